@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"expvar"
 	"log"
 	"net/http"
 	"os"
@@ -9,42 +10,115 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/fx"
 	"github.com/filipe/financial-ledger-project/internal/handler"
-	"github.com/filipe/financial-ledger-project/internal/repository"
 	"github.com/filipe/financial-ledger-project/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 func main() {
-	cfg := database.Config{
-		Host:     getEnv("DATABASE_HOST", "localhost"),
-		Port:     getEnv("DATABASE_PORT", "5432"),
-		User:     getEnv("DATABASE_USER", "ledger_user"),
-		Password: getEnv("DATABASE_PASSWORD", "ledger_pass"),
-		DBName:   getEnv("DATABASE_NAME", "financial_ledger"),
-		SSLMode:  getEnv("DATABASE_SSLMODE", "disable"),
-	}
-
 	port := getEnv("API_PORT", "8080")
 
-	db, err := database.NewPostgresDB(cfg)
+	store, err := openStorage()
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
+	defer store.db.Close()
 
 	log.Println("Connected to database successfully")
 
-	accountRepo := repository.NewAccountRepository(db)
-	transactionRepo := repository.NewTransactionRepository(db)
+	rates, err := newRateProvider(getEnv("FX_RATES_FILE", ""))
+	if err != nil {
+		log.Fatalf("Failed to load FX rates: %v", err)
+	}
+
+	quoteTTL, err := time.ParseDuration(getEnv("FX_QUOTE_TTL", "30s"))
+	if err != nil {
+		log.Fatalf("Invalid FX_QUOTE_TTL: %v", err)
+	}
+
+	// Holds of pending transfers lapse after HOLD_TTL; a background job
+	// releases them every HOLD_EXPIRY_INTERVAL.
+	holdTTL, err := time.ParseDuration(getEnv("HOLD_TTL", "168h"))
+	if err != nil {
+		log.Fatalf("Invalid HOLD_TTL: %v", err)
+	}
+
+	holdExpiryInterval, err := time.ParseDuration(getEnv("HOLD_EXPIRY_INTERVAL", "1m"))
+	if err != nil {
+		log.Fatalf("Invalid HOLD_EXPIRY_INTERVAL: %v", err)
+	}
+
+	// End-of-day balance snapshots are taken every SNAPSHOT_INTERVAL for
+	// days that ended at least SNAPSHOT_SETTLE_DELAY ago.
+	snapshotInterval, err := time.ParseDuration(getEnv("SNAPSHOT_INTERVAL", "1h"))
+	if err != nil {
+		log.Fatalf("Invalid SNAPSHOT_INTERVAL: %v", err)
+	}
+
+	snapshotSettleDelay, err := time.ParseDuration(getEnv("SNAPSHOT_SETTLE_DELAY", "5m"))
+	if err != nil {
+		log.Fatalf("Invalid SNAPSHOT_SETTLE_DELAY: %v", err)
+	}
 
-	accountService := service.NewAccountService(accountRepo)
-	transferService := service.NewTransferService(db, accountRepo, transactionRepo)
+	// Idempotency keys are kept for IDEMPOTENCY_KEY_RETENTION; a background
+	// job purges older ones every IDEMPOTENCY_PURGE_INTERVAL.
+	idempotencyRetention, err := time.ParseDuration(getEnv("IDEMPOTENCY_KEY_RETENTION", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid IDEMPOTENCY_KEY_RETENTION: %v", err)
+	}
+
+	idempotencyPurgeInterval, err := time.ParseDuration(getEnv("IDEMPOTENCY_PURGE_INTERVAL", "1h"))
+	if err != nil {
+		log.Fatalf("Invalid IDEMPOTENCY_PURGE_INTERVAL: %v", err)
+	}
+
+	// Pending credits of hot accounts are settled every
+	// HOT_ACCOUNT_SETTLE_INTERVAL, in case nothing debits the account.
+	creditSettleInterval, err := time.ParseDuration(getEnv("HOT_ACCOUNT_SETTLE_INTERVAL", "1s"))
+	if err != nil {
+		log.Fatalf("Invalid HOT_ACCOUNT_SETTLE_INTERVAL: %v", err)
+	}
+
+	// Statements and account imports stream for as long as their data takes
+	// to send, and a batch of up to 1000 transfers takes as long as they take
+	// to run: up to STREAM_TIMEOUT rather than the 60s other requests get.
+	streamTimeout, err := time.ParseDuration(getEnv("STREAM_TIMEOUT", "30m"))
+	if err != nil {
+		log.Fatalf("Invalid STREAM_TIMEOUT: %v", err)
+	}
+
+	accountService := service.NewAccountService(store.uow, store.accountRepo, store.journalRepo, store.idempotencyRepo)
+	transferService := service.NewTransferService(
+		store.uow,
+		store.accountRepo,
+		store.transactionRepo,
+		store.journalRepo,
+		store.quoteRepo,
+		store.idempotencyRepo,
+		holdTTL,
+	)
+	// TRANSFER_ISOLATION=serializable runs money movements at SERIALIZABLE
+	// isolation; transactions aborted by conflicts are retried either way.
+	transferService.UseSerializable(getEnv("TRANSFER_ISOLATION", "read_committed") == "serializable")
+	transactionService := service.NewTransactionService(store.accountRepo, store.transactionRepo)
+	fxService := service.NewFXService(store.uow, store.quoteRepo, rates, quoteTTL)
+	snapshotService := service.NewSnapshotService(store.uow, store.snapshotRepo, snapshotSettleDelay)
+	reconciliationService := service.NewReconciliationService(store.uow, store.reconciliationRepo)
+	idempotencyService := service.NewIdempotencyService(store.uow, store.idempotencyRepo, idempotencyRetention)
+
+	// Idempotency keys sent without X-Client-ID share one anonymous scope and
+	// are logged as deprecated. REQUIRE_CLIENT_ID=true refuses them with 400
+	// once every client sends the header.
+	requireClientID := getEnv("REQUIRE_CLIENT_ID", "false") == "true"
 
 	accountHandler := handler.NewAccountHandler(accountService)
-	transactionHandler := handler.NewTransactionHandler(transferService)
+	accountHandler.RequireClientID(requireClientID)
+	transactionHandler := handler.NewTransactionHandler(transferService, transactionService)
+	transactionHandler.RequireClientID(requireClientID)
+	fxHandler := handler.NewFXHandler(fxService)
+	adminHandler := handler.NewAdminHandler(reconciliationService)
 
 	r := chi.NewRouter()
 
@@ -52,20 +126,60 @@ func main() {
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
-	r.Use(middleware.Timeout(60 * time.Second))
 
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+	requestTimeout := middleware.Timeout(60 * time.Second)
+
+	r.With(requestTimeout).Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
+	// Runtime and retry metrics as JSON.
+	r.With(requestTimeout).Handle("/debug/vars", expvar.Handler())
+
 	r.Route("/accounts", func(r chi.Router) {
-		r.Post("/", accountHandler.CreateAccount)
-		r.Get("/{account_id}", accountHandler.GetAccount)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Timeout(streamTimeout))
+			r.Post("/import", accountHandler.ImportAccounts)
+			r.Get("/{account_id}/statement", accountHandler.GetStatement)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(requestTimeout)
+			r.Post("/", accountHandler.CreateAccount)
+			r.Get("/{account_id}", accountHandler.GetAccount)
+			r.Get("/{account_id}/balance", accountHandler.GetBalance)
+			r.Get("/{account_id}/transactions", transactionHandler.ListAccountTransactions)
+			r.Put("/{account_id}/overdraft-limit", accountHandler.SetOverdraftLimit)
+			r.Put("/{account_id}/hot", accountHandler.SetHotMode)
+			r.Post("/{account_id}/freeze", accountHandler.FreezeAccount)
+			r.Post("/{account_id}/unfreeze", accountHandler.UnfreezeAccount)
+			r.Post("/{account_id}/close", accountHandler.CloseAccount)
+			r.Get("/{account_id}/status-changes", accountHandler.ListStatusChanges)
+		})
 	})
 
 	r.Route("/transactions", func(r chi.Router) {
-		r.Post("/", transactionHandler.CreateTransaction)
+		r.With(middleware.Timeout(streamTimeout)).Post("/batch", transactionHandler.CreateBatch)
+
+		r.Group(func(r chi.Router) {
+			r.Use(requestTimeout)
+			r.Post("/", transactionHandler.CreateTransaction)
+			r.Get("/{transaction_id}", transactionHandler.GetTransaction)
+			r.Post("/{transaction_id}/reversals", transactionHandler.ReverseTransaction)
+			r.Post("/{transaction_id}/capture", transactionHandler.CaptureTransaction)
+			r.Post("/{transaction_id}/void", transactionHandler.VoidTransaction)
+		})
+	})
+
+	r.Route("/fx", func(r chi.Router) {
+		r.Use(requestTimeout)
+		r.Post("/quotes", fxHandler.CreateQuote)
+	})
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(requestTimeout)
+		r.Get("/reconciliation", adminHandler.Reconcile)
 	})
 
 	srv := &http.Server{
@@ -76,6 +190,14 @@ func main() {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	go runHoldExpiry(jobsCtx, transferService, holdExpiryInterval)
+	go runSnapshots(jobsCtx, snapshotService, snapshotInterval)
+	go runIdempotencyPurge(jobsCtx, idempotencyService, idempotencyPurgeInterval)
+	go runCreditSettlement(jobsCtx, accountService, creditSettleInterval)
+
 	go func() {
 		log.Printf("Starting API server on port %s...", port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -88,6 +210,7 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -99,6 +222,99 @@ func main() {
 	log.Println("Server stopped")
 }
 
+// runHoldExpiry releases lapsed holds every interval until ctx is done.
+func runHoldExpiry(ctx context.Context, transferService *service.TransferService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := transferService.ExpireHolds(ctx, time.Now())
+			if err != nil {
+				log.Printf("Failed to expire holds: %v", err)
+			}
+			if expired > 0 {
+				log.Printf("Expired %d holds", expired)
+			}
+		}
+	}
+}
+
+// runSnapshots takes any missing end-of-day balance snapshots at startup and
+// then every interval until ctx is done.
+func runSnapshots(ctx context.Context, snapshotService *service.SnapshotService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		days, err := snapshotService.TakeSnapshots(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Failed to take balance snapshots: %v", err)
+		}
+		if days > 0 {
+			log.Printf("Took balance snapshots for %d days", days)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runIdempotencyPurge deletes expired idempotency keys every interval until
+// ctx is done.
+func runIdempotencyPurge(ctx context.Context, idempotencyService *service.IdempotencyService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := idempotencyService.PurgeExpired(ctx, time.Now())
+			if err != nil {
+				log.Printf("Failed to purge idempotency keys: %v", err)
+			}
+			if purged > 0 {
+				log.Printf("Purged %d idempotency keys", purged)
+			}
+		}
+	}
+}
+
+// runCreditSettlement settles the pending credits of hot accounts every
+// interval until ctx is done.
+func runCreditSettlement(ctx context.Context, accountService *service.AccountService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := accountService.SettleCredits(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to settle pending credits: %v", err)
+			}
+		}
+	}
+}
+
+// newRateProvider loads FX rates from a JSON file. Without one, quotes can
+// not be created and only same-currency transfers are possible.
+func newRateProvider(path string) (fx.RateProvider, error) {
+	if path == "" {
+		return fx.NewStaticRateProvider(nil)
+	}
+	return fx.NewFileRateProvider(path)
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
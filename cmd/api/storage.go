@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/repository/sqlite"
+)
+
+// storage is the database the API runs on, with its repositories.
+type storage struct {
+	db                 *sql.DB
+	uow                repository.UnitOfWork
+	accountRepo        repository.AccountRepository
+	transactionRepo    repository.TransactionRepository
+	journalRepo        repository.JournalRepository
+	quoteRepo          repository.QuoteRepository
+	idempotencyRepo    repository.IdempotencyRepository
+	snapshotRepo       repository.SnapshotRepository
+	reconciliationRepo repository.ReconciliationRepository
+}
+
+// openStorage connects to the database selected by DATABASE_DRIVER:
+// postgres (the default) or sqlite, a single file at SQLITE_PATH for
+// deployments without a database server.
+func openStorage() (*storage, error) {
+	switch driver := getEnv("DATABASE_DRIVER", "postgres"); driver {
+	case "postgres":
+		db, err := database.NewPostgresDB(database.Config{
+			Host:     getEnv("DATABASE_HOST", "localhost"),
+			Port:     getEnv("DATABASE_PORT", "5432"),
+			User:     getEnv("DATABASE_USER", "ledger_user"),
+			Password: getEnv("DATABASE_PASSWORD", "ledger_pass"),
+			DBName:   getEnv("DATABASE_NAME", "financial_ledger"),
+			SSLMode:  getEnv("DATABASE_SSLMODE", "disable"),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &storage{
+			db:                 db,
+			uow:                postgres.NewUnitOfWork(db),
+			accountRepo:        postgres.NewAccountRepository(db),
+			transactionRepo:    postgres.NewTransactionRepository(db),
+			journalRepo:        postgres.NewJournalRepository(db),
+			quoteRepo:          postgres.NewQuoteRepository(db),
+			idempotencyRepo:    postgres.NewIdempotencyRepository(db),
+			snapshotRepo:       postgres.NewSnapshotRepository(db),
+			reconciliationRepo: postgres.NewReconciliationRepository(db),
+		}, nil
+
+	case "sqlite":
+		db, err := database.NewSQLiteDB(getEnv("SQLITE_PATH", "ledger.db"))
+		if err != nil {
+			return nil, err
+		}
+
+		store := sqlite.NewStore(db)
+		return &storage{
+			db:                 db,
+			uow:                store,
+			accountRepo:        sqlite.NewAccountRepository(store),
+			transactionRepo:    sqlite.NewTransactionRepository(store),
+			journalRepo:        sqlite.NewJournalRepository(store),
+			quoteRepo:          sqlite.NewQuoteRepository(store),
+			idempotencyRepo:    sqlite.NewIdempotencyRepository(store),
+			snapshotRepo:       sqlite.NewSnapshotRepository(store),
+			reconciliationRepo: sqlite.NewReconciliationRepository(store),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown DATABASE_DRIVER %q: use postgres or sqlite", driver)
+	}
+}
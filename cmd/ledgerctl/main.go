@@ -0,0 +1,165 @@
+// Command ledgerctl runs administrative operations against the ledger's
+// database, selected by DATABASE_DRIVER like the API server's.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/repository/sqlite"
+	"github.com/filipe/financial-ledger-project/internal/service"
+)
+
+const usage = `Usage: ledgerctl <command> [arguments]
+
+Commands:
+  import-accounts [-rejects <file>] <file.csv | ->
+      open the accounts listed in a CSV file, or standard input, with their
+      opening balances; rows whose account already exists are skipped
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "import-accounts":
+		importAccounts(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func importAccounts(args []string) {
+	flags := flag.NewFlagSet("import-accounts", flag.ExitOnError)
+	rejectsPath := flags.String("rejects", "", "write the rejected rows to this CSV file")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var input io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Failed to open import file: %v", err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	file, err := models.NewAccountImportReader(input)
+	if err != nil {
+		log.Fatalf("Failed to read import file: %v", err)
+	}
+
+	db, accountService := openAccountService()
+	defer db.Close()
+
+	report, err := accountService.ImportAccounts(context.Background(), file)
+	if report != nil {
+		log.Printf("Read %d rows: %d accounts created, %d already existed, %d rows rejected",
+			report.Rows, report.Created, report.Existing, len(report.Rejected))
+
+		if *rejectsPath != "" {
+			if err := writeRejects(*rejectsPath, report.Rejected); err != nil {
+				log.Fatalf("Failed to write rejected rows: %v", err)
+			}
+		} else {
+			for _, rejected := range report.Rejected {
+				log.Printf("line %d: %s", rejected.Line, rejected.Error)
+			}
+		}
+	}
+	if err != nil {
+		log.Fatalf("Import stopped: %v", err)
+	}
+
+	if len(report.Rejected) > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeRejects writes each rejected row as its line number and error
+// followed by the row's own fields.
+func writeRejects(path string, rejected []models.AccountImportReject) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	// A failed write is kept by the writer and reported by Error.
+	w := csv.NewWriter(f)
+	w.Write([]string{"line", "error", "record"})
+	for _, row := range rejected {
+		w.Write(append([]string{strconv.Itoa(row.Line), row.Error}, row.Record...))
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// openAccountService connects to the database selected by DATABASE_DRIVER.
+func openAccountService() (*sql.DB, *service.AccountService) {
+	if getEnv("DATABASE_DRIVER", "postgres") == "sqlite" {
+		db, err := database.NewSQLiteDB(getEnv("SQLITE_PATH", "ledger.db"))
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+
+		store := sqlite.NewStore(db)
+		return db, service.NewAccountService(
+			store,
+			sqlite.NewAccountRepository(store),
+			sqlite.NewJournalRepository(store),
+			sqlite.NewIdempotencyRepository(store),
+		)
+	}
+
+	cfg := database.Config{
+		Host:     getEnv("DATABASE_HOST", "localhost"),
+		Port:     getEnv("DATABASE_PORT", "5432"),
+		User:     getEnv("DATABASE_USER", "ledger_user"),
+		Password: getEnv("DATABASE_PASSWORD", "ledger_pass"),
+		DBName:   getEnv("DATABASE_NAME", "financial_ledger"),
+		SSLMode:  getEnv("DATABASE_SSLMODE", "disable"),
+	}
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	return db, service.NewAccountService(
+		postgres.NewUnitOfWork(db),
+		postgres.NewAccountRepository(db),
+		postgres.NewJournalRepository(db),
+		postgres.NewIdempotencyRepository(db),
+	)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -1,18 +1,78 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
-	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
 	"github.com/filipe/financial-ledger-project/internal/database"
-	_ "github.com/lib/pq"
 )
 
+const usage = `Usage: migrate [command]
+
+Commands:
+  up            apply every pending migration (the default)
+  down          revert the latest applied migration
+  status        list the migrations and whether they are applied
+  to <version>  apply or revert migrations until <version> is the latest
+                applied; 0 reverts them all
+`
+
 func main() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		args = []string{"up"}
+	}
+
+	var run func(migrator *database.Migrator, ctx context.Context) error
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		run = (*database.Migrator).Up
+	case args[0] == "down" && len(args) == 1:
+		run = (*database.Migrator).Down
+	case args[0] == "status" && len(args) == 1:
+		run = printStatus
+	case args[0] == "to" && len(args) == 2:
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			log.Fatalf("Invalid version %q", args[1])
+		}
+		run = func(migrator *database.Migrator, ctx context.Context) error {
+			return migrator.To(ctx, version)
+		}
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	db, migrator := openDatabase()
+	defer db.Close()
+
+	if err := run(migrator, context.Background()); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	if args[0] != "status" {
+		log.Println("Migrations completed successfully!")
+	}
+}
+
+// openDatabase connects to the database selected by DATABASE_DRIVER, like
+// the API server does.
+func openDatabase() (*sql.DB, *database.Migrator) {
+	if getEnv("DATABASE_DRIVER", "postgres") == "sqlite" {
+		db, err := database.NewSQLiteDB(getEnv("SQLITE_PATH", "ledger.db"))
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		return db, database.NewSQLiteMigrator(db)
+	}
+
 	cfg := database.Config{
 		Host:     getEnv("DATABASE_HOST", "localhost"),
 		Port:     getEnv("DATABASE_PORT", "5432"),
@@ -26,49 +86,38 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer db.Close()
 
 	log.Println("Connected to database successfully")
 
-	if err := runMigrations(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
-	}
-
-	log.Println("All migrations completed successfully!")
+	return db, database.NewPostgresMigrator(db)
 }
 
-func runMigrations(db *sql.DB) error {
-	migrationsPath := "internal/database/migrations"
-
-	files, err := os.ReadDir(migrationsPath)
+func printStatus(migrator *database.Migrator, ctx context.Context) error {
+	statuses, err := migrator.Status(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to read migrations directory: %w", err)
+		return err
 	}
 
-	var sqlFiles []string
-	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".sql" {
-			sqlFiles = append(sqlFiles, file.Name())
-		}
-	}
-	sort.Strings(sqlFiles)
-
-	for _, filename := range sqlFiles {
-		log.Printf("Running migration: %s", filename)
-
-		content, err := os.ReadFile(filepath.Join(migrationsPath, filename))
-		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", filename, err)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\t")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Local().Format(time.RFC3339)
 		}
 
-		if _, err := db.Exec(string(content)); err != nil {
-			return fmt.Errorf("failed to execute migration %s: %w", filename, err)
+		note := ""
+		switch {
+		case status.Missing:
+			note = "not in this build"
+		case status.Edited:
+			note = "edited since applied"
 		}
 
-		log.Printf("✓ Migration %s completed", filename)
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, note)
 	}
 
-	return nil
+	return w.Flush()
 }
 
 func getEnv(key, defaultValue string) string {
@@ -0,0 +1,93 @@
+// Command reconcile recomputes every account's balance from the journal and
+// checks it against accounts.balance, along with held balances, journal
+// entry sums and transaction entries. It prints each discrepancy and exits
+// with status 1 if any is found, so it can gate a cron job or a deploy.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	cfg := database.Config{
+		Host:     getEnv("DATABASE_HOST", "localhost"),
+		Port:     getEnv("DATABASE_PORT", "5432"),
+		User:     getEnv("DATABASE_USER", "ledger_user"),
+		Password: getEnv("DATABASE_PASSWORD", "ledger_pass"),
+		DBName:   getEnv("DATABASE_NAME", "financial_ledger"),
+		SSLMode:  getEnv("DATABASE_SSLMODE", "disable"),
+	}
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	reconciliationService := service.NewReconciliationService(postgres.NewUnitOfWork(db), postgres.NewReconciliationRepository(db))
+
+	report, err := reconciliationService.Reconcile(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to reconcile ledger: %v", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report.ToResponse()); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	} else {
+		printReport(report.ToResponse())
+	}
+
+	if !report.Balanced() {
+		os.Exit(1)
+	}
+}
+
+func printReport(report models.ReconciliationResponse) {
+	fmt.Printf("Checked %d accounts at %s\n", report.AccountsChecked, report.CheckedAt.Format(time.RFC3339))
+
+	for _, drift := range report.AccountDrift {
+		fmt.Printf("account %d (%s): balance %s, expected %s; held %s, expected %s\n",
+			drift.AccountID, drift.Currency,
+			drift.Balance, drift.ExpectedBalance,
+			drift.HeldBalance, drift.ExpectedHeldBalance)
+	}
+
+	for _, entry := range report.UnbalancedEntries {
+		fmt.Printf("journal entry %s: %s postings sum to %s\n", entry.EntryID, entry.Currency, entry.Sum)
+	}
+
+	for _, mismatch := range report.Transactions {
+		fmt.Printf("transaction %s: %s with %d journal entries\n", mismatch.TransactionID, mismatch.Status, mismatch.Entries)
+	}
+
+	if report.Balanced {
+		fmt.Println("Ledger is balanced")
+	} else {
+		fmt.Println("Ledger has drifted")
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,53 @@
+// Command snapshot takes any missing end-of-day balance snapshots and exits.
+// It does the same work as the API's background job and can be run from
+// cron when that job is disabled or to backfill an existing ledger.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+)
+
+func main() {
+	cfg := database.Config{
+		Host:     getEnv("DATABASE_HOST", "localhost"),
+		Port:     getEnv("DATABASE_PORT", "5432"),
+		User:     getEnv("DATABASE_USER", "ledger_user"),
+		Password: getEnv("DATABASE_PASSWORD", "ledger_pass"),
+		DBName:   getEnv("DATABASE_NAME", "financial_ledger"),
+		SSLMode:  getEnv("DATABASE_SSLMODE", "disable"),
+	}
+
+	settleDelay, err := time.ParseDuration(getEnv("SNAPSHOT_SETTLE_DELAY", "5m"))
+	if err != nil {
+		log.Fatalf("Invalid SNAPSHOT_SETTLE_DELAY: %v", err)
+	}
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	snapshotService := service.NewSnapshotService(postgres.NewUnitOfWork(db), postgres.NewSnapshotRepository(db), settleDelay)
+
+	days, err := snapshotService.TakeSnapshots(context.Background(), time.Now())
+	if err != nil {
+		log.Fatalf("Failed to take balance snapshots: %v", err)
+	}
+
+	log.Printf("Took balance snapshots for %d days", days)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,76 @@
+// Command verify-chain walks every account's transaction hash chain and
+// reports the first tampered or missing record of each, as well as any
+// transaction created since chaining began that was never sealed. It exits
+// with status 1 if a chain is broken.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+)
+
+func main() {
+	cfg := database.Config{
+		Host:     getEnv("DATABASE_HOST", "localhost"),
+		Port:     getEnv("DATABASE_PORT", "5432"),
+		User:     getEnv("DATABASE_USER", "ledger_user"),
+		Password: getEnv("DATABASE_PASSWORD", "ledger_pass"),
+		DBName:   getEnv("DATABASE_NAME", "financial_ledger"),
+		SSLMode:  getEnv("DATABASE_SSLMODE", "disable"),
+	}
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	chainService := service.NewChainService(postgres.NewTransactionRepository(db))
+
+	report, err := chainService.Verify(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to verify hash chains: %v", err)
+	}
+
+	fmt.Printf("Checked %d transactions in %d chains\n", report.TransactionsChecked, report.AccountsChecked)
+
+	for _, chainBreak := range report.Breaks {
+		fmt.Println(describe(chainBreak))
+	}
+
+	if !report.Intact() {
+		fmt.Println("Hash chains are broken")
+		os.Exit(1)
+	}
+
+	fmt.Println("Hash chains are intact")
+}
+
+func describe(b models.ChainBreak) string {
+	switch b.Reason {
+	case models.ChainBreakMissing:
+		return fmt.Sprintf("account %d: record %d is missing", b.AccountID, b.Seq)
+	case models.ChainBreakLink:
+		return fmt.Sprintf("account %d: record %d (transaction %s) was rewritten after the next record was sealed", b.AccountID, b.Seq, b.TransactionID)
+	case models.ChainBreakHash:
+		return fmt.Sprintf("account %d: record %d (transaction %s) does not match its hash", b.AccountID, b.Seq, b.TransactionID)
+	case models.ChainBreakSettlement:
+		return fmt.Sprintf("account %d: record %d (transaction %s) does not match how its hold was settled", b.AccountID, b.Seq, b.TransactionID)
+	default:
+		return fmt.Sprintf("account %d: transaction %s was never sealed", b.AccountID, b.TransactionID)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,415 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrations embed.FS
+
+// Migration is a numbered schema change, read from NNN_name.up.sql and the
+// optional NNN_name.down.sql that reverts it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of Up. It is recorded when the migration is
+	// applied, so an edit made afterwards is detected.
+	Checksum string
+}
+
+// MigrationStatus is a migration and whether it has been applied.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	// Edited reports that the migration changed after it was applied.
+	Edited bool
+	// Missing reports an applied migration this build does not know.
+	Missing bool
+}
+
+// dialect holds what differs between the databases a Migrator runs on.
+type dialect struct {
+	schemaTable string
+	// lock and unlock take and release a lock held by one migrator at a
+	// time, when the database has one.
+	lock, unlock string
+	// bind rewrites the $N placeholders of a query for the database.
+	bind func(query string) string
+}
+
+// migrationLockKey is the Postgres advisory lock migrators take. Any
+// constant works as long as every migrator of the schema uses the same one.
+const migrationLockKey int64 = 7_242_603_453
+
+var postgresDialect = dialect{
+	schemaTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL
+		)
+	`,
+	lock:   `SELECT pg_advisory_lock($1)`,
+	unlock: `SELECT pg_advisory_unlock($1)`,
+	bind:   func(query string) string { return query },
+}
+
+// SQLite has no lock a migrator could hold across transactions. Its
+// database belongs to a single host, and a second migrator applying the
+// same migration fails on the schema_migrations primary key instead.
+var sqliteDialect = dialect{
+	schemaTable: `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`,
+	bind: func(query string) string { return strings.ReplaceAll(query, "$", "?") },
+}
+
+// Migrator applies and reverts the migrations of a database, recording the
+// applied ones in schema_migrations. Each migration runs in a transaction of
+// its own, together with its record.
+type Migrator struct {
+	db         *sql.DB
+	dialect    dialect
+	migrations []Migration
+	err        error
+}
+
+// NewPostgresMigrator returns a Migrator for the PostgreSQL migrations
+// embedded in the binary.
+func NewPostgresMigrator(db *sql.DB) *Migrator {
+	return newMigrator(db, postgresDialect, migrations, "migrations/postgres")
+}
+
+// NewSQLiteMigrator returns a Migrator for the SQLite migrations embedded in
+// the binary.
+func NewSQLiteMigrator(db *sql.DB) *Migrator {
+	return newMigrator(db, sqliteDialect, migrations, "migrations/sqlite")
+}
+
+func newMigrator(db *sql.DB, d dialect, fsys fs.FS, dir string) *Migrator {
+	m := &Migrator{db: db, dialect: d}
+	m.migrations, m.err = loadMigrations(fsys, dir)
+	return m
+}
+
+// loadMigrations reads the migrations in dir, ordered by version.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		base, direction, _ := cutLast(base, ".")
+		prefix, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil || version <= 0 || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %s: want NNN_name.up.sql or NNN_name.down.sql", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration file %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no up file", migration.Version, migration.Name)
+		}
+		list = append(list, *migration)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// appliedMigration is a row of schema_migrations.
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// Up applies every pending migration, in version order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.run(ctx, true, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the latest applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.run(ctx, true, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				return m.revert(ctx, conn, m.migrations[i])
+			}
+		}
+		log.Println("No migrations to revert")
+		return nil
+	})
+}
+
+// To applies the pending migrations up to version and reverts the applied
+// ones after it, leaving version the latest applied. Version 0 reverts
+// every migration.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.run(ctx, true, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; ok && migration.Version > version {
+				if err := m.revert(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+				if err := m.apply(ctx, conn, migration); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every migration, known or applied, in version order.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.run(ctx, false, func(conn *sql.Conn, applied map[int64]appliedMigration) error {
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if record, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &record.appliedAt
+				status.Edited = record.checksum != migration.Checksum
+			}
+			statuses = append(statuses, status)
+		}
+
+		for version, record := range applied {
+			if !m.known(version) {
+				appliedAt := record.appliedAt
+				statuses = append(statuses, MigrationStatus{Version: version, Name: record.name, AppliedAt: &appliedAt, Missing: true})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+func (m *Migrator) known(version int64) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// run calls fn with the applied migrations, holding the migration lock on
+// conn. When change is set, fn is only called if every applied migration
+// matches this build's.
+func (m *Migrator) run(ctx context.Context, change bool, fn func(conn *sql.Conn, applied map[int64]appliedMigration) error) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if m.dialect.lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.lock, migrationLockKey); err != nil {
+			return fmt.Errorf("failed to take migration lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), m.dialect.unlock, migrationLockKey)
+	}
+
+	if _, err := conn.ExecContext(ctx, m.dialect.schemaTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if change {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+	}
+
+	return fn(conn, applied)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var record appliedMigration
+		if err := rows.Scan(&version, &record.name, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = record
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// verify refuses to change a schema whose applied migrations differ from
+// the ones in this build: the schema is not the one the migrations expect.
+func (m *Migrator) verify(applied map[int64]appliedMigration) error {
+	for version, record := range applied {
+		if !m.known(version) {
+			return fmt.Errorf("migration %d (%s) is applied but not part of this build", version, record.name)
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if record, ok := applied[migration.Version]; ok && record.checksum != migration.Checksum {
+			return fmt.Errorf("migration %d (%s) was edited after it was applied", migration.Version, migration.Name)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	log.Printf("Applying migration %03d_%s", migration.Version, migration.Name)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("failed to apply migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	query := m.dialect.bind(`
+		INSERT INTO schema_migrations (version, name, checksum, applied_at)
+		VALUES ($1, $2, $3, $4)
+	`)
+	if _, err := tx.ExecContext(ctx, query, migration.Version, migration.Name, migration.Checksum, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %03d_%s cannot be reverted: it has no down file", migration.Version, migration.Name)
+	}
+
+	log.Printf("Reverting migration %03d_%s", migration.Version, migration.Name)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("failed to revert migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	query := m.dialect.bind(`DELETE FROM schema_migrations WHERE version = $1`)
+	if _, err := tx.ExecContext(ctx, query, migration.Version); err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := NewSQLiteDB(filepath.Join(t.TempDir(), "ledger.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count)
+	require.NoError(t, err)
+	return count > 0
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"m/001_accounts.up.sql":   {Data: []byte(`CREATE TABLE accounts (id INTEGER PRIMARY KEY);`)},
+		"m/001_accounts.down.sql": {Data: []byte(`DROP TABLE accounts;`)},
+		"m/002_quotes.up.sql":     {Data: []byte(`CREATE TABLE quotes (id INTEGER PRIMARY KEY);`)},
+		"m/002_quotes.down.sql":   {Data: []byte(`DROP TABLE quotes;`)},
+	}
+}
+
+func TestMigrations_Load(t *testing.T) {
+	for _, dir := range []string{"migrations/postgres", "migrations/sqlite"} {
+		list, err := loadMigrations(migrations, dir)
+		require.NoError(t, err, dir)
+		require.NotEmpty(t, list, dir)
+
+		for i, migration := range list {
+			assert.Equal(t, int64(i+1), migration.Version, "%s: versions are contiguous", dir)
+			assert.NotEmpty(t, migration.Down, "%s: %03d_%s has a down file", dir, migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestMigrations_LoadRejectsBadNames(t *testing.T) {
+	fsys := fstest.MapFS{"m/accounts.sql": {Data: []byte(`SELECT 1;`)}}
+	_, err := loadMigrations(fsys, "m")
+	assert.Error(t, err)
+
+	fsys = fstest.MapFS{"m/001_accounts.down.sql": {Data: []byte(`SELECT 1;`)}}
+	_, err = loadMigrations(fsys, "m")
+	assert.Error(t, err, "a down file needs its up file")
+}
+
+func TestMigrator_UpDownTo(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := newMigrator(db, sqliteDialect, testMigrations(), "m")
+
+	require.NoError(t, migrator.Up(ctx))
+	assert.True(t, tableExists(t, db, "accounts"))
+	assert.True(t, tableExists(t, db, "quotes"))
+
+	// Applied migrations are not run again.
+	require.NoError(t, migrator.Up(ctx))
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt)
+		assert.False(t, status.Edited)
+	}
+
+	require.NoError(t, migrator.Down(ctx))
+	assert.True(t, tableExists(t, db, "accounts"))
+	assert.False(t, tableExists(t, db, "quotes"))
+
+	statuses, err = migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	require.NoError(t, migrator.To(ctx, 2))
+	assert.True(t, tableExists(t, db, "quotes"))
+
+	require.NoError(t, migrator.To(ctx, 0))
+	assert.False(t, tableExists(t, db, "accounts"))
+	assert.False(t, tableExists(t, db, "quotes"))
+
+	assert.Error(t, migrator.To(ctx, 3), "unknown version")
+}
+
+func TestMigrator_FailedMigrationIsNotRecorded(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	fsys := testMigrations()
+	fsys["m/003_broken.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE broken (id INTEGER PRIMARY KEY); SELECT * FROM missing;`)}
+	migrator := newMigrator(db, sqliteDialect, fsys, "m")
+
+	require.Error(t, migrator.Up(ctx))
+	assert.True(t, tableExists(t, db, "quotes"), "earlier migrations stay applied")
+	assert.False(t, tableExists(t, db, "broken"), "the failed migration is rolled back")
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, statuses[2].AppliedAt)
+}
+
+func TestMigrator_RefusesEditedMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	require.NoError(t, newMigrator(db, sqliteDialect, testMigrations(), "m").Up(ctx))
+
+	fsys := testMigrations()
+	fsys["m/001_accounts.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE accounts (id INTEGER PRIMARY KEY, name TEXT);`)}
+	fsys["m/003_holds.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE holds (id INTEGER PRIMARY KEY);`)}
+	edited := newMigrator(db, sqliteDialect, fsys, "m")
+
+	assert.ErrorContains(t, edited.Up(ctx), "edited")
+	assert.False(t, tableExists(t, db, "holds"))
+
+	statuses, err := edited.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Edited)
+}
+
+func TestMigrator_RefusesUnknownMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	require.NoError(t, newMigrator(db, sqliteDialect, testMigrations(), "m").Up(ctx))
+
+	fsys := testMigrations()
+	delete(fsys, "m/002_quotes.up.sql")
+	delete(fsys, "m/002_quotes.down.sql")
+	older := newMigrator(db, sqliteDialect, fsys, "m")
+
+	assert.ErrorContains(t, older.Down(ctx), "not part of this build")
+
+	statuses, err := older.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[1].Missing)
+}
+
+func TestSQLiteMigrations_UpDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := NewSQLiteMigrator(db)
+
+	require.NoError(t, migrator.Up(ctx))
+	assert.True(t, tableExists(t, db, "postings"))
+
+	require.NoError(t, migrator.To(ctx, 0))
+	assert.False(t, tableExists(t, db, "accounts"))
+
+	require.NoError(t, migrator.Up(ctx))
+	assert.True(t, tableExists(t, db, "accounts"))
+}
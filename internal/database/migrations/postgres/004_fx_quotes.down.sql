@@ -0,0 +1,6 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_quote_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_rate;
+ALTER TABLE transactions DROP COLUMN IF EXISTS destination_currency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS destination_amount;
+
+DROP TABLE IF EXISTS fx_quotes;
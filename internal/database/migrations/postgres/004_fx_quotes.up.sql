@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id UUID PRIMARY KEY,
+    source_currency CHAR(3) NOT NULL,
+    destination_currency CHAR(3) NOT NULL,
+    rate NUMERIC(30, 12) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT positive_rate CHECK (rate > 0),
+    CONSTRAINT different_currencies CHECK (source_currency != destination_currency)
+);
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS destination_amount BIGINT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS destination_currency CHAR(3);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_rate NUMERIC(30, 12);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fx_quote_id UUID REFERENCES fx_quotes(id);
@@ -0,0 +1,6 @@
+-- Balances stay cached on accounts; the postings behind them are lost.
+DROP TABLE IF EXISTS postings;
+DROP TABLE IF EXISTS journal_entries;
+
+DROP FUNCTION IF EXISTS reject_posting_change();
+DROP FUNCTION IF EXISTS check_journal_entry_balanced();
@@ -0,0 +1,115 @@
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id UUID PRIMARY KEY,
+    transaction_id UUID,
+    kind VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT fk_entry_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_transaction ON journal_entries(transaction_id)
+WHERE transaction_id IS NOT NULL;
+
+-- Postings are signed: positive amounts increase the account's balance,
+-- negative amounts decrease it. System accounts (opening balances, FX
+-- conversion) are named rather than stored in accounts.
+CREATE TABLE IF NOT EXISTS postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id UUID NOT NULL,
+    account_id BIGINT,
+    system_account VARCHAR(64),
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT fk_posting_entry FOREIGN KEY (entry_id) REFERENCES journal_entries(id),
+    CONSTRAINT fk_posting_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CONSTRAINT nonzero_amount CHECK (amount != 0),
+    CONSTRAINT single_account CHECK ((account_id IS NULL) != (system_account IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_postings_entry ON postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account ON postings(account_id, created_at, id)
+WHERE account_id IS NOT NULL;
+
+-- Every journal entry must sum to zero per currency. The check runs at commit
+-- so an entry can be written one posting at a time.
+CREATE OR REPLACE FUNCTION check_journal_entry_balanced() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM postings
+        WHERE entry_id = NEW.entry_id
+        GROUP BY currency
+        HAVING SUM(amount) != 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % does not balance', NEW.entry_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS postings_balanced ON postings;
+CREATE CONSTRAINT TRIGGER postings_balanced
+    AFTER INSERT ON postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- The journal is append-only.
+CREATE OR REPLACE FUNCTION reject_posting_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'postings are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS postings_append_only ON postings;
+CREATE TRIGGER postings_append_only
+    BEFORE UPDATE OR DELETE ON postings
+    FOR EACH ROW EXECUTE FUNCTION reject_posting_change();
+
+-- Backfill: journal entries for transfers recorded before the journal existed.
+-- Holds that were never captured have no entry by design.
+INSERT INTO journal_entries (id, transaction_id, kind, created_at)
+SELECT gen_random_uuid(), t.id, 'TRANSFER', t.created_at
+FROM transactions t
+WHERE t.status NOT IN ('PENDING', 'VOIDED', 'EXPIRED')
+  AND NOT EXISTS (SELECT 1 FROM journal_entries e WHERE e.transaction_id = t.id);
+
+INSERT INTO postings (entry_id, account_id, system_account, amount, currency, created_at)
+SELECT e.id, p.account_id, p.system_account, p.amount, p.currency, e.created_at
+FROM journal_entries e
+JOIN transactions t ON t.id = e.transaction_id
+CROSS JOIN LATERAL (VALUES
+    (t.source_account_id, NULL, -t.amount, t.currency),
+    (t.destination_account_id, NULL, COALESCE(t.destination_amount, t.amount), COALESCE(t.destination_currency, t.currency)),
+    (NULL, 'fx:conversion', t.amount, t.currency),
+    (NULL, 'fx:conversion', -COALESCE(t.destination_amount, t.amount), COALESCE(t.destination_currency, t.currency))
+) AS p(account_id, system_account, amount, currency)
+WHERE NOT EXISTS (SELECT 1 FROM postings existing WHERE existing.entry_id = e.id)
+  AND (p.account_id IS NOT NULL OR t.currency != COALESCE(t.destination_currency, t.currency));
+
+-- Backfill: opening balances are whatever the cached balance holds that the
+-- transfer postings do not explain.
+CREATE TEMP TABLE opening_balances ON COMMIT DROP AS
+SELECT a.id AS account_id, gen_random_uuid() AS entry_id, a.currency, a.created_at,
+       a.balance - COALESCE((SELECT SUM(p.amount) FROM postings p WHERE p.account_id = a.id), 0) AS amount
+FROM accounts a
+WHERE NOT EXISTS (
+    SELECT 1
+    FROM postings p
+    JOIN journal_entries e ON e.id = p.entry_id
+    WHERE p.account_id = a.id AND e.kind = 'OPENING_BALANCE'
+);
+
+INSERT INTO journal_entries (id, transaction_id, kind, created_at)
+SELECT entry_id, NULL, 'OPENING_BALANCE', created_at
+FROM opening_balances
+WHERE amount != 0;
+
+INSERT INTO postings (entry_id, account_id, system_account, amount, currency, created_at)
+SELECT entry_id, account_id, NULL, amount, currency, created_at
+FROM opening_balances
+WHERE amount != 0
+UNION ALL
+SELECT entry_id, NULL, 'equity:opening_balances', -amount, currency, created_at
+FROM opening_balances
+WHERE amount != 0;
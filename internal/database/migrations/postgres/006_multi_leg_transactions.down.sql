@@ -0,0 +1,6 @@
+-- Fails while multi-leg transactions exist: they have no single source or
+-- destination to restore.
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS single_or_multi_leg;
+
+ALTER TABLE transactions ALTER COLUMN source_account_id SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN destination_account_id SET NOT NULL;
@@ -0,0 +1,8 @@
+-- Multi-leg transactions have no single source or destination; their legs
+-- are the postings of the transaction's journal entry.
+ALTER TABLE transactions ALTER COLUMN source_account_id DROP NOT NULL;
+ALTER TABLE transactions ALTER COLUMN destination_account_id DROP NOT NULL;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS single_or_multi_leg;
+ALTER TABLE transactions ADD CONSTRAINT single_or_multi_leg
+    CHECK ((source_account_id IS NULL) = (destination_account_id IS NULL));
@@ -0,0 +1,7 @@
+-- Timestamps are converted to the session time zone.
+ALTER TABLE fx_quotes ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE accounts ALTER COLUMN created_at TYPE TIMESTAMP;
+ALTER TABLE accounts ALTER COLUMN updated_at TYPE TIMESTAMP;
+
+ALTER TABLE transactions ALTER COLUMN created_at TYPE TIMESTAMP;
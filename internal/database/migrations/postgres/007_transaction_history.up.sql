@@ -0,0 +1,21 @@
+-- History is paginated by (created_at, id) and filtered by client-supplied
+-- instants, so timestamps are stored with their time zone. Existing values
+-- are interpreted in the session time zone they were written in.
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_name = 'transactions' AND column_name = 'created_at') = 'timestamp without time zone' THEN
+        ALTER TABLE transactions ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+    END IF;
+
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_name = 'accounts' AND column_name = 'created_at') = 'timestamp without time zone' THEN
+        ALTER TABLE accounts ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+        ALTER TABLE accounts ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+    END IF;
+
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_name = 'fx_quotes' AND column_name = 'created_at') = 'timestamp without time zone' THEN
+        ALTER TABLE fx_quotes ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+    END IF;
+END $$;
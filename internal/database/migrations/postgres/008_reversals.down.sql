@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_reversal_of;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS reversed_amount_range;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_reversal_of;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversed_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS reversal_of;
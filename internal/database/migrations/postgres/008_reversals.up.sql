@@ -0,0 +1,16 @@
+-- A reversal is an ordinary transaction pointing back at the one it
+-- compensates. reversed_amount tracks how much of the original, in its source
+-- currency, has been returned so far.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversal_of UUID;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS reversed_amount BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS fk_reversal_of;
+ALTER TABLE transactions ADD CONSTRAINT fk_reversal_of
+    FOREIGN KEY (reversal_of) REFERENCES transactions(id);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS reversed_amount_range;
+ALTER TABLE transactions ADD CONSTRAINT reversed_amount_range
+    CHECK (reversed_amount >= 0 AND reversed_amount <= amount);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions(reversal_of)
+WHERE reversal_of IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_pending_holds;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS hold_expires_at;
+ALTER TABLE transactions DROP COLUMN IF EXISTS authorized_amount;
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS non_negative_hold;
+ALTER TABLE accounts DROP COLUMN IF EXISTS held_balance;
@@ -0,0 +1,15 @@
+-- Two-phase transfers reserve funds on the source account until they are
+-- captured, voided or expire. held_balance caches the sum of the account's
+-- open holds; the available balance is balance - held_balance.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS held_balance BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS non_negative_hold;
+ALTER TABLE accounts ADD CONSTRAINT non_negative_hold CHECK (held_balance >= 0);
+
+-- authorized_amount is the amount held when the transfer was created; amount
+-- becomes the captured amount.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS authorized_amount BIGINT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS hold_expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_pending_holds ON transactions(hold_expires_at)
+WHERE status = 'PENDING';
@@ -0,0 +1,18 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'ACTIVE';
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS valid_status;
+ALTER TABLE accounts ADD CONSTRAINT valid_status CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED'));
+
+-- Audit trail of every status change: who made it, when and why.
+CREATE TABLE IF NOT EXISTS account_status_changes (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp(),
+    CONSTRAINT fk_status_change_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_status_changes_account ON account_status_changes(account_id, created_at);
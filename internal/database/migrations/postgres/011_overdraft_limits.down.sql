@@ -0,0 +1,6 @@
+-- Fails while any account is overdrawn.
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS within_overdraft_limit;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS non_negative_overdraft_limit;
+ALTER TABLE accounts DROP COLUMN IF EXISTS overdraft_limit;
+
+ALTER TABLE accounts ADD CONSTRAINT positive_balance CHECK (balance >= 0);
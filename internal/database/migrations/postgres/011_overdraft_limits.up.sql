@@ -0,0 +1,14 @@
+-- overdraft_limit is how far below zero an account's balance may go, in
+-- minor units. NULL means no limit, for internal clearing accounts. The check
+-- is evaluated on every balance update, which happens under the account's
+-- row lock, so it holds atomically with the transfer that moves the money.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_limit BIGINT DEFAULT 0;
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS non_negative_overdraft_limit;
+ALTER TABLE accounts ADD CONSTRAINT non_negative_overdraft_limit
+    CHECK (overdraft_limit IS NULL OR overdraft_limit >= 0);
+
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS positive_balance;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS within_overdraft_limit;
+ALTER TABLE accounts ADD CONSTRAINT within_overdraft_limit
+    CHECK (overdraft_limit IS NULL OR balance >= -overdraft_limit);
@@ -0,0 +1,13 @@
+-- End-of-day balances. A snapshot holds the sum of the account's postings
+-- with created_at <= as_of, so historical balances only need the postings
+-- after the nearest snapshot.
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    account_id BIGINT NOT NULL,
+    as_of TIMESTAMPTZ NOT NULL,
+    balance BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, as_of),
+    CONSTRAINT fk_snapshot_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_snapshots_as_of ON balance_snapshots(as_of);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_transactions_chain;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS sealed_or_unsealed;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS hash;
+ALTER TABLE transactions DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE transactions DROP COLUMN IF EXISTS chain_seq;
+ALTER TABLE transactions DROP COLUMN IF EXISTS chain_account_id;
@@ -0,0 +1,17 @@
+-- Tamper-evident hash chain. Every transaction created from now on is
+-- appended to the chain of its source account (the lowest source of a
+-- multi-leg transaction): chain_seq numbers the chain from 1 and hash covers
+-- the record's contents and prev_hash. Earlier transactions stay unsealed.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS chain_account_id BIGINT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS chain_seq BIGINT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS prev_hash CHAR(64);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS hash CHAR(64);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS sealed_or_unsealed;
+ALTER TABLE transactions ADD CONSTRAINT sealed_or_unsealed CHECK (
+    (chain_account_id IS NULL AND chain_seq IS NULL AND prev_hash IS NULL AND hash IS NULL)
+    OR (chain_account_id IS NOT NULL AND chain_seq > 0 AND prev_hash IS NOT NULL AND hash IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_chain ON transactions(chain_account_id, chain_seq)
+WHERE chain_account_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- SHA-256 of the request an idempotency key was first used with. A reused
+-- key is only replayed for the same request. Transactions recorded before
+-- this column existed have none and are replayed for any request.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS request_fingerprint CHAR(64);
@@ -0,0 +1,2 @@
+-- Keys recorded only here, such as those of refused requests, are lost.
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,21 @@
+-- Idempotency keys and the outcome of the request that first used them. A
+-- key is claimed by inserting it in the same database transaction that does
+-- the work, so a concurrent request with the same key waits on the primary
+-- key until that transaction ends. A committed key always has an outcome:
+-- the transaction it created or the error it was refused with.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_fingerprint CHAR(64),
+    transaction_id UUID,
+    error_code VARCHAR(64),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_idempotency_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    CONSTRAINT single_outcome CHECK (transaction_id IS NULL OR error_code IS NULL)
+);
+
+-- Backfill: keys recorded on transactions before this table existed.
+INSERT INTO idempotency_keys (key, request_fingerprint, transaction_id, created_at)
+SELECT idempotency_key, request_fingerprint, id, created_at
+FROM transactions
+WHERE idempotency_key IS NOT NULL
+ON CONFLICT (key) DO NOTHING;
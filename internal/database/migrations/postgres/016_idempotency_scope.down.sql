@@ -0,0 +1,12 @@
+-- Fails while two clients hold the same key.
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS single_outcome;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS fk_idempotency_account;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS account_id;
+ALTER TABLE idempotency_keys ADD CONSTRAINT single_outcome CHECK (transaction_id IS NULL OR error_code IS NULL);
+
+DROP INDEX IF EXISTS idx_idempotency_keys_created_at;
+DROP INDEX IF EXISTS idx_idempotency_keys_client_key;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS client_id;
+ALTER TABLE idempotency_keys ADD CONSTRAINT idempotency_keys_pkey PRIMARY KEY (key);
+
+ALTER TABLE transactions ADD CONSTRAINT unique_idempotency_key UNIQUE (idempotency_key);
@@ -0,0 +1,20 @@
+-- Idempotency keys are scoped to the API client that sent them (the
+-- X-Client-ID header, '' for requests without one), can record the account
+-- a request opened, and are purged once older than the retention window.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS client_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS account_id BIGINT;
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS fk_idempotency_account;
+ALTER TABLE idempotency_keys ADD CONSTRAINT fk_idempotency_account FOREIGN KEY (account_id) REFERENCES accounts(id);
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS single_outcome;
+ALTER TABLE idempotency_keys ADD CONSTRAINT single_outcome CHECK (num_nonnulls(transaction_id, account_id, error_code) <= 1);
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_idempotency_keys_client_key ON idempotency_keys(client_id, key);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+-- Keys now live only in idempotency_keys. The copies on transactions are no
+-- longer written or unique, and are cleared when their key is purged.
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS unique_idempotency_key;
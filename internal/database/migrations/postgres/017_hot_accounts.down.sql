@@ -0,0 +1,13 @@
+-- Pending credits are settled first, so no balance is lost.
+UPDATE accounts a
+SET balance = a.balance + p.total, updated_at = NOW()
+FROM (
+    SELECT account_id, SUM(amount) AS total
+    FROM pending_credits
+    GROUP BY account_id
+) p
+WHERE p.account_id = a.id;
+
+DROP TABLE IF EXISTS pending_credits;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS hot;
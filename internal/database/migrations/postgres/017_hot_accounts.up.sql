@@ -0,0 +1,18 @@
+-- Hot accounts are credited under a shared row lock, so credits to them do
+-- not wait for each other. Such a credit is posted to the journal at once
+-- and recorded in pending_credits; it is added to accounts.balance when the
+-- account is next locked for update. accounts.balance therefore excludes
+-- pending credits, and within_overdraft_limit keeps debits within the
+-- settled balance.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS hot BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS pending_credits (
+    posting_id BIGINT PRIMARY KEY,
+    account_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    CONSTRAINT fk_pending_credit_posting FOREIGN KEY (posting_id) REFERENCES postings(id),
+    CONSTRAINT fk_pending_credit_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CONSTRAINT positive_credit CHECK (amount > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_credits_account ON pending_credits(account_id);
@@ -0,0 +1,22 @@
+-- A hold's hash covers its authorization only. settlement_hash seals how the
+-- hold ended, chained to that hash: the status it was captured, voided or
+-- expired with and the amounts it then recorded (see
+-- Transaction.ComputeSettlementHash). Sealed holds that have already ended
+-- are settled as they stand.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS settlement_hash CHAR(64);
+
+UPDATE transactions
+SET settlement_hash = encode(sha256(convert_to(format(
+    E'v1 settlement\nid=%s\nprev=%s\nstatus=%s\namount=%s %s\ndestination_amount=%s %s\n',
+    id,
+    hash,
+    CASE WHEN status IN ('PARTIALLY_REVERSED', 'REVERSED') THEN 'COMPLETED' ELSE status END,
+    amount,
+    currency,
+    COALESCE(destination_amount, amount),
+    COALESCE(destination_currency, currency)
+), 'UTF8')), 'hex')
+WHERE authorized_amount IS NOT NULL
+    AND status <> 'PENDING'
+    AND hash IS NOT NULL
+    AND settlement_hash IS NULL;
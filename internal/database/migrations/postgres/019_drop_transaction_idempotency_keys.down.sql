@@ -0,0 +1,11 @@
+-- Only the anonymous client's keys can be copied back.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS request_fingerprint CHAR(64);
+
+UPDATE transactions t
+SET idempotency_key = k.key, request_fingerprint = k.request_fingerprint
+FROM idempotency_keys k
+WHERE k.transaction_id = t.id AND k.client_id = '';
+
+CREATE INDEX IF NOT EXISTS idx_transactions_idempotency ON transactions(idempotency_key)
+WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Keys recorded on transactions were copied into idempotency_keys by 015.
+-- Move any still missing there, to the anonymous client as 016 scoped them,
+-- and drop the copies.
+INSERT INTO idempotency_keys (client_id, key, request_fingerprint, transaction_id, created_at)
+SELECT '', idempotency_key, request_fingerprint, id, created_at
+FROM transactions
+WHERE idempotency_key IS NOT NULL
+ON CONFLICT (client_id, key) DO NOTHING;
+
+DROP INDEX IF EXISTS idx_transactions_idempotency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS idempotency_key;
+ALTER TABLE transactions DROP COLUMN IF EXISTS request_fingerprint;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS balance_snapshots;
+DROP TABLE IF EXISTS postings;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS fx_quotes;
+DROP TABLE IF EXISTS account_status_changes;
+DROP TABLE IF EXISTS accounts;
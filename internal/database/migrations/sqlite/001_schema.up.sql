@@ -0,0 +1,158 @@
+-- The SQLite schema is the Postgres one as of 016_idempotency_scope.up.sql,
+-- without the columns and backfills kept there for data written by earlier
+-- versions. Timestamps are INTEGER microseconds since the Unix epoch, which
+-- compare correctly and keep Postgres' precision; UUIDs and exchange rates
+-- are TEXT. Foreign keys are only enforced on connections that enable them,
+-- which database.NewSQLiteDB does.
+CREATE TABLE IF NOT EXISTS accounts (
+    id INTEGER PRIMARY KEY,
+    balance INTEGER NOT NULL DEFAULT 0,
+    held_balance INTEGER NOT NULL DEFAULT 0,
+    overdraft_limit INTEGER DEFAULT 0,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    status TEXT NOT NULL DEFAULT 'ACTIVE',
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER,
+    CONSTRAINT non_negative_hold CHECK (held_balance >= 0),
+    CONSTRAINT valid_status CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED')),
+    CONSTRAINT non_negative_overdraft_limit CHECK (overdraft_limit IS NULL OR overdraft_limit >= 0),
+    CONSTRAINT within_overdraft_limit CHECK (overdraft_limit IS NULL OR balance >= -overdraft_limit)
+);
+
+CREATE TABLE IF NOT EXISTS account_status_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT fk_status_change_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_status_changes_account ON account_status_changes(account_id, created_at);
+
+CREATE TABLE IF NOT EXISTS fx_quotes (
+    id TEXT PRIMARY KEY,
+    source_currency TEXT NOT NULL,
+    destination_currency TEXT NOT NULL,
+    rate TEXT NOT NULL,
+    expires_at INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT different_currencies CHECK (source_currency != destination_currency)
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id TEXT PRIMARY KEY,
+    source_account_id INTEGER,
+    destination_account_id INTEGER,
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    destination_amount INTEGER,
+    destination_currency TEXT,
+    fx_rate TEXT,
+    fx_quote_id TEXT,
+    status TEXT NOT NULL,
+    reversal_of TEXT,
+    reversed_amount INTEGER NOT NULL DEFAULT 0,
+    authorized_amount INTEGER,
+    hold_expires_at INTEGER,
+    chain_account_id INTEGER,
+    chain_seq INTEGER,
+    prev_hash TEXT,
+    hash TEXT,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT fk_source_account FOREIGN KEY (source_account_id) REFERENCES accounts(id),
+    CONSTRAINT fk_destination_account FOREIGN KEY (destination_account_id) REFERENCES accounts(id),
+    CONSTRAINT fk_fx_quote FOREIGN KEY (fx_quote_id) REFERENCES fx_quotes(id),
+    CONSTRAINT fk_reversal_of FOREIGN KEY (reversal_of) REFERENCES transactions(id),
+    CONSTRAINT positive_amount CHECK (amount > 0),
+    CONSTRAINT different_accounts CHECK (source_account_id != destination_account_id),
+    CONSTRAINT single_or_multi_leg CHECK ((source_account_id IS NULL) = (destination_account_id IS NULL)),
+    CONSTRAINT reversed_amount_range CHECK (reversed_amount >= 0 AND reversed_amount <= amount),
+    CONSTRAINT sealed_or_unsealed CHECK (
+        (chain_account_id IS NULL AND chain_seq IS NULL AND prev_hash IS NULL AND hash IS NULL)
+        OR (chain_account_id IS NOT NULL AND chain_seq > 0 AND prev_hash IS NOT NULL AND hash IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_source ON transactions(source_account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_destination ON transactions(destination_account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions(reversal_of)
+WHERE reversal_of IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_transactions_pending_holds ON transactions(hold_expires_at)
+WHERE status = 'PENDING';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_chain ON transactions(chain_account_id, chain_seq)
+WHERE chain_account_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS journal_entries (
+    id TEXT PRIMARY KEY,
+    transaction_id TEXT,
+    kind TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT fk_entry_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_transaction ON journal_entries(transaction_id)
+WHERE transaction_id IS NOT NULL;
+
+-- SQLite has no deferred triggers, so unlike Postgres the database does not
+-- check that entries balance: JournalRepository.Post validates every entry
+-- and reconciliation reports any that do not.
+CREATE TABLE IF NOT EXISTS postings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry_id TEXT NOT NULL,
+    account_id INTEGER,
+    system_account TEXT,
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    CONSTRAINT fk_posting_entry FOREIGN KEY (entry_id) REFERENCES journal_entries(id),
+    CONSTRAINT fk_posting_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CONSTRAINT nonzero_amount CHECK (amount != 0),
+    CONSTRAINT single_account CHECK ((account_id IS NULL) != (system_account IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_postings_entry ON postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_postings_account ON postings(account_id, created_at, id)
+WHERE account_id IS NOT NULL;
+
+-- The journal is append-only.
+CREATE TRIGGER IF NOT EXISTS postings_no_update BEFORE UPDATE ON postings
+BEGIN
+    SELECT RAISE(ABORT, 'postings are append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS postings_no_delete BEFORE DELETE ON postings
+BEGIN
+    SELECT RAISE(ABORT, 'postings are append-only');
+END;
+
+CREATE TABLE IF NOT EXISTS balance_snapshots (
+    account_id INTEGER NOT NULL,
+    as_of INTEGER NOT NULL,
+    balance INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (account_id, as_of),
+    CONSTRAINT fk_snapshot_account FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_snapshots_as_of ON balance_snapshots(as_of);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    client_id TEXT NOT NULL DEFAULT '',
+    key TEXT NOT NULL,
+    request_fingerprint TEXT,
+    transaction_id TEXT,
+    account_id INTEGER,
+    error_code TEXT,
+    created_at INTEGER NOT NULL,
+    PRIMARY KEY (client_id, key),
+    CONSTRAINT fk_idempotency_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    CONSTRAINT fk_idempotency_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CONSTRAINT single_outcome CHECK (
+        (transaction_id IS NOT NULL) + (account_id IS NOT NULL) + (error_code IS NOT NULL) <= 1
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
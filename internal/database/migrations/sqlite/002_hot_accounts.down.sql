@@ -0,0 +1,7 @@
+UPDATE accounts
+SET balance = balance + (SELECT SUM(amount) FROM pending_credits p WHERE p.account_id = accounts.id)
+WHERE id IN (SELECT account_id FROM pending_credits);
+
+DROP TABLE pending_credits;
+
+ALTER TABLE accounts DROP COLUMN hot;
@@ -0,0 +1,15 @@
+-- See 017_hot_accounts.up.sql of the Postgres migrations. SQLite runs one
+-- transaction at a time, so hot accounts gain nothing here, but they behave
+-- the same.
+ALTER TABLE accounts ADD COLUMN hot INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE pending_credits (
+    posting_id INTEGER PRIMARY KEY,
+    account_id INTEGER NOT NULL,
+    amount INTEGER NOT NULL,
+    CONSTRAINT fk_pending_credit_posting FOREIGN KEY (posting_id) REFERENCES postings(id),
+    CONSTRAINT fk_pending_credit_account FOREIGN KEY (account_id) REFERENCES accounts(id),
+    CONSTRAINT positive_credit CHECK (amount > 0)
+);
+
+CREATE INDEX idx_pending_credits_account ON pending_credits(account_id);
@@ -0,0 +1,4 @@
+-- See 018_hold_settlements.up.sql of the Postgres migrations. SQLite has no
+-- SHA-256 function to settle the holds that have already ended, so chain
+-- verification reports each of them as a settlement mismatch.
+ALTER TABLE transactions ADD COLUMN settlement_hash TEXT;
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewSQLiteDB opens the SQLite database at path, creating it if needed.
+//
+// Every transaction begins IMMEDIATE, taking the database's write lock
+// up front: SQLite has no row locks, so writers run one at a time instead.
+// WAL lets reads outside transactions go on meanwhile. Foreign keys are off
+// in SQLite unless each connection turns them on.
+func NewSQLiteDB(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Set("_txlock", "immediate")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_synchronous", "FULL")
+	params.Set("_foreign_keys", "on")
+	params.Set("_busy_timeout", "5000")
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,146 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+)
+
+// RateScale is the maximum number of fractional digits a rate may carry. It
+// matches the precision of the fx_quotes.rate column.
+const RateScale = 12
+
+// RateProvider supplies exchange rates. A rate is the amount of the
+// destination currency bought by one unit of the source currency.
+type RateProvider interface {
+	Rate(ctx context.Context, sourceCurrency, destinationCurrency string) (models.Decimal, error)
+}
+
+// StaticRateProvider serves a fixed set of rates keyed by "SRC/DST" pairs.
+// It is meant for tests and for deployments that load rates from a file.
+type StaticRateProvider struct {
+	rates map[string]models.Decimal
+}
+
+func NewStaticRateProvider(rates map[string]models.Decimal) (*StaticRateProvider, error) {
+	normalized := make(map[string]models.Decimal, len(rates))
+	for pair, rate := range rates {
+		source, destination, ok := strings.Cut(pair, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid currency pair %q", pair)
+		}
+		source, err := models.NormalizeCurrency(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid currency pair %q: %w", pair, err)
+		}
+		destination, err = models.NormalizeCurrency(destination)
+		if err != nil {
+			return nil, fmt.Errorf("invalid currency pair %q: %w", pair, err)
+		}
+		if err := ValidateRate(rate); err != nil {
+			return nil, fmt.Errorf("invalid rate for %q: %w", pair, err)
+		}
+		normalized[source+"/"+destination] = rate
+	}
+
+	return &StaticRateProvider{rates: normalized}, nil
+}
+
+// NewFileRateProvider loads rates from a JSON file of the form
+// {"USD/JPY": "151.25", "USD/KWD": "0.307"}.
+func NewFileRateProvider(path string) (*StaticRateProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var rates map[string]models.Decimal
+	if err := json.Unmarshal(content, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file: %w", err)
+	}
+
+	return NewStaticRateProvider(rates)
+}
+
+func (p *StaticRateProvider) Rate(ctx context.Context, sourceCurrency, destinationCurrency string) (models.Decimal, error) {
+	rate, ok := p.rates[sourceCurrency+"/"+destinationCurrency]
+	if !ok {
+		return "", models.ErrRateUnavailable
+	}
+	return rate, nil
+}
+
+// ValidateRate checks that rate is a positive decimal with at most RateScale
+// fractional digits.
+func ValidateRate(rate models.Decimal) error {
+	r, err := parseRate(rate)
+	if err != nil {
+		return err
+	}
+	if r.Sign() <= 0 {
+		return models.ErrInvalidRate
+	}
+	return nil
+}
+
+// Convert turns an amount in the source currency's minor units into the
+// destination currency's minor units at the given rate. The result is rounded
+// half away from zero to the destination currency's precision; this is the
+// only place the ledger rounds money.
+func Convert(amount int64, sourceCurrency, destinationCurrency string, rate models.Decimal) (int64, error) {
+	sourceUnits, err := models.MinorUnits(sourceCurrency)
+	if err != nil {
+		return 0, err
+	}
+	destinationUnits, err := models.MinorUnits(destinationCurrency)
+	if err != nil {
+		return 0, err
+	}
+	r, err := parseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+
+	value := new(big.Rat).SetInt64(amount)
+	value.Mul(value, r)
+	value.Mul(value, new(big.Rat).SetInt(pow10(destinationUnits)))
+	value.Quo(value, new(big.Rat).SetInt(pow10(sourceUnits)))
+
+	num := new(big.Int).Abs(value.Num())
+	quotient, remainder := new(big.Int).QuoRem(num, value.Denom(), new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+
+	if !quotient.IsInt64() {
+		return 0, models.ErrInvalidAmount
+	}
+	return quotient.Int64(), nil
+}
+
+func parseRate(rate models.Decimal) (*big.Rat, error) {
+	s := string(rate)
+	if _, frac, ok := strings.Cut(s, "."); ok && len(strings.TrimRight(frac, "0")) > RateScale {
+		return nil, models.ErrInvalidRate
+	}
+	if strings.ContainsAny(s, "eE/+") {
+		return nil, models.ErrInvalidRate
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, models.ErrInvalidRate
+	}
+	return r, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,64 @@
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      int64
+		source      string
+		destination string
+		rate        models.Decimal
+		expected    int64
+	}{
+		{"USD to JPY", 1001, "USD", "JPY", "150.5", 1507},
+		{"JPY to USD", 10000, "JPY", "USD", "0.0066", 6600},
+		{"USD to KWD", 10000, "USD", "KWD", "0.307", 30700},
+		{"KWD to USD", 1000, "KWD", "USD", "3.2573", 326},
+		{"Rounds half away from zero", 1, "USD", "EUR", "0.5", 1},
+		{"Rounds down below half", 1, "USD", "EUR", "0.49", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Convert(tt.amount, tt.source, tt.destination, tt.rate)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestValidateRate(t *testing.T) {
+	assert.NoError(t, ValidateRate("150.5"))
+	assert.NoError(t, ValidateRate("0.000000000001"))
+	assert.ErrorIs(t, ValidateRate("0"), models.ErrInvalidRate)
+	assert.ErrorIs(t, ValidateRate("-1.5"), models.ErrInvalidRate)
+	assert.ErrorIs(t, ValidateRate("1e3"), models.ErrInvalidRate)
+	assert.ErrorIs(t, ValidateRate("1/3"), models.ErrInvalidRate)
+	assert.ErrorIs(t, ValidateRate("0.0000000000001"), models.ErrInvalidRate)
+}
+
+func TestStaticRateProvider(t *testing.T) {
+	provider, err := NewStaticRateProvider(map[string]models.Decimal{"usd/jpy": "150.5"})
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, models.Decimal("150.5"), rate)
+
+	_, err = provider.Rate(context.Background(), "JPY", "USD")
+	assert.ErrorIs(t, err, models.ErrRateUnavailable)
+
+	_, err = NewStaticRateProvider(map[string]models.Decimal{"USDJPY": "150.5"})
+	assert.Error(t, err)
+
+	_, err = NewStaticRateProvider(map[string]models.Decimal{"USD/JPY": "abc"})
+	assert.Error(t, err)
+}
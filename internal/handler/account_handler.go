@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/filipe/financial-ledger-project/internal/models"
 	"github.com/filipe/financial-ledger-project/internal/service"
@@ -11,6 +15,7 @@ import (
 )
 
 type AccountHandler struct {
+	keyScope
 	accountService *service.AccountService
 }
 
@@ -28,7 +33,13 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.accountService.CreateAccount(r.Context(), req); err != nil {
+	key, err := h.requestKey(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	if err := h.accountService.CreateAccount(r.Context(), req, key); err != nil {
 		sendError(w, err)
 		return
 	}
@@ -36,6 +47,29 @@ func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusCreated)
 }
 
+// ImportAccounts opens the accounts of a CSV file sent as the request body,
+// reading it as it arrives, and returns the import report. Rows whose
+// account already exists are skipped, so the same file can be sent again.
+func (h *AccountHandler) ImportAccounts(w http.ResponseWriter, r *http.Request) {
+	// A large file takes more than the server's read timeout to upload and
+	// is imported while it arrives, past its write timeout.
+	extendDeadlines(w, r)
+
+	file, err := models.NewAccountImportReader(r.Body)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	report, err := h.accountService.ImportAccounts(r.Context(), file)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, report)
+}
+
 func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
 	accountIDStr := chi.URLParam(r, "account_id")
 	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
@@ -50,5 +84,209 @@ func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sendJSON(w, http.StatusOK, account)
+	sendResponse(w, r, http.StatusOK, account)
+}
+
+// GetBalance returns the account's balance at the as_of query parameter, or
+// now if it is omitted.
+func (h *AccountHandler) GetBalance(w http.ResponseWriter, r *http.Request) {
+	accountIDStr := chi.URLParam(r, "account_id")
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid account ID"})
+		return
+	}
+
+	now := time.Now()
+	asOf := now.Truncate(time.Microsecond)
+	if value := r.URL.Query().Get("as_of"); value != "" {
+		if asOf, err = models.ParseAsOf(value, now); err != nil {
+			sendError(w, err)
+			return
+		}
+	}
+
+	balance, err := h.accountService.GetBalanceAt(r.Context(), accountID, asOf)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, balance)
+}
+
+// statementContentTypes are the media types of the statement formats.
+var statementContentTypes = map[string]string{
+	models.StatementFormatCSV:   "text/csv; charset=utf-8",
+	models.StatementFormatJSONL: "application/jsonl",
+	models.StatementFormatOFX:   "application/x-ofx",
+}
+
+// GetStatement streams the account's statement between the from and to query
+// parameters, in the format the format parameter names: csv (the default),
+// jsonl or ofx.
+func (h *AccountHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	accountIDStr := chi.URLParam(r, "account_id")
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid account ID"})
+		return
+	}
+
+	query := r.URL.Query()
+	period, err := models.ParseStatementPeriod(query.Get("from"), query.Get("to"), time.Now())
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = models.StatementFormatCSV
+	}
+
+	body := &startedWriter{ResponseWriter: w}
+	numericAmounts := r.Header.Get(AmountFormatHeader) == AmountFormatNumber
+	statement, err := models.NewStatementWriter(format, numericAmounts, body)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", statementContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%d.%s"`, accountID, format))
+
+	// A long statement can take more than the server's write timeout to
+	// send.
+	extendDeadlines(w, r)
+
+	if err := h.accountService.WriteStatement(r.Context(), accountID, period, statement); err != nil {
+		if !body.started {
+			w.Header().Del("Content-Disposition")
+			sendError(w, err)
+			return
+		}
+
+		// The status line is gone: abort the response so the client sees
+		// the statement cut short rather than complete.
+		log.Printf("Failed to write statement of account %d: %v", accountID, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// startedWriter records whether anything has been written to the response.
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+func (h *AccountHandler) SetOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	accountIDStr := chi.URLParam(r, "account_id")
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid account ID"})
+		return
+	}
+
+	var req models.OverdraftLimitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON"})
+		return
+	}
+
+	account, err := h.accountService.SetOverdraftLimit(r.Context(), accountID, req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, account)
+}
+
+func (h *AccountHandler) SetHotMode(w http.ResponseWriter, r *http.Request) {
+	accountIDStr := chi.URLParam(r, "account_id")
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid account ID"})
+		return
+	}
+
+	var req models.HotModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON"})
+		return
+	}
+
+	account, err := h.accountService.SetHotMode(r.Context(), accountID, req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, account)
+}
+
+// ActorHeader identifies who asked for an account status change.
+const ActorHeader = "X-Actor"
+
+func (h *AccountHandler) FreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.accountService.FreezeAccount)
+}
+
+func (h *AccountHandler) UnfreezeAccount(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.accountService.UnfreezeAccount)
+}
+
+func (h *AccountHandler) CloseAccount(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.accountService.CloseAccount)
+}
+
+func (h *AccountHandler) changeStatus(
+	w http.ResponseWriter,
+	r *http.Request,
+	change func(context.Context, int64, models.ChangeAccountStatusRequest) (*models.AccountResponse, error),
+) {
+	accountIDStr := chi.URLParam(r, "account_id")
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid account ID"})
+		return
+	}
+
+	var req models.ChangeAccountStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON"})
+		return
+	}
+	req.Actor = r.Header.Get(ActorHeader)
+
+	account, err := change(r.Context(), accountID, req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, account)
+}
+
+func (h *AccountHandler) ListStatusChanges(w http.ResponseWriter, r *http.Request) {
+	accountIDStr := chi.URLParam(r, "account_id")
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid account ID"})
+		return
+	}
+
+	changes, err := h.accountService.ListStatusChanges(r.Context(), accountID)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, changes)
 }
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/filipe/financial-ledger-project/internal/service"
+)
+
+// AdminHandler serves operational endpoints that are not part of the public
+// API and should not be exposed outside the operator's network.
+type AdminHandler struct {
+	reconciliationService *service.ReconciliationService
+}
+
+func NewAdminHandler(reconciliationService *service.ReconciliationService) *AdminHandler {
+	return &AdminHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// Reconcile checks the ledger against its journal. Drift is reported in the
+// body with balanced set to false, not as an error status.
+func (h *AdminHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	report, err := h.reconciliationService.Reconcile(r.Context())
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, report.ToResponse())
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/service"
+)
+
+type FXHandler struct {
+	fxService *service.FXService
+}
+
+func NewFXHandler(fxService *service.FXService) *FXHandler {
+	return &FXHandler{
+		fxService: fxService,
+	}
+}
+
+func (h *FXHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateFXQuoteRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON"})
+		return
+	}
+
+	quote, err := h.fxService.CreateQuote(r.Context(), req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusCreated, quote)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+)
+
+const (
+	// IdempotencyKeyHeader makes a request safe to retry.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// ClientIDHeader identifies the API client that sent a request.
+	// Idempotency keys are scoped to it.
+	ClientIDHeader = "X-Client-ID"
+)
+
+// keyScope scopes the idempotency keys of requests to the client that sent
+// them. Keys sent without a client ID share the anonymous scope of the empty
+// client ID, where keys stored before scoping existed also live; such
+// requests are logged as deprecated until RequireClientID refuses them.
+//
+// The API does not authenticate its callers: X-Client-ID is taken as sent,
+// and the scope only keeps apart clients that are honest about who they
+// are. Deployments that expose the API beyond trusted services must set the
+// header from the authenticated caller at the gateway, and strip any value
+// the caller sent.
+type keyScope struct {
+	requireClientID bool
+}
+
+// RequireClientID refuses idempotency keys sent without an X-Client-ID,
+// once every client sends one.
+func (s *keyScope) RequireClientID(required bool) {
+	s.requireClientID = required
+}
+
+// requestKey reads the idempotency key of a request and the client it is
+// scoped to.
+func (s *keyScope) requestKey(r *http.Request) (models.RequestKey, error) {
+	key := models.RequestKey{
+		ClientID: r.Header.Get(ClientIDHeader),
+		Key:      r.Header.Get(IdempotencyKeyHeader),
+	}
+	if key.Key != "" && key.ClientID == "" {
+		if err := s.anonymous(r); err != nil {
+			return models.RequestKey{}, err
+		}
+	}
+	return key, nil
+}
+
+// batchClientID reads the client the idempotency keys of a batch's
+// transfers are scoped to, as requestKey does for a single request.
+func (s *keyScope) batchClientID(r *http.Request, req *models.BatchTransferRequest) (string, error) {
+	clientID := r.Header.Get(ClientIDHeader)
+	if clientID != "" {
+		return clientID, nil
+	}
+	for _, item := range req.Transfers {
+		if item.IdempotencyKey != "" {
+			return "", s.anonymous(r)
+		}
+	}
+	return "", nil
+}
+
+// anonymous refuses a request that sent an idempotency key without a client
+// ID, or logs it as deprecated if those are still allowed.
+func (s *keyScope) anonymous(r *http.Request) error {
+	if s.requireClientID {
+		return models.ErrMissingClientID
+	}
+	log.Printf("Deprecated: %s %s sent an idempotency key without %s; it is scoped to the anonymous client", r.Method, r.URL.Path, ClientIDHeader)
+	return nil
+}
@@ -3,16 +3,49 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/filipe/financial-ledger-project/internal/models"
 )
 
+const (
+	// AmountFormatHeader set to AmountFormatNumber asks for the amounts of a
+	// response as bare JSON numbers rather than decimal strings, for clients
+	// that have not moved to strings yet.
+	AmountFormatHeader = "X-Amount-Format"
+	AmountFormatNumber = "number"
+)
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// sendResponse writes the response to a request that succeeded, with its
+// amounts in the format the request asked for.
+func sendResponse(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	if amounts, ok := data.(models.NumericAmounter); ok && r.Header.Get(AmountFormatHeader) == AmountFormatNumber {
+		data = amounts.NumericAmounts()
+	}
+	sendJSON(w, statusCode, data)
+}
+
+// extendDeadlines lets a streaming request (an account import, a statement
+// or a transfer batch) read its body and write its response for as long as
+// the request may take, which the router sets to STREAM_TIMEOUT, rather than
+// the server's read and write timeouts.
+func extendDeadlines(w http.ResponseWriter, r *http.Request) {
+	deadline, ok := r.Context().Deadline()
+	if !ok {
+		return
+	}
+
+	controller := http.NewResponseController(w)
+	controller.SetReadDeadline(deadline)
+	controller.SetWriteDeadline(deadline)
+}
+
 func sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -23,8 +56,17 @@ func sendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 }
 
 func sendError(w http.ResponseWriter, err error) {
-	statusCode := http.StatusInternalServerError
-	errorMessage := "Internal server error"
+	statusCode, errorMessage := errorStatus(err)
+	sendJSON(w, statusCode, ErrorResponse{Error: errorMessage})
+}
+
+// errorStatus maps an error to the HTTP status and message a request that
+// failed with it gets.
+func errorStatus(err error) (statusCode int, errorMessage string) {
+	statusCode = http.StatusInternalServerError
+	errorMessage = "Internal server error"
+
+	var currencyMismatch *models.CurrencyMismatchError
 
 	switch {
 	case errors.Is(err, models.ErrAccountNotFound):
@@ -45,15 +87,118 @@ func sendError(w http.ResponseWriter, err error) {
 	case errors.Is(err, models.ErrInvalidAmount):
 		statusCode = http.StatusBadRequest
 		errorMessage = "Amount must be positive"
+	case errors.Is(err, models.ErrInvalidAmountFormat):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Invalid amount format"
+	case errors.Is(err, models.ErrTooManyDecimals):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Amount has more decimal places than the currency allows"
+	case errors.Is(err, models.ErrUnsupportedCurrency):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Unsupported currency"
+	case errors.As(err, &currencyMismatch):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = fmt.Sprintf("Currency mismatch: source account is %s, destination account is %s",
+			currencyMismatch.SourceCurrency, currencyMismatch.DestinationCurrency)
+	case errors.Is(err, models.ErrSameCurrency):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Source and destination currencies must differ"
+	case errors.Is(err, models.ErrRateUnavailable):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Exchange rate not available"
+	case errors.Is(err, models.ErrQuoteNotFound):
+		statusCode = http.StatusNotFound
+		errorMessage = "FX quote not found"
+	case errors.Is(err, models.ErrQuoteExpired):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "FX quote has expired"
+	case errors.Is(err, models.ErrQuoteMismatch):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "FX quote does not match the accounts' currencies"
+	case errors.Is(err, models.ErrInvalidLegs):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Invalid transaction legs"
+	case errors.Is(err, models.ErrUnbalancedLegs):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Source and destination legs do not balance"
+	case errors.Is(err, models.ErrTransactionNotFound):
+		statusCode = http.StatusNotFound
+		errorMessage = "Transaction not found"
+	case errors.Is(err, models.ErrNotReversible):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Transaction can not be reversed"
+	case errors.Is(err, models.ErrReversalExceedsTotal):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Reversal exceeds the amount left to reverse"
+	case errors.Is(err, models.ErrPartialReversal):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Multi-leg transactions can only be reversed in full"
+	case errors.Is(err, models.ErrNotPending):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Transaction is not pending"
+	case errors.Is(err, models.ErrHoldExpired):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Hold has expired"
+	case errors.Is(err, models.ErrCaptureExceedsHold):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Capture exceeds the authorized amount"
+	case errors.Is(err, models.ErrAccountFrozen):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Account is frozen"
+	case errors.Is(err, models.ErrAccountClosed):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Account is closed"
+	case errors.Is(err, models.ErrAccountNotEmpty):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Account still holds funds"
+	case errors.Is(err, models.ErrInvalidStatusTransition):
+		statusCode = http.StatusConflict
+		errorMessage = "Account is already in that status"
+	case errors.Is(err, models.ErrInvalidOverdraftLimit):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Overdraft limit must be a non-negative amount"
+	case errors.Is(err, models.ErrOverdraftLimitExceeded):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Balance is below the requested overdraft limit"
+	case errors.Is(err, models.ErrInvalidAsOf):
+		statusCode = http.StatusBadRequest
+		errorMessage = "as_of must be a past RFC 3339 timestamp or date"
+	case errors.Is(err, models.ErrInvalidReason):
+		statusCode = http.StatusBadRequest
+		errorMessage = "A reason of at most 500 characters is required"
+	case errors.Is(err, models.ErrMissingActor):
+		statusCode = http.StatusBadRequest
+		errorMessage = "X-Actor header is required"
+	case errors.Is(err, models.ErrInvalidCursor):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Invalid pagination cursor"
+	case errors.Is(err, models.ErrInvalidFilter):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Invalid transaction filter"
 	case errors.Is(err, models.ErrSameAccount):
 		statusCode = http.StatusBadRequest
 		errorMessage = "Cannot transfer to same account"
-	case errors.Is(err, models.ErrDuplicateIdempotency):
-		statusCode = http.StatusConflict
-		errorMessage = "Duplicate idempotency key"
+	case errors.Is(err, models.ErrIdempotencyKeyReused):
+		statusCode = http.StatusUnprocessableEntity
+		errorMessage = "Idempotency-Key has already been used with a different request payload"
+	case errors.Is(err, models.ErrInvalidIdempotencyKey):
+		statusCode = http.StatusBadRequest
+		errorMessage = "Idempotency-Key and X-Client-ID must be at most 255 characters"
+	case errors.Is(err, models.ErrMissingClientID):
+		statusCode = http.StatusBadRequest
+		errorMessage = "X-Client-ID header is required with an idempotency key"
+	case errors.Is(err, models.ErrInvalidBatch):
+		statusCode = http.StatusBadRequest
+		errorMessage = fmt.Sprintf("A batch needs a mode of atomic or best_effort and 1 to %d transfers with distinct idempotency keys", models.MaxBatchSize)
+	case errors.Is(err, models.ErrInvalidStatementPeriod):
+		statusCode = http.StatusBadRequest
+		errorMessage = "from and to must be RFC 3339 timestamps or dates, from not after to and to not in the future"
+	case errors.Is(err, models.ErrInvalidStatementFormat):
+		statusCode = http.StatusBadRequest
+		errorMessage = "format must be csv, jsonl or ofx"
 	default:
 		log.Printf("Unexpected error: %v", err)
 	}
 
-	sendJSON(w, statusCode, ErrorResponse{Error: errorMessage})
+	return statusCode, errorMessage
 }
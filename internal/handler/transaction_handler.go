@@ -2,19 +2,31 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/filipe/financial-ledger-project/internal/models"
 	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/go-chi/chi/v5"
 )
 
 type TransactionHandler struct {
-	transferService *service.TransferService
+	keyScope
+	transferService    *service.TransferService
+	transactionService *service.TransactionService
 }
 
-func NewTransactionHandler(transferService *service.TransferService) *TransactionHandler {
+func NewTransactionHandler(
+	transferService *service.TransferService,
+	transactionService *service.TransactionService,
+) *TransactionHandler {
 	return &TransactionHandler{
-		transferService: transferService,
+		transferService:    transferService,
+		transactionService: transactionService,
 	}
 }
 
@@ -26,13 +38,187 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	idempotencyKey := r.Header.Get("Idempotency-Key")
+	key, err := h.requestKey(r)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	transaction, err := h.transferService.Transfer(r.Context(), req, key)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusCreated, transaction)
+}
+
+// CreateBatch runs a batch of transfers and reports the outcome of each. An
+// atomic batch answers 201 if it committed, or else the status of the
+// transfer that failed it; a best effort batch answers 201 if every transfer
+// succeeded and 207 otherwise.
+func (h *TransactionHandler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	// A large batch takes more than the server's read timeout to upload and
+	// can take longer to run than its write timeout allows.
+	extendDeadlines(w, r)
+
+	var req models.BatchTransferRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON"})
+		return
+	}
+
+	clientID, err := h.batchClientID(r, &req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	batch, err := h.transferService.TransferBatch(r.Context(), req, clientID)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	statusCode := http.StatusCreated
+	for i := range batch.Results {
+		result := &batch.Results[i]
+		if result.Err == nil {
+			continue
+		}
+		result.Code, result.Error = errorStatus(result.Err)
+
+		if batch.Mode == models.BatchModeAtomic {
+			statusCode = result.Code
+		} else {
+			statusCode = http.StatusMultiStatus
+		}
+	}
+
+	sendResponse(w, r, statusCode, batch)
+}
+
+func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "transaction_id")
+
+	transaction, err := h.transactionService.GetTransaction(r.Context(), transactionID)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, transaction)
+}
+
+// ReverseTransaction reverses all of a transaction, or the amount given in
+// the optional request body.
+func (h *TransactionHandler) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "transaction_id")
 
-	transaction, err := h.transferService.Transfer(r.Context(), req, idempotencyKey)
+	var req models.CreateReversalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON"})
+		return
+	}
+
+	key, err := h.requestKey(r)
 	if err != nil {
 		sendError(w, err)
 		return
 	}
 
-	sendJSON(w, http.StatusCreated, transaction)
+	reversal, err := h.transferService.Reverse(r.Context(), transactionID, req, key)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusCreated, reversal)
+}
+
+// CaptureTransaction settles a pending transfer, for the amount in the
+// optional request body or for all that was authorized.
+func (h *TransactionHandler) CaptureTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "transaction_id")
+
+	var req models.CaptureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid JSON"})
+		return
+	}
+
+	transaction, err := h.transferService.Capture(r.Context(), transactionID, req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, transaction)
+}
+
+func (h *TransactionHandler) VoidTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID := chi.URLParam(r, "transaction_id")
+
+	transaction, err := h.transferService.Void(r.Context(), transactionID)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, transaction)
+}
+
+func (h *TransactionHandler) ListAccountTransactions(w http.ResponseWriter, r *http.Request) {
+	accountIDStr := chi.URLParam(r, "account_id")
+	accountID, err := strconv.ParseInt(accountIDStr, 10, 64)
+	if err != nil {
+		sendJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid account ID"})
+		return
+	}
+
+	req, err := parseListTransactionsRequest(r.URL.Query())
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	page, err := h.transactionService.ListAccountTransactions(r.Context(), accountID, *req)
+	if err != nil {
+		sendError(w, err)
+		return
+	}
+
+	sendResponse(w, r, http.StatusOK, page)
+}
+
+// parseListTransactionsRequest reads direction, min_amount, max_amount, from,
+// to (RFC 3339), cursor and limit from the query string.
+func parseListTransactionsRequest(query url.Values) (*models.ListTransactionsRequest, error) {
+	req := &models.ListTransactionsRequest{
+		Direction: query.Get("direction"),
+		MinAmount: models.Decimal(query.Get("min_amount")),
+		MaxAmount: models.Decimal(query.Get("max_amount")),
+		Cursor:    query.Get("cursor"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, models.ErrInvalidFilter
+		}
+		req.Limit = value
+	}
+
+	for name, target := range map[string]**time.Time{"from": &req.From, "to": &req.To} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, models.ErrInvalidFilter
+			}
+			*target = &parsed
+		}
+	}
+
+	return req, nil
 }
@@ -0,0 +1,73 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/handler"
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/memory"
+	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCreateBatch_SlowUpload sends a batch whose body takes longer to arrive
+// than the server's read timeout, which the batch route must lift before it
+// reads the body.
+func TestCreateBatch_SlowUpload(t *testing.T) {
+	const readTimeout = 100 * time.Millisecond
+
+	store := memory.NewStore()
+	accountRepo := memory.NewAccountRepository(store)
+	transactionRepo := memory.NewTransactionRepository(store)
+	journalRepo := memory.NewJournalRepository(store)
+	idempotencyRepo := memory.NewIdempotencyRepository(store)
+
+	accountService := service.NewAccountService(store, accountRepo, journalRepo, idempotencyRepo)
+	transferService := service.NewTransferService(
+		store, accountRepo, transactionRepo, journalRepo, memory.NewQuoteRepository(store), idempotencyRepo, time.Hour,
+	)
+	transactionHandler := handler.NewTransactionHandler(transferService, service.NewTransactionService(accountRepo, transactionRepo))
+
+	ctx := context.Background()
+	for _, req := range []models.CreateAccountRequest{
+		{AccountID: 1, InitialBalance: "10.00"},
+		{AccountID: 2},
+	} {
+		require.NoError(t, accountService.CreateAccount(ctx, req, models.RequestKey{}))
+	}
+
+	router := chi.NewRouter()
+	router.With(middleware.Timeout(10*time.Second)).Post("/transactions/batch", transactionHandler.CreateBatch)
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.ReadTimeout = readTimeout
+	server.Config.WriteTimeout = readTimeout
+	server.Start()
+	defer server.Close()
+
+	body, upload := io.Pipe()
+	go func() {
+		io.WriteString(upload, `{"mode": "atomic", "transfers": [`)
+		time.Sleep(3 * readTimeout)
+		io.WriteString(upload, `{"source_account_id": 1, "destination_account_id": 2, "amount": "4.00"}]}`)
+		upload.Close()
+	}()
+
+	resp, err := http.Post(server.URL+"/transactions/batch", "application/json", body)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var batch models.BatchTransferResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 1, batch.Succeeded)
+}
@@ -5,38 +5,167 @@ import (
 	"time"
 )
 
+// Account is a customer ledger account. Balance is a cache of the sum of the
+// account's journal postings, maintained in the same database transaction
+// that writes them. HeldBalance is the part of it reserved by pending
+// transfers. OverdraftLimit is how far below zero the balance may go; nil
+// means no limit.
+//
+// A Hot account receives credits without being locked exclusively: they are
+// posted to the journal at once but only added to Balance when the account
+// is next locked for update, or by a background job. PendingCredits is their
+// sum until then. CanCover counts them like any other funds; a debit locks
+// the account for update, which reads it without pending credits and first
+// settles those of a hot account into Balance, so the database's overdraft
+// constraint on Balance sees the same sum.
 type Account struct {
-	ID        int64      `db:"id"`
-	Balance   int64      `db:"balance"`
-	CreatedAt time.Time  `db:"created_at"`
-	UpdatedAt *time.Time `db:"updated_at"`
+	ID             int64      `db:"id"`
+	Balance        int64      `db:"balance"`
+	HeldBalance    int64      `db:"held_balance"`
+	OverdraftLimit *int64     `db:"overdraft_limit"`
+	Currency       string     `db:"currency"`
+	Status         string     `db:"status"`
+	Hot            bool       `db:"hot"`
+	PendingCredits int64      `db:"pending_credits"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      *time.Time `db:"updated_at"`
+}
+
+// LedgerBalance is the sum of the account's postings, pending credits
+// included.
+func (a *Account) LedgerBalance() int64 {
+	return a.Balance + a.PendingCredits
+}
+
+// Available is the account's ledger balance less the funds held for pending
+// transfers.
+func (a *Account) Available() int64 {
+	return a.LedgerBalance() - a.HeldBalance
+}
+
+// CanCover reports whether the account can spend amount more without going
+// past its overdraft limit.
+func (a *Account) CanCover(amount int64) bool {
+	if a.OverdraftLimit == nil {
+		return true
+	}
+	return a.Available()+*a.OverdraftLimit >= amount
 }
 
 type AccountResponse struct {
-	AccountID int64   `json:"account_id"`
-	Balance   float64 `json:"balance"`
+	AccountID        int64   `json:"account_id"`
+	Currency         string  `json:"currency"`
+	Status           string  `json:"status"`
+	Balance          Decimal `json:"balance"`
+	AvailableBalance Decimal `json:"available_balance"`
+	// OverdraftLimit is null for accounts without a limit.
+	OverdraftLimit *Decimal `json:"overdraft_limit"`
+	Hot            bool     `json:"hot,omitempty"`
 }
 
 func (a *Account) ToResponse() AccountResponse {
-	return AccountResponse{
-		AccountID: a.ID,
-		Balance:   CentsToFloat(a.Balance),
+	response := AccountResponse{
+		AccountID:        a.ID,
+		Currency:         a.Currency,
+		Status:           a.Status,
+		Balance:          MinorUnitsToDecimal(a.LedgerBalance(), a.Currency),
+		AvailableBalance: MinorUnitsToDecimal(a.Available(), a.Currency),
+		Hot:              a.Hot,
 	}
+
+	if a.OverdraftLimit != nil {
+		limit := MinorUnitsToDecimal(*a.OverdraftLimit, a.Currency)
+		response.OverdraftLimit = &limit
+	}
+
+	return response
 }
 
 type CreateAccountRequest struct {
 	AccountID      int64   `json:"account_id"`
-	InitialBalance float64 `json:"initial_balance"`
+	Currency       string  `json:"currency"`
+	InitialBalance Decimal `json:"initial_balance"`
+	// Hot opens the account in hot mode, for accounts credited by most
+	// transfers such as fee and settlement accounts.
+	Hot bool `json:"hot,omitempty"`
+	OverdraftLimitRequest
+}
+
+// HotModeRequest turns an account's hot mode on or off.
+type HotModeRequest struct {
+	Hot bool `json:"hot"`
+}
+
+// OverdraftLimitRequest sets how far below zero an account may go. The limit
+// defaults to zero; UnlimitedOverdraft removes it altogether.
+type OverdraftLimitRequest struct {
+	OverdraftLimit     Decimal `json:"overdraft_limit,omitempty"`
+	UnlimitedOverdraft bool    `json:"unlimited_overdraft,omitempty"`
 }
 
 func (r *CreateAccountRequest) Validate() error {
 	if r.AccountID <= 0 {
 		return ErrInvalidAccountID
 	}
-	if r.InitialBalance < 0 {
+	balance, err := r.InitialBalanceInMinorUnits()
+	if err != nil {
+		return err
+	}
+	if balance < 0 {
 		return ErrNegativeBalance
 	}
-	return nil
+	currency, err := r.CurrencyCode()
+	if err != nil {
+		return err
+	}
+	_, err = r.LimitIn(currency)
+	return err
+}
+
+// LimitIn returns the requested limit in the minor units of currency, or nil
+// for no limit.
+func (r *OverdraftLimitRequest) LimitIn(currency string) (*int64, error) {
+	if r.UnlimitedOverdraft {
+		if r.OverdraftLimit != "" {
+			return nil, ErrInvalidOverdraftLimit
+		}
+		return nil, nil
+	}
+
+	var limit int64
+	if r.OverdraftLimit != "" {
+		var err error
+		if limit, err = DecimalToMinorUnits(r.OverdraftLimit, currency); err != nil {
+			return nil, err
+		}
+		if limit < 0 {
+			return nil, ErrInvalidOverdraftLimit
+		}
+	}
+
+	return &limit, nil
+}
+
+// CurrencyCode returns the normalized currency of the new account, defaulting
+// to DefaultCurrency when none was given.
+func (r *CreateAccountRequest) CurrencyCode() (string, error) {
+	if r.Currency == "" {
+		return DefaultCurrency, nil
+	}
+	return NormalizeCurrency(r.Currency)
+}
+
+// InitialBalanceInMinorUnits returns the requested opening balance in the
+// account currency's minor units. An omitted initial balance means zero.
+func (r *CreateAccountRequest) InitialBalanceInMinorUnits() (int64, error) {
+	currency, err := r.CurrencyCode()
+	if err != nil {
+		return 0, err
+	}
+	if r.InitialBalance == "" {
+		return 0, nil
+	}
+	return DecimalToMinorUnits(r.InitialBalance, currency)
 }
 
 func (a *Account) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Columns of an account import file. The header names them, in any order;
+// only account_id is required. Each row reads as the CreateAccountRequest
+// with the same JSON fields.
+var accountImportColumns = []string{
+	"account_id",
+	"currency",
+	"initial_balance",
+	"overdraft_limit",
+	"unlimited_overdraft",
+	"hot",
+}
+
+// AccountImportReader reads the rows of an account import file one at a time,
+// so files of any size can be imported without holding them in memory.
+type AccountImportReader struct {
+	csv     *csv.Reader
+	columns map[string]int
+	width   int
+}
+
+// NewAccountImportReader reads the header of an account import file.
+func NewAccountImportReader(r io.Reader) (*AccountImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the file is empty", ErrInvalidImportHeader)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImportHeader, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(accountImportColumns, name) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImportHeader, name)
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%w: column %q appears twice", ErrInvalidImportHeader, name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["account_id"]; !ok {
+		return nil, fmt.Errorf("%w: account_id column is required", ErrInvalidImportHeader)
+	}
+
+	return &AccountImportReader{csv: reader, columns: columns, width: len(header)}, nil
+}
+
+// AccountImportRow is one data row of an import file. Err is set if the row
+// could not be read as a request; Request is then incomplete.
+type AccountImportRow struct {
+	Line    int
+	Record  []string
+	Request CreateAccountRequest
+	Err     error
+}
+
+// Next returns the next row, or io.EOF after the last one. A row that is not
+// valid CSV or has the wrong number of fields is returned with Err set; any
+// other error ends the file.
+func (r *AccountImportReader) Next() (*AccountImportRow, error) {
+	record, err := r.csv.Read()
+
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		return &AccountImportRow{Line: parseErr.StartLine, Record: record, Err: fmt.Errorf("malformed CSV: %w", parseErr.Err)}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	row := &AccountImportRow{Line: line, Record: record}
+	if len(record) != r.width {
+		row.Err = fmt.Errorf("expected %d fields, found %d", r.width, len(record))
+		return row, nil
+	}
+
+	row.Request, row.Err = r.parse(record)
+	return row, nil
+}
+
+func (r *AccountImportReader) parse(record []string) (CreateAccountRequest, error) {
+	field := func(name string) string {
+		if i, ok := r.columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	flag := func(name string) (bool, error) {
+		value := field(name)
+		if value == "" {
+			return false, nil
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("%s must be true or false", name)
+		}
+		return parsed, nil
+	}
+
+	var req CreateAccountRequest
+	var err error
+
+	if req.AccountID, err = strconv.ParseInt(field("account_id"), 10, 64); err != nil {
+		return req, ErrInvalidAccountID
+	}
+	req.Currency = field("currency")
+	req.InitialBalance = Decimal(field("initial_balance"))
+	req.OverdraftLimit = Decimal(field("overdraft_limit"))
+	if req.UnlimitedOverdraft, err = flag("unlimited_overdraft"); err != nil {
+		return req, err
+	}
+	if req.Hot, err = flag("hot"); err != nil {
+		return req, err
+	}
+
+	return req, nil
+}
+
+// AccountOpening is an existing account as an import compares it: the
+// account and the opening balance it was funded with.
+type AccountOpening struct {
+	Account Account
+	Balance int64
+}
+
+// Conflict returns an error matching ErrImportConflict if the account and
+// opening balance of an import row differ from the stored ones, or nil if
+// the row only repeats them.
+func (o *AccountOpening) Conflict(account *Account, balance int64) error {
+	stored := &o.Account
+	var diffs []string
+	if account.Currency != stored.Currency {
+		diffs = append(diffs, fmt.Sprintf("currency is %s, not %s", stored.Currency, account.Currency))
+	}
+	if !equalLimits(account.OverdraftLimit, stored.OverdraftLimit) {
+		diffs = append(diffs, fmt.Sprintf("overdraft limit is %s, not %s",
+			formatLimit(stored.OverdraftLimit, stored.Currency), formatLimit(account.OverdraftLimit, account.Currency)))
+	}
+	if account.Hot != stored.Hot {
+		diffs = append(diffs, fmt.Sprintf("hot is %t, not %t", stored.Hot, account.Hot))
+	}
+	if balance != o.Balance {
+		diffs = append(diffs, fmt.Sprintf("opening balance is %s, not %s",
+			MinorUnitsToDecimal(o.Balance, stored.Currency), MinorUnitsToDecimal(balance, account.Currency)))
+	}
+
+	if len(diffs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("account %d %w: %s", stored.ID, ErrImportConflict, strings.Join(diffs, ", "))
+}
+
+func equalLimits(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatLimit(limit *int64, currency string) string {
+	if limit == nil {
+		return "unlimited"
+	}
+	return string(MinorUnitsToDecimal(*limit, currency))
+}
+
+// AccountImportReport is the outcome of an import. Rows whose account
+// already exists with the same settings and opening balance are skipped,
+// so a file can be imported again after a failure or with its rejected
+// rows fixed; rows that differ from their account are rejected.
+type AccountImportReport struct {
+	Rows     int                   `json:"rows"`
+	Created  int                   `json:"created"`
+	Existing int                   `json:"existing"`
+	Rejected []AccountImportReject `json:"rejected"`
+}
+
+// AccountImportReject is a row that was not imported and why.
+type AccountImportReject struct {
+	Line   int      `json:"line"`
+	Record []string `json:"record"`
+	Error  string   `json:"error"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccountImportReader_Header(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{"empty file", ""},
+		{"unknown column", "account_id,balance\n"},
+		{"duplicate column", "account_id,currency,Currency\n"},
+		{"no account_id", "currency,initial_balance\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAccountImportReader(strings.NewReader(tt.header))
+			assert.ErrorIs(t, err, ErrInvalidImportHeader)
+		})
+	}
+}
+
+func TestAccountImportReader_Next(t *testing.T) {
+	file := "\ufeffHot, account_id,initial_balance\n" +
+		"true,1,10.50\n" +
+		",2,\n" +
+		"yes,3,1\n" +
+		"false,x,1\n" +
+		"false,4\n" +
+		"false,\"5,1\n"
+
+	reader, err := NewAccountImportReader(strings.NewReader(file))
+	require.NoError(t, err)
+
+	row, err := reader.Next()
+	require.NoError(t, err)
+	assert.NoError(t, row.Err)
+	assert.Equal(t, 2, row.Line)
+	assert.Equal(t, CreateAccountRequest{AccountID: 1, InitialBalance: "10.50", Hot: true}, row.Request)
+
+	row, err = reader.Next()
+	require.NoError(t, err)
+	assert.NoError(t, row.Err)
+	assert.Equal(t, CreateAccountRequest{AccountID: 2}, row.Request)
+
+	row, err = reader.Next()
+	require.NoError(t, err)
+	assert.EqualError(t, row.Err, "hot must be true or false")
+
+	row, err = reader.Next()
+	require.NoError(t, err)
+	assert.ErrorIs(t, row.Err, ErrInvalidAccountID)
+
+	row, err = reader.Next()
+	require.NoError(t, err)
+	assert.EqualError(t, row.Err, "expected 3 fields, found 2")
+	assert.Equal(t, []string{"false", "4"}, row.Record)
+
+	row, err = reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 7, row.Line)
+	assert.ErrorContains(t, row.Err, "malformed CSV")
+
+	_, err = reader.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
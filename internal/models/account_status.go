@@ -0,0 +1,97 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	AccountStatusActive = "ACTIVE"
+	AccountStatusFrozen = "FROZEN"
+	AccountStatusClosed = "CLOSED"
+)
+
+// MaxStatusReasonLength caps the free-text reason given for a status change.
+const MaxStatusReasonLength = 500
+
+// CanSend reports whether money may leave the account. Frozen accounts can
+// still receive but not send; closed accounts can do neither.
+func (a *Account) CanSend() error {
+	switch a.Status {
+	case AccountStatusFrozen:
+		return ErrAccountFrozen
+	case AccountStatusClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// CanReceive reports whether money may enter the account.
+func (a *Account) CanReceive() error {
+	if a.Status == AccountStatusClosed {
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// Transition checks the account may move to the given status. Active and
+// frozen accounts switch freely and can be closed once they hold nothing;
+// closing is final.
+func (a *Account) Transition(to string) error {
+	switch {
+	case a.Status == AccountStatusClosed:
+		return ErrAccountClosed
+	case a.Status == to:
+		return ErrInvalidStatusTransition
+	case to == AccountStatusClosed && (a.Balance != 0 || a.HeldBalance != 0):
+		return ErrAccountNotEmpty
+	}
+	return nil
+}
+
+// AccountStatusChange records one status change of an account.
+type AccountStatusChange struct {
+	ID         int64     `db:"id"`
+	AccountID  int64     `db:"account_id"`
+	FromStatus string    `db:"from_status"`
+	ToStatus   string    `db:"to_status"`
+	Reason     string    `db:"reason"`
+	Actor      string    `db:"actor"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type AccountStatusChangeResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (c *AccountStatusChange) ToResponse() AccountStatusChangeResponse {
+	return AccountStatusChangeResponse{
+		FromStatus: c.FromStatus,
+		ToStatus:   c.ToStatus,
+		Reason:     c.Reason,
+		Actor:      c.Actor,
+		CreatedAt:  c.CreatedAt,
+	}
+}
+
+// ChangeAccountStatusRequest is the body of a freeze, unfreeze or close
+// request. Actor comes from the request headers rather than the body.
+type ChangeAccountStatusRequest struct {
+	Reason string `json:"reason"`
+	Actor  string `json:"-"`
+}
+
+func (r *ChangeAccountStatusRequest) Validate() error {
+	reason := strings.TrimSpace(r.Reason)
+	if reason == "" || len(reason) > MaxStatusReasonLength {
+		return ErrInvalidReason
+	}
+	if strings.TrimSpace(r.Actor) == "" {
+		return ErrMissingActor
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount_CanSendAndReceive(t *testing.T) {
+	tests := []struct {
+		status     string
+		sendErr    error
+		receiveErr error
+	}{
+		{AccountStatusActive, nil, nil},
+		{AccountStatusFrozen, ErrAccountFrozen, nil},
+		{AccountStatusClosed, ErrAccountClosed, ErrAccountClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			account := Account{ID: 1, Status: tt.status}
+			assert.Equal(t, tt.sendErr, account.CanSend())
+			assert.Equal(t, tt.receiveErr, account.CanReceive())
+		})
+	}
+}
+
+func TestAccount_Transition(t *testing.T) {
+	tests := []struct {
+		name        string
+		account     Account
+		to          string
+		expectError error
+	}{
+		{"Freeze", Account{Status: AccountStatusActive, Balance: 100}, AccountStatusFrozen, nil},
+		{"Unfreeze", Account{Status: AccountStatusFrozen, Balance: 100}, AccountStatusActive, nil},
+		{"Freeze twice", Account{Status: AccountStatusFrozen}, AccountStatusFrozen, ErrInvalidStatusTransition},
+		{"Unfreeze active", Account{Status: AccountStatusActive}, AccountStatusActive, ErrInvalidStatusTransition},
+		{"Close empty", Account{Status: AccountStatusActive}, AccountStatusClosed, nil},
+		{"Close frozen", Account{Status: AccountStatusFrozen}, AccountStatusClosed, nil},
+		{"Close with balance", Account{Status: AccountStatusActive, Balance: 1}, AccountStatusClosed, ErrAccountNotEmpty},
+		{"Close with overdrawn balance", Account{Status: AccountStatusActive, Balance: -1}, AccountStatusClosed, ErrAccountNotEmpty},
+		{"Close with hold", Account{Status: AccountStatusActive, Balance: 100, HeldBalance: 100}, AccountStatusClosed, ErrAccountNotEmpty},
+		{"Reopen closed", Account{Status: AccountStatusClosed}, AccountStatusActive, ErrAccountClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectError, tt.account.Transition(tt.to))
+		})
+	}
+}
+
+func TestChangeAccountStatusRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         ChangeAccountStatusRequest
+		expectError error
+	}{
+		{"Valid", ChangeAccountStatusRequest{Reason: "Suspected fraud", Actor: "ops@example.com"}, nil},
+		{"Missing reason", ChangeAccountStatusRequest{Actor: "ops@example.com"}, ErrInvalidReason},
+		{"Blank reason", ChangeAccountStatusRequest{Reason: "   ", Actor: "ops@example.com"}, ErrInvalidReason},
+		{"Reason too long", ChangeAccountStatusRequest{Reason: strings.Repeat("x", MaxStatusReasonLength+1), Actor: "ops"}, ErrInvalidReason},
+		{"Missing actor", ChangeAccountStatusRequest{Reason: "Suspected fraud"}, ErrMissingActor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectError, tt.req.Validate())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccount_CanCover(t *testing.T) {
+	limit := int64(5000)
+	noLimit := int64(0)
+
+	tests := []struct {
+		name     string
+		account  Account
+		amount   int64
+		expected bool
+	}{
+		{"Within balance", Account{Balance: 10000, OverdraftLimit: &noLimit}, 10000, true},
+		{"Past balance without overdraft", Account{Balance: 10000, OverdraftLimit: &noLimit}, 10001, false},
+		{"Holds count", Account{Balance: 10000, HeldBalance: 2000, OverdraftLimit: &noLimit}, 8001, false},
+		{"Into the overdraft", Account{Balance: 10000, OverdraftLimit: &limit}, 15000, true},
+		{"Past the overdraft", Account{Balance: 10000, OverdraftLimit: &limit}, 15001, false},
+		{"Already overdrawn", Account{Balance: -4000, OverdraftLimit: &limit}, 1001, false},
+		{"Unlimited", Account{Balance: -1000000}, 1000000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.account.CanCover(tt.amount))
+		})
+	}
+}
+
+func TestOverdraftLimitRequest_LimitIn(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         OverdraftLimitRequest
+		currency    string
+		expected    *int64
+		expectError error
+	}{
+		{"Defaults to zero", OverdraftLimitRequest{}, "USD", ptr(int64(0)), nil},
+		{"Limit", OverdraftLimitRequest{OverdraftLimit: "500.00"}, "USD", ptr(int64(50000)), nil},
+		{"Zero decimal currency", OverdraftLimitRequest{OverdraftLimit: "500"}, "JPY", ptr(int64(500)), nil},
+		{"Unlimited", OverdraftLimitRequest{UnlimitedOverdraft: true}, "USD", nil, nil},
+		{"Unlimited with a limit", OverdraftLimitRequest{OverdraftLimit: "1.00", UnlimitedOverdraft: true}, "USD", nil, ErrInvalidOverdraftLimit},
+		{"Negative", OverdraftLimitRequest{OverdraftLimit: "-1.00"}, "USD", nil, ErrInvalidOverdraftLimit},
+		{"Too many decimals", OverdraftLimitRequest{OverdraftLimit: "1.5"}, "JPY", nil, ErrTooManyDecimals},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := tt.req.LimitIn(tt.currency)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, limit)
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
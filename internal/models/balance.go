@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// BalanceResponse is an account's balance as it stood at AsOf.
+type BalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   Decimal   `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+}
+
+// ParseAsOf reads a point in time for a balance query: either an RFC 3339
+// timestamp, or a date (YYYY-MM-DD) meaning the end of that day in UTC.
+// Postings are stored to the microsecond, so the result is truncated to it
+// and can be compared inclusively.
+func ParseAsOf(value string, now time.Time) (time.Time, error) {
+	asOf, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		day, dateErr := time.Parse(time.DateOnly, value)
+		if dateErr != nil {
+			return time.Time{}, ErrInvalidAsOf
+		}
+		asOf = day.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+
+	asOf = asOf.Truncate(time.Microsecond)
+
+	// Transfers still in flight could yet commit with earlier timestamps, so
+	// a balance in the future is not stable.
+	if asOf.After(now) {
+		return time.Time{}, ErrInvalidAsOf
+	}
+
+	return asOf, nil
+}
+
+// BalanceSnapshot is an account's balance at the end of a day.
+type BalanceSnapshot struct {
+	AccountID int64     `db:"account_id"`
+	AsOf      time.Time `db:"as_of"`
+	Balance   int64     `db:"balance"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// DayEnd returns the last microsecond of t's day in UTC, the as_of of that
+// day's snapshots.
+func DayEnd(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC).Add(-time.Microsecond)
+}
+
+// LastSettledDayEnd returns the end of the latest day that ended at least
+// settleDelay before now. Transfers are timestamped before they commit, so
+// a day is only snapshotted once any transfer that started in it has had
+// time to finish.
+func LastSettledDayEnd(now time.Time, settleDelay time.Duration) time.Time {
+	return DayEnd(now.Add(-settleDelay).AddDate(0, 0, -1))
+}
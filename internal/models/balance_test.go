@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAsOf(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectError error
+	}{
+		{
+			name:     "Timestamp",
+			value:    "2024-03-01T09:30:00Z",
+			expected: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "Timestamp with offset",
+			value:    "2024-03-01T09:30:00+02:00",
+			expected: time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "Nanoseconds are truncated",
+			value:    "2024-03-01T09:30:00.123456789Z",
+			expected: time.Date(2024, 3, 1, 9, 30, 0, 123456000, time.UTC),
+		},
+		{
+			name:     "Date is the end of the day",
+			value:    "2024-02-29",
+			expected: time.Date(2024, 2, 29, 23, 59, 59, 999999000, time.UTC),
+		},
+		{
+			name:        "Future",
+			value:       "2024-03-15T12:00:01Z",
+			expectError: ErrInvalidAsOf,
+		},
+		{
+			name:        "Today has not ended",
+			value:       "2024-03-15",
+			expectError: ErrInvalidAsOf,
+		},
+		{
+			name:        "Garbage",
+			value:       "yesterday",
+			expectError: ErrInvalidAsOf,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asOf, err := ParseAsOf(tt.value, now)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(asOf), "expected %s, got %s", tt.expected, asOf)
+		})
+	}
+}
+
+func TestDayEnd(t *testing.T) {
+	expected := time.Date(2024, 2, 29, 23, 59, 59, 999999000, time.UTC)
+
+	assert.Equal(t, expected, DayEnd(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, expected, DayEnd(time.Date(2024, 2, 29, 23, 59, 59, 999999999, time.UTC)))
+	assert.Equal(t, expected, DayEnd(time.Date(2024, 3, 1, 1, 0, 0, 0, time.FixedZone("CET", 3600)).Add(-time.Second)))
+	assert.Equal(t, expected, DayEnd(expected))
+}
+
+func TestLastSettledDayEnd(t *testing.T) {
+	dayEnd := time.Date(2024, 3, 14, 23, 59, 59, 999999000, time.UTC)
+
+	// Just past midnight the previous day is not settled yet
+	assert.Equal(t, dayEnd.AddDate(0, 0, -1), LastSettledDayEnd(time.Date(2024, 3, 15, 0, 2, 0, 0, time.UTC), 5*time.Minute))
+	assert.Equal(t, dayEnd, LastSettledDayEnd(time.Date(2024, 3, 15, 0, 5, 0, 0, time.UTC), 5*time.Minute))
+	assert.Equal(t, dayEnd, LastSettledDayEnd(time.Date(2024, 3, 15, 23, 0, 0, 0, time.UTC), 5*time.Minute))
+}
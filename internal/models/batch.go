@@ -0,0 +1,76 @@
+package models
+
+// Batch modes: an atomic batch commits all of its transfers or none, a best
+// effort batch commits each transfer that succeeds on its own.
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+)
+
+// MaxBatchSize bounds the number of transfers in one batch.
+const MaxBatchSize = 1000
+
+// Outcomes of the transfers of a batch.
+const (
+	BatchItemCompleted  = "completed"
+	BatchItemFailed     = "failed"
+	BatchItemRolledBack = "rolled_back"
+	BatchItemSkipped    = "skipped"
+)
+
+type BatchTransferRequest struct {
+	Mode      string              `json:"mode"`
+	Transfers []BatchTransferItem `json:"transfers"`
+}
+
+// BatchTransferItem is a transfer of a batch. Its idempotency key plays the
+// part of the Idempotency-Key header of a single transfer.
+type BatchTransferItem struct {
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	CreateTransactionRequest
+}
+
+// Validate checks the batch as a whole; each transfer is validated when it
+// is run. Idempotency keys must be unique within the batch.
+func (r *BatchTransferRequest) Validate() error {
+	if r.Mode != BatchModeAtomic && r.Mode != BatchModeBestEffort {
+		return ErrInvalidBatch
+	}
+	if len(r.Transfers) == 0 || len(r.Transfers) > MaxBatchSize {
+		return ErrInvalidBatch
+	}
+
+	keys := make(map[string]bool, len(r.Transfers))
+	for _, item := range r.Transfers {
+		if item.IdempotencyKey == "" {
+			continue
+		}
+		if keys[item.IdempotencyKey] {
+			return ErrInvalidBatch
+		}
+		keys[item.IdempotencyKey] = true
+	}
+
+	return nil
+}
+
+// BatchTransferResult is the outcome of one transfer of a batch, at the same
+// index as in the request. Err is the error a failed transfer returned; the
+// handler renders it into Error and Code, the HTTP status the transfer would
+// have had on its own.
+type BatchTransferResult struct {
+	Index          int                  `json:"index"`
+	IdempotencyKey string               `json:"idempotency_key,omitempty"`
+	Status         string               `json:"status"`
+	Transaction    *TransactionResponse `json:"transaction,omitempty"`
+	Code           int                  `json:"code,omitempty"`
+	Error          string               `json:"error,omitempty"`
+	Err            error                `json:"-"`
+}
+
+type BatchTransferResponse struct {
+	Mode      string                `json:"mode"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []BatchTransferResult `json:"results"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchTransferRequest_Validate(t *testing.T) {
+	transfer := CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "1.00"}
+
+	valid := BatchTransferRequest{
+		Mode: BatchModeAtomic,
+		Transfers: []BatchTransferItem{
+			{IdempotencyKey: "a", CreateTransactionRequest: transfer},
+			{CreateTransactionRequest: transfer},
+			{CreateTransactionRequest: transfer},
+		},
+	}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name string
+		req  BatchTransferRequest
+	}{
+		{"unknown mode", BatchTransferRequest{Mode: "all", Transfers: valid.Transfers}},
+		{"no transfers", BatchTransferRequest{Mode: BatchModeBestEffort}},
+		{"too many transfers", BatchTransferRequest{Mode: BatchModeBestEffort, Transfers: make([]BatchTransferItem, MaxBatchSize+1)}},
+		{"duplicate keys", BatchTransferRequest{Mode: BatchModeAtomic, Transfers: []BatchTransferItem{
+			{IdempotencyKey: "a", CreateTransactionRequest: transfer},
+			{IdempotencyKey: "a", CreateTransactionRequest: transfer},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.req.Validate(), ErrInvalidBatch)
+		})
+	}
+}
@@ -1,11 +1,136 @@
 package models
 
-import "math"
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
 
-func CentsToFloat(cents int64) float64 {
-	return float64(cents) / 100.0
+// Decimal is a monetary amount carried as its exact decimal text, e.g. "100.13".
+//
+// It is emitted as a JSON string; a response's NumericAmounts variant emits
+// it as a bare number instead. For clients still sending the old numeric
+// form it also accepts a bare JSON number, which is read from its literal
+// text and never goes through float64.
+type Decimal string
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(d))
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if bytes.Equal(data, []byte("null")) {
+		*d = ""
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*d = Decimal(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*d = Decimal(n.String())
+	return nil
+}
+
+// DecimalToMinorUnits converts a decimal amount into the currency's minor
+// units (cents for USD, yen for JPY, fils for KWD). Amounts with more
+// fractional digits than the currency allows are rejected with
+// ErrTooManyDecimals instead of being rounded.
+func DecimalToMinorUnits(amount Decimal, currency string) (int64, error) {
+	units, err := MinorUnits(currency)
+	if err != nil {
+		return 0, err
+	}
+	return parseDecimal(string(amount), units)
+}
+
+// MinorUnitsToDecimal formats an amount in minor units using the currency's
+// exponent, e.g. 10050 USD -> "100.50", 10050 JPY -> "10050".
+func MinorUnitsToDecimal(amount int64, currency string) Decimal {
+	units, err := MinorUnits(currency)
+	if err != nil {
+		units = minorUnits[DefaultCurrency]
+	}
+	return Decimal(formatDecimal(amount, units))
+}
+
+// parseDecimal parses "[-]digits[.digits]" into an integer number of units of
+// 10^-scale. Exponents, signs other than a leading minus and surrounding
+// whitespace are not accepted.
+func parseDecimal(s string, scale int) (int64, error) {
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasPoint := strings.Cut(s, ".")
+	if intPart == "" || (hasPoint && fracPart == "") {
+		return 0, ErrInvalidAmountFormat
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidAmountFormat
+	}
+
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > scale {
+		return 0, ErrTooManyDecimals
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	value, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmountFormat
+	}
+
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+func formatDecimal(value int64, scale int) string {
+	sign := ""
+	var digits string
+	if value < 0 {
+		sign = "-"
+		if value == math.MinInt64 {
+			digits = strconv.FormatInt(value, 10)[1:]
+		} else {
+			digits = strconv.FormatInt(-value, 10)
+		}
+	} else {
+		digits = strconv.FormatInt(value, 10)
+	}
+
+	if scale == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	point := len(digits) - scale
+	return sign + digits[:point] + "." + digits[point:]
 }
 
-func FloatToCents(amount float64) int64 {
-	return int64(math.Round(amount * 100))
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
 }
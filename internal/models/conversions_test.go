@@ -1,68 +1,156 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestCentsToFloat(t *testing.T) {
+func TestMinorUnitsToDecimal(t *testing.T) {
 	tests := []struct {
 		name     string
-		cents    int64
-		expected float64
+		amount   int64
+		currency string
+		expected Decimal
 	}{
-		{"Zero", 0, 0.0},
-		{"One dollar", 100, 1.0},
-		{"One hundred dollars", 10000, 100.0},
-		{"Decimal amount", 10050, 100.50},
-		{"Complex decimal", 12345, 123.45},
-		{"Single cent", 1, 0.01},
-		{"Large amount", 123456789, 1234567.89},
+		{"Zero", 0, "USD", "0.00"},
+		{"One dollar", 100, "USD", "1.00"},
+		{"One hundred dollars", 10000, "USD", "100.00"},
+		{"Decimal amount", 10050, "USD", "100.50"},
+		{"Complex decimal", 12345, "USD", "123.45"},
+		{"Single cent", 1, "USD", "0.01"},
+		{"Large amount", 123456789, "USD", "1234567.89"},
+		{"Negative amount", -10050, "USD", "-100.50"},
+		{"Negative single cent", -1, "USD", "-0.01"},
+		{"Zero decimal currency", 10050, "JPY", "10050"},
+		{"Three decimal currency", 10050, "KWD", "10.050"},
+		{"Single fils", 1, "KWD", "0.001"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := CentsToFloat(tt.cents)
+			result := MinorUnitsToDecimal(tt.amount, tt.currency)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
-func TestFloatToCents(t *testing.T) {
+func TestDecimalToMinorUnits(t *testing.T) {
 	tests := []struct {
-		name     string
-		amount   float64
-		expected int64
+		name        string
+		amount      Decimal
+		currency    string
+		expected    int64
+		expectError error
 	}{
-		{"Zero", 0.0, 0},
-		{"One dollar", 1.0, 100},
-		{"One hundred dollars", 100.0, 10000},
-		{"Decimal amount", 100.50, 10050},
-		{"Complex decimal", 123.45, 12345},
-		{"Single cent", 0.01, 1},
-		{"Large amount", 1234567.89, 123456789},
-		{"Precision handling", 100.123, 10012},
-		{"Precision handling 2", 100.126, 10013},
+		{"Zero", "0", "USD", 0, nil},
+		{"One dollar", "1", "USD", 100, nil},
+		{"One hundred dollars", "100.00", "USD", 10000, nil},
+		{"Decimal amount", "100.50", "USD", 10050, nil},
+		{"Single fractional digit", "100.5", "USD", 10050, nil},
+		{"Complex decimal", "123.45", "USD", 12345, nil},
+		{"Single cent", "0.01", "USD", 1, nil},
+		{"Large amount", "1234567.89", "USD", 123456789, nil},
+		{"Trailing zeros", "1.2300", "USD", 123, nil},
+		{"Negative amount", "-100.50", "USD", -10050, nil},
+		{"Too many decimals", "100.123", "USD", 0, ErrTooManyDecimals},
+		{"Too many decimals 2", "100.126", "USD", 0, ErrTooManyDecimals},
+		{"Zero decimal currency", "10050", "JPY", 10050, nil},
+		{"Zero decimal currency with zero fraction", "10050.00", "JPY", 10050, nil},
+		{"Zero decimal currency with fraction", "100.5", "JPY", 0, ErrTooManyDecimals},
+		{"Three decimal currency", "10.050", "KWD", 10050, nil},
+		{"Three decimal currency too many decimals", "10.0501", "KWD", 0, ErrTooManyDecimals},
+		{"Unsupported currency", "1.00", "XXX", 0, ErrUnsupportedCurrency},
+		{"Empty", "", "USD", 0, ErrInvalidAmountFormat},
+		{"Exponent", "1e2", "USD", 0, ErrInvalidAmountFormat},
+		{"Leading point", ".5", "USD", 0, ErrInvalidAmountFormat},
+		{"Trailing point", "5.", "USD", 0, ErrInvalidAmountFormat},
+		{"Plus sign", "+5", "USD", 0, ErrInvalidAmountFormat},
+		{"Whitespace", " 5", "USD", 0, ErrInvalidAmountFormat},
+		{"Overflow", "99999999999999999999", "USD", 0, ErrInvalidAmountFormat},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := FloatToCents(tt.amount)
+			result, err := DecimalToMinorUnits(tt.amount, tt.currency)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
 func TestRoundTrip(t *testing.T) {
-	// Test that converting cents -> float -> cents maintains precision
-	tests := []int64{0, 1, 100, 10050, 123456789}
+	// Test that converting minor units -> decimal -> minor units maintains precision
+	tests := []int64{0, 1, 100, 10050, 123456789, -10050}
+
+	for _, currency := range []string{"USD", "JPY", "KWD"} {
+		for _, amount := range tests {
+			t.Run("RoundTrip "+currency, func(t *testing.T) {
+				decimal := MinorUnitsToDecimal(amount, currency)
+				back, err := DecimalToMinorUnits(decimal, currency)
+				assert.NoError(t, err)
+				assert.Equal(t, amount, back, "Round trip conversion should maintain value")
+			})
+		}
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	currency, err := NormalizeCurrency("kwd")
+	assert.NoError(t, err)
+	assert.Equal(t, "KWD", currency)
+
+	_, err = NormalizeCurrency("ABC")
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
+
+func TestDecimal_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Decimal
+	}{
+		{"String", `{"amount": "100.13"}`, "100.13"},
+		{"Legacy number", `{"amount": 100.13}`, "100.13"},
+		{"Legacy number keeps extra digits", `{"amount": 100.126}`, "100.126"},
+		{"Null", `{"amount": null}`, ""},
+		{"Missing", `{}`, ""},
+	}
 
-	for _, cents := range tests {
-		t.Run("RoundTrip", func(t *testing.T) {
-			floatVal := CentsToFloat(cents)
-			backToCents := FloatToCents(floatVal)
-			assert.Equal(t, cents, backToCents, "Round trip conversion should maintain value")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Amount Decimal `json:"amount"`
+			}
+			err := json.Unmarshal([]byte(tt.input), &body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, body.Amount)
 		})
 	}
+
+	var body struct {
+		Amount Decimal `json:"amount"`
+	}
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": true}`), &body))
+}
+
+func TestDecimal_MarshalJSON(t *testing.T) {
+	limit := Decimal("0.00")
+	account := AccountResponse{
+		AccountID:        1,
+		Currency:         "USD",
+		Status:           AccountStatusActive,
+		Balance:          "100.50",
+		AvailableBalance: "90.50",
+		OverdraftLimit:   &limit,
+	}
+
+	data, err := json.Marshal(account)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"account_id": 1, "currency": "USD", "status": "ACTIVE", "balance": "100.50", "available_balance": "90.50", "overdraft_limit": "0.00"}`, string(data))
 }
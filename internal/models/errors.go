@@ -1,15 +1,71 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrAccountNotFound      = errors.New("account not found")
-	ErrAccountExists        = errors.New("account already exists")
-	ErrInvalidAccountID     = errors.New("invalid account ID")
-	ErrNegativeBalance      = errors.New("balance cannot be negative")
-	ErrInsufficientFunds    = errors.New("insufficient funds")
-	ErrInvalidAmount        = errors.New("amount must be positive")
-	ErrSameAccount          = errors.New("cannot transfer to same account")
-	ErrTransactionNotFound  = errors.New("transaction not found")
-	ErrDuplicateIdempotency = errors.New("duplicate idempotency key")
+	ErrAccountNotFound         = errors.New("account not found")
+	ErrAccountExists           = errors.New("account already exists")
+	ErrInvalidAccountID        = errors.New("invalid account ID")
+	ErrNegativeBalance         = errors.New("balance cannot be negative")
+	ErrInsufficientFunds       = errors.New("insufficient funds")
+	ErrInvalidAmount           = errors.New("amount must be positive")
+	ErrSameAccount             = errors.New("cannot transfer to same account")
+	ErrTransactionNotFound     = errors.New("transaction not found")
+	ErrIdempotencyKeyReused    = errors.New("idempotency key was used with a different request")
+	ErrInvalidIdempotencyKey   = errors.New("idempotency key and client ID must be at most 255 characters")
+	ErrMissingClientID         = errors.New("client ID is required with an idempotency key")
+	ErrInvalidAmountFormat     = errors.New("invalid amount format")
+	ErrTooManyDecimals         = errors.New("amount has more decimal places than the currency allows")
+	ErrUnsupportedCurrency     = errors.New("unsupported currency")
+	ErrCurrencyMismatch        = errors.New("accounts have different currencies")
+	ErrSameCurrency            = errors.New("source and destination currencies must differ")
+	ErrRateUnavailable         = errors.New("exchange rate not available")
+	ErrInvalidRate             = errors.New("invalid exchange rate")
+	ErrQuoteNotFound           = errors.New("fx quote not found")
+	ErrQuoteExpired            = errors.New("fx quote has expired")
+	ErrQuoteMismatch           = errors.New("fx quote does not match the accounts' currencies")
+	ErrUnbalancedEntry         = errors.New("journal entry postings do not balance")
+	ErrInvalidLegs             = errors.New("invalid transaction legs")
+	ErrUnbalancedLegs          = errors.New("source and destination legs do not balance")
+	ErrInvalidCursor           = errors.New("invalid pagination cursor")
+	ErrInvalidFilter           = errors.New("invalid transaction filter")
+	ErrNotReversible           = errors.New("transaction can not be reversed")
+	ErrReversalExceedsTotal    = errors.New("reversal exceeds the amount left to reverse")
+	ErrPartialReversal         = errors.New("multi-leg transactions can only be reversed in full")
+	ErrNotPending              = errors.New("transaction is not pending")
+	ErrHoldExpired             = errors.New("hold has expired")
+	ErrCaptureExceedsHold      = errors.New("capture exceeds the authorized amount")
+	ErrAccountFrozen           = errors.New("account is frozen")
+	ErrAccountClosed           = errors.New("account is closed")
+	ErrAccountNotEmpty         = errors.New("account still holds funds")
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+	ErrInvalidReason           = errors.New("a reason of at most 500 characters is required")
+	ErrInvalidOverdraftLimit   = errors.New("invalid overdraft limit")
+	ErrOverdraftLimitExceeded  = errors.New("balance is below the requested overdraft limit")
+	ErrInvalidAsOf             = errors.New("invalid as_of timestamp")
+	ErrMissingActor            = errors.New("actor is required")
+	ErrInvalidBatch            = errors.New("invalid transfer batch")
+	ErrInvalidImportHeader     = errors.New("invalid account import header")
+	ErrImportConflict          = errors.New("conflicts with existing account")
+	ErrInvalidStatementPeriod  = errors.New("invalid statement period")
+	ErrInvalidStatementFormat  = errors.New("invalid statement format")
 )
+
+// CurrencyMismatchError is returned when a transfer involves accounts held in
+// different currencies. It matches ErrCurrencyMismatch with errors.Is.
+type CurrencyMismatchError struct {
+	SourceCurrency      string
+	DestinationCurrency string
+}
+
+func (e *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("currency mismatch: source account is %s, destination account is %s",
+		e.SourceCurrency, e.DestinationCurrency)
+}
+
+func (e *CurrencyMismatchError) Is(target error) bool {
+	return target == ErrCurrencyMismatch
+}
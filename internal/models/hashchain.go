@@ -0,0 +1,183 @@
+package models
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GenesisHash is the previous hash of the first transaction in a chain.
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// ChainAccount returns the account whose hash chain the transaction joins:
+// its source, or for a multi-leg transaction its lowest source account. That
+// account is locked whenever the transaction is created, which serializes
+// each chain.
+func (t *Transaction) ChainAccount() int64 {
+	if !t.IsMultiLeg() {
+		return t.SourceAccountID
+	}
+
+	account := t.Sources[0].AccountID
+	for _, leg := range t.Sources[1:] {
+		account = min(account, leg.AccountID)
+	}
+	return account
+}
+
+// Seal appends the transaction to its account's chain after the record with
+// sequence number headSeq and hash headHash (0 and GenesisHash for an empty
+// chain). It must be called once CreatedAt is known.
+func (t *Transaction) Seal(headSeq int64, headHash string) {
+	t.ChainAccountID = t.ChainAccount()
+	t.ChainSeq = headSeq + 1
+	t.PrevHash = headHash
+	t.Hash = t.ComputeHash()
+}
+
+// ComputeHash returns the SHA-256 of the transaction's chained contents.
+//
+// Only what is fixed when the transaction is created is covered. Status and
+// reversed amount change when it is reversed, and each reversal is chained
+// as a transaction of its own. A hold is covered by its authorized amount
+// and rate; how it ends is sealed by Settle.
+func (t *Transaction) ComputeHash() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "v1\n")
+	fmt.Fprintf(&b, "id=%s\n", t.ID)
+	fmt.Fprintf(&b, "chain=%d:%d\n", t.ChainAccountID, t.ChainSeq)
+	fmt.Fprintf(&b, "prev=%s\n", t.PrevHash)
+	fmt.Fprintf(&b, "source=%d\n", t.SourceAccountID)
+	fmt.Fprintf(&b, "destination=%d\n", t.DestinationAccountID)
+
+	if t.AuthorizedAmount > 0 {
+		fmt.Fprintf(&b, "authorized=%d %s\n", t.AuthorizedAmount, t.Currency)
+		fmt.Fprintf(&b, "destination_currency=%s\n", t.DestinationCurrency)
+	} else {
+		fmt.Fprintf(&b, "amount=%d %s\n", t.Amount, t.Currency)
+		fmt.Fprintf(&b, "destination_amount=%d %s\n", t.DestinationAmount, t.DestinationCurrency)
+	}
+
+	if t.FXRate != nil {
+		fmt.Fprintf(&b, "fx_rate=%s\n", *t.FXRate)
+	}
+	if t.ReversalOf != nil {
+		fmt.Fprintf(&b, "reversal_of=%s\n", *t.ReversalOf)
+	}
+
+	for _, leg := range sortedLegs(t.Sources) {
+		fmt.Fprintf(&b, "source_leg=%d %d\n", leg.AccountID, leg.Amount)
+	}
+	for _, leg := range sortedLegs(t.Destinations) {
+		fmt.Fprintf(&b, "destination_leg=%d %d\n", leg.AccountID, leg.Amount)
+	}
+
+	fmt.Fprintf(&b, "created_at=%d\n", t.CreatedAt.UnixMicro())
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Settle seals how a hold ended, once it is captured, voided or expires:
+// the settlement hash chains its status and amounts to the hold's own hash.
+// A hold created before chaining began has nothing to chain to and stays
+// unsettled.
+func (t *Transaction) Settle() {
+	if t.Hash != "" {
+		t.SettlementHash = t.ComputeSettlementHash()
+	}
+}
+
+// ComputeSettlementHash returns the SHA-256 of how a hold ended. A captured
+// hold that has since been reversed is still settled as completed; its
+// reversals are chained on their own.
+func (t *Transaction) ComputeSettlementHash() string {
+	status := t.Status
+	if status == StatusPartiallyReversed || status == StatusReversed {
+		status = StatusCompleted
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "v1 settlement\n")
+	fmt.Fprintf(&b, "id=%s\n", t.ID)
+	fmt.Fprintf(&b, "prev=%s\n", t.Hash)
+	fmt.Fprintf(&b, "status=%s\n", status)
+	fmt.Fprintf(&b, "amount=%d %s\n", t.Amount, t.Currency)
+	fmt.Fprintf(&b, "destination_amount=%d %s\n", t.DestinationAmount, t.DestinationCurrency)
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// settlementIntact reports whether a hold's settlement matches its status:
+// only a hold that has ended is settled, and then for what it now records.
+func (t *Transaction) settlementIntact() bool {
+	if t.AuthorizedAmount == 0 || t.Status == StatusPending {
+		return t.SettlementHash == ""
+	}
+	return t.SettlementHash == t.ComputeSettlementHash()
+}
+
+// sortedLegs orders legs independently of how they were requested or loaded.
+func sortedLegs(legs []Leg) []Leg {
+	sorted := slices.Clone(legs)
+	slices.SortFunc(sorted, func(a, b Leg) int {
+		if a.AccountID != b.AccountID {
+			return cmp.Compare(a.AccountID, b.AccountID)
+		}
+		return cmp.Compare(a.Amount, b.Amount)
+	})
+	return sorted
+}
+
+const (
+	ChainBreakHash     = "hash_mismatch"
+	ChainBreakLink     = "broken_link"
+	ChainBreakMissing  = "missing_record"
+	ChainBreakUnsealed = "unsealed_record"
+	// ChainBreakSettlement is a hold whose status or captured amounts do
+	// not match its settlement hash.
+	ChainBreakSettlement = "settlement_mismatch"
+)
+
+// ChainBreak is the first point at which an account's chain fails to
+// verify. Records after it can not be trusted until it is explained.
+type ChainBreak struct {
+	AccountID     int64
+	Seq           int64
+	TransactionID string
+	Reason        string
+}
+
+// VerifyChainLink checks a record against the one before it in its chain
+// (prevSeq 0 and GenesisHash for the first record). It returns the reason
+// the link is broken, or "" if it holds.
+func (t *Transaction) VerifyChainLink(prevSeq int64, prevHash string) string {
+	switch {
+	case t.ChainSeq != prevSeq+1:
+		return ChainBreakMissing
+	case t.PrevHash != prevHash:
+		return ChainBreakLink
+	case t.Hash != t.ComputeHash():
+		return ChainBreakHash
+	case !t.settlementIntact():
+		return ChainBreakSettlement
+	}
+	return ""
+}
+
+// ChainReport is the result of verifying every account's chain.
+type ChainReport struct {
+	AccountsChecked     int
+	TransactionsChecked int
+	Breaks              []ChainBreak
+}
+
+func (r *ChainReport) Intact() bool {
+	return len(r.Breaks) == 0
+}
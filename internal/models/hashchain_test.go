@@ -0,0 +1,156 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sealedTransfer(headSeq int64, headHash string) *Transaction {
+	transaction := &Transaction{
+		ID:                   "5f0c6d3e-8b1a-4c2e-9a57-0d5c8e2f6b11",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               10050,
+		Currency:             "USD",
+		DestinationAmount:    10050,
+		DestinationCurrency:  "USD",
+		Status:               StatusCompleted,
+		CreatedAt:            time.Date(2024, 3, 15, 12, 0, 0, 123456000, time.UTC),
+	}
+	transaction.Seal(headSeq, headHash)
+	return transaction
+}
+
+func TestTransaction_Seal(t *testing.T) {
+	first := sealedTransfer(0, GenesisHash)
+	assert.Equal(t, int64(1), first.ChainAccountID)
+	assert.Equal(t, int64(1), first.ChainSeq)
+	assert.Equal(t, GenesisHash, first.PrevHash)
+	assert.Len(t, first.Hash, 64)
+	assert.Equal(t, first.Hash, sealedTransfer(0, GenesisHash).Hash)
+
+	second := sealedTransfer(first.ChainSeq, first.Hash)
+	assert.Equal(t, int64(2), second.ChainSeq)
+	assert.NotEqual(t, first.Hash, second.Hash)
+}
+
+func TestTransaction_ComputeHash(t *testing.T) {
+	original := sealedTransfer(0, GenesisHash)
+
+	tests := []struct {
+		name    string
+		change  func(*Transaction)
+		changes bool
+	}{
+		{"amount", func(t *Transaction) { t.Amount++ }, true},
+		{"destination", func(t *Transaction) { t.DestinationAccountID = 3 }, true},
+		{"created_at", func(t *Transaction) { t.CreatedAt = t.CreatedAt.Add(time.Microsecond) }, true},
+		{"previous hash", func(t *Transaction) { t.PrevHash = sealedTransfer(0, GenesisHash).Hash }, true},
+		{"status", func(t *Transaction) { t.Status = StatusReversed }, false},
+		{"reversed amount", func(t *Transaction) { t.ReversedAmount = 100 }, false},
+		{"time zone", func(t *Transaction) { t.CreatedAt = t.CreatedAt.In(time.FixedZone("CET", 3600)) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := *original
+			tt.change(&changed)
+			assert.Equal(t, tt.changes, changed.ComputeHash() != original.Hash)
+		})
+	}
+}
+
+func TestTransaction_ComputeHash_CapturedHold(t *testing.T) {
+	hold := sealedTransfer(0, GenesisHash)
+	hold.AuthorizedAmount = hold.Amount
+	hold.Hash = hold.ComputeHash()
+
+	// Capturing rewrites the amounts, not the authorization
+	captured := *hold
+	captured.Amount = 5000
+	captured.DestinationAmount = 5000
+	captured.Status = StatusCompleted
+	assert.Equal(t, hold.Hash, captured.ComputeHash())
+}
+
+func TestTransaction_Settle(t *testing.T) {
+	hold := sealedTransfer(0, GenesisHash)
+	hold.AuthorizedAmount = hold.Amount
+	hold.Status = StatusPending
+	hold.Hash = hold.ComputeHash()
+	assert.Equal(t, "", hold.VerifyChainLink(0, GenesisHash))
+
+	captured := *hold
+	captured.Amount = 5000
+	captured.DestinationAmount = 5000
+	captured.Status = StatusCompleted
+	captured.Settle()
+	assert.Len(t, captured.SettlementHash, 64)
+	assert.Equal(t, "", captured.VerifyChainLink(0, GenesisHash))
+
+	// Reversals are chained on their own and leave the settlement alone
+	reversed := captured
+	reversed.Status = StatusPartiallyReversed
+	reversed.ReversedAmount = 1000
+	assert.Equal(t, "", reversed.VerifyChainLink(0, GenesisHash))
+
+	tests := []struct {
+		name   string
+		change func(*Transaction)
+	}{
+		{"captured amount", func(t *Transaction) { t.Amount++ }},
+		{"captured destination amount", func(t *Transaction) { t.DestinationAmount++ }},
+		{"status", func(t *Transaction) { t.Status = StatusVoided }},
+		{"back to pending", func(t *Transaction) { t.Status = StatusPending }},
+		{"settlement dropped", func(t *Transaction) { t.SettlementHash = "" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := captured
+			tt.change(&changed)
+			assert.Equal(t, ChainBreakSettlement, changed.VerifyChainLink(0, GenesisHash))
+		})
+	}
+
+	// A hold that ended without being settled
+	voided := *hold
+	voided.Status = StatusVoided
+	assert.Equal(t, ChainBreakSettlement, voided.VerifyChainLink(0, GenesisHash))
+	voided.Settle()
+	assert.Equal(t, "", voided.VerifyChainLink(0, GenesisHash))
+}
+
+func TestTransaction_MultiLegChain(t *testing.T) {
+	transaction := &Transaction{
+		ID:           "5f0c6d3e-8b1a-4c2e-9a57-0d5c8e2f6b11",
+		Currency:     "USD",
+		Sources:      []Leg{{AccountID: 7, Amount: 300}, {AccountID: 3, Amount: 200}},
+		Destinations: []Leg{{AccountID: 9, Amount: 500}},
+	}
+	assert.Equal(t, int64(3), transaction.ChainAccount())
+
+	// Legs loaded back in another order hash the same
+	reordered := *transaction
+	reordered.Sources = []Leg{{AccountID: 3, Amount: 200}, {AccountID: 7, Amount: 300}}
+	assert.Equal(t, transaction.ComputeHash(), reordered.ComputeHash())
+
+	reordered.Sources = []Leg{{AccountID: 3, Amount: 300}, {AccountID: 7, Amount: 200}}
+	assert.NotEqual(t, transaction.ComputeHash(), reordered.ComputeHash())
+}
+
+func TestTransaction_VerifyChainLink(t *testing.T) {
+	first := sealedTransfer(0, GenesisHash)
+	second := sealedTransfer(first.ChainSeq, first.Hash)
+
+	assert.Equal(t, "", first.VerifyChainLink(0, GenesisHash))
+	assert.Equal(t, "", second.VerifyChainLink(first.ChainSeq, first.Hash))
+
+	assert.Equal(t, ChainBreakMissing, second.VerifyChainLink(0, GenesisHash))
+	assert.Equal(t, ChainBreakLink, second.VerifyChainLink(first.ChainSeq, GenesisHash))
+
+	second.Amount++
+	assert.Equal(t, ChainBreakHash, second.VerifyChainLink(first.ChainSeq, first.Hash))
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DirectionDebit  = "debit"
+	DirectionCredit = "credit"
+
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// TransactionCursor is the keyset position of the last item on a page.
+// Pages are ordered newest first by (created_at, id).
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c TransactionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(token string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := TransactionCursor{ID: id}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// ListTransactionsRequest holds the raw query parameters of an account's
+// transaction history.
+type ListTransactionsRequest struct {
+	Direction string
+	MinAmount Decimal
+	MaxAmount Decimal
+	From      *time.Time
+	To        *time.Time
+	Cursor    string
+	Limit     int
+}
+
+func (r *ListTransactionsRequest) Validate() error {
+	if r.Direction != "" && r.Direction != DirectionDebit && r.Direction != DirectionCredit {
+		return ErrInvalidFilter
+	}
+	if r.Limit < 0 || r.Limit > MaxPageSize {
+		return ErrInvalidFilter
+	}
+	if r.From != nil && r.To != nil && !r.From.Before(*r.To) {
+		return ErrInvalidFilter
+	}
+	return nil
+}
+
+// Filter converts the request into a repository filter, reading amounts in the
+// account's currency.
+func (r *ListTransactionsRequest) Filter(accountID int64, currency string) (*TransactionFilter, error) {
+	filter := &TransactionFilter{
+		AccountID: accountID,
+		Direction: r.Direction,
+		From:      r.From,
+		To:        r.To,
+		Limit:     r.Limit,
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+
+	if r.MinAmount != "" {
+		amount, err := DecimalToMinorUnits(r.MinAmount, currency)
+		if err != nil {
+			return nil, err
+		}
+		filter.MinAmount = &amount
+	}
+	if r.MaxAmount != "" {
+		amount, err := DecimalToMinorUnits(r.MaxAmount, currency)
+		if err != nil {
+			return nil, err
+		}
+		filter.MaxAmount = &amount
+	}
+
+	if r.Cursor != "" {
+		cursor, err := DecodeTransactionCursor(r.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.Cursor = cursor
+	}
+
+	return filter, nil
+}
+
+// TransactionFilter selects a page of an account's transaction history.
+// Amount bounds are inclusive and apply to the amount that moved the
+// account; From is inclusive and To exclusive.
+type TransactionFilter struct {
+	AccountID int64
+	Direction string
+	MinAmount *int64
+	MaxAmount *int64
+	From      *time.Time
+	To        *time.Time
+	Cursor    *TransactionCursor
+	Limit     int
+}
+
+// AccountTransaction is a transaction seen from one of its accounts.
+type AccountTransaction struct {
+	Transaction
+	Direction     string
+	AccountAmount int64
+}
+
+type AccountTransactionResponse struct {
+	TransactionResponse
+	Direction     string  `json:"direction"`
+	AccountAmount Decimal `json:"account_amount"`
+}
+
+type TransactionPageResponse struct {
+	Transactions []AccountTransactionResponse `json:"transactions"`
+	NextCursor   string                       `json:"next_cursor,omitempty"`
+}
+
+// ToResponse formats the entry; accountCurrency is the currency of the
+// account whose history is being listed.
+func (t *AccountTransaction) ToResponse(accountCurrency string) AccountTransactionResponse {
+	return AccountTransactionResponse{
+		TransactionResponse: t.Transaction.ToResponse(),
+		Direction:           t.Direction,
+		AccountAmount:       MinorUnitsToDecimal(t.AccountAmount, accountCurrency),
+	}
+}
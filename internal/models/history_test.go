@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	cursor := TransactionCursor{
+		CreatedAt: time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC),
+		ID:        "0b9f6d3e-4c1a-4f5e-9a43-2f1d1c0e8b7a",
+	}
+
+	decoded, err := DecodeTransactionCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestDecodeTransactionCursor_Invalid(t *testing.T) {
+	for _, token := range []string{"not base64!", "bm8tc2VwYXJhdG9y", "Zm9vfGJhcg"} {
+		_, err := DecodeTransactionCursor(token)
+		assert.ErrorIs(t, err, ErrInvalidCursor, token)
+	}
+}
+
+func TestListTransactionsRequest_Filter(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	req := ListTransactionsRequest{
+		Direction: DirectionDebit,
+		MinAmount: "1.5",
+		MaxAmount: "100",
+		From:      &from,
+		To:        &to,
+	}
+	require.NoError(t, req.Validate())
+
+	filter, err := req.Filter(7, "KWD")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), filter.AccountID)
+	assert.Equal(t, int64(1500), *filter.MinAmount)
+	assert.Equal(t, int64(100000), *filter.MaxAmount)
+	assert.Equal(t, DefaultPageSize, filter.Limit)
+
+	_, err = (&ListTransactionsRequest{MinAmount: "1.5"}).Filter(7, "JPY")
+	assert.ErrorIs(t, err, ErrTooManyDecimals)
+
+	assert.ErrorIs(t, (&ListTransactionsRequest{Direction: "sideways"}).Validate(), ErrInvalidFilter)
+	assert.ErrorIs(t, (&ListTransactionsRequest{Limit: MaxPageSize + 1}).Validate(), ErrInvalidFilter)
+	assert.ErrorIs(t, (&ListTransactionsRequest{From: &to, To: &from}).Validate(), ErrInvalidFilter)
+}
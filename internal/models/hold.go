@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// CaptureRequest settles a pending transfer.
+type CaptureRequest struct {
+	// Amount is in the transfer's currency and may be less than was
+	// authorized; the rest of the hold is released. Left empty, the full
+	// authorized amount is captured.
+	Amount Decimal `json:"amount,omitempty"`
+}
+
+func (r *CaptureRequest) Validate() error {
+	if r.Amount == "" {
+		return nil
+	}
+	_, err := validateAmount(r.Amount)
+	return err
+}
+
+// HoldExpired reports whether the hold of a pending transfer has lapsed.
+func (t *Transaction) HoldExpired(now time.Time) bool {
+	return t.HoldExpiresAt != nil && !now.Before(*t.HoldExpiresAt)
+}
+
+// CaptureAmount resolves how much of a pending transfer a capture request
+// settles. A transfer is captured at most once.
+func (t *Transaction) CaptureAmount(req CaptureRequest, now time.Time) (int64, error) {
+	if t.Status != StatusPending {
+		return 0, ErrNotPending
+	}
+	if t.HoldExpired(now) {
+		return 0, ErrHoldExpired
+	}
+
+	if req.Amount == "" {
+		return t.AuthorizedAmount, nil
+	}
+
+	amount, err := positiveAmountIn(req.Amount, t.Currency)
+	if err != nil {
+		return 0, err
+	}
+	if amount > t.AuthorizedAmount {
+		return 0, ErrCaptureExceedsHold
+	}
+
+	return amount, nil
+}
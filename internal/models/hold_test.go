@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_CaptureAmount(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	pending := Transaction{
+		Amount:           10000,
+		AuthorizedAmount: 10000,
+		Currency:         "USD",
+		Status:           StatusPending,
+		HoldExpiresAt:    &later,
+	}
+
+	tests := []struct {
+		name        string
+		transaction Transaction
+		amount      Decimal
+		now         time.Time
+		expected    int64
+		expectError error
+	}{
+		{
+			name:        "Full capture by default",
+			transaction: pending,
+			now:         now,
+			expected:    10000,
+		},
+		{
+			name:        "Partial capture",
+			transaction: pending,
+			amount:      "60.25",
+			now:         now,
+			expected:    6025,
+		},
+		{
+			name:        "More than authorized",
+			transaction: pending,
+			amount:      "100.01",
+			now:         now,
+			expectError: ErrCaptureExceedsHold,
+		},
+		{
+			name:        "Hold expired",
+			transaction: pending,
+			now:         later,
+			expectError: ErrHoldExpired,
+		},
+		{
+			name:        "Already captured",
+			transaction: Transaction{Amount: 10000, AuthorizedAmount: 10000, Currency: "USD", Status: StatusCompleted},
+			now:         now,
+			expectError: ErrNotPending,
+		},
+		{
+			name:        "Zero amount",
+			transaction: pending,
+			amount:      "0",
+			now:         now,
+			expectError: ErrInvalidAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := tt.transaction.CaptureAmount(CaptureRequest{Amount: tt.amount}, tt.now)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+}
+
+func TestAccount_Available(t *testing.T) {
+	account := Account{ID: 1, Balance: 10000, HeldBalance: 2500, Currency: "USD"}
+
+	assert.Equal(t, int64(7500), account.Available())
+
+	response := account.ToResponse()
+	assert.Equal(t, Decimal("100.00"), response.Balance)
+	assert.Equal(t, Decimal("75.00"), response.AvailableBalance)
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Fingerprint identifies the payload of a transfer request, so that a reused
+// Idempotency-Key can be told apart from a retry. Formatting differences in
+// the JSON body, such as whitespace or key order, do not change it.
+func (r *CreateTransactionRequest) Fingerprint() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "transfer\n")
+	fmt.Fprintf(&b, "source=%d\n", r.SourceAccountID)
+	fmt.Fprintf(&b, "destination=%d\n", r.DestinationAccountID)
+	fmt.Fprintf(&b, "amount=%s\n", r.Amount)
+	fmt.Fprintf(&b, "quote=%s\n", r.QuoteID)
+	fmt.Fprintf(&b, "pending=%t\n", r.Pending)
+	for _, leg := range r.Sources {
+		fmt.Fprintf(&b, "source_leg=%d %s\n", leg.AccountID, leg.Amount)
+	}
+	for _, leg := range r.Destinations {
+		fmt.Fprintf(&b, "destination_leg=%d %s\n", leg.AccountID, leg.Amount)
+	}
+
+	return fingerprint(b.String())
+}
+
+// Fingerprint identifies a request to reverse transactionID.
+func (r *CreateReversalRequest) Fingerprint(transactionID string) string {
+	return fingerprint(fmt.Sprintf("reversal\ntransaction=%s\namount=%s\n", transactionID, r.Amount))
+}
+
+// Fingerprint identifies a request to open an account.
+func (r *CreateAccountRequest) Fingerprint() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "account\n")
+	fmt.Fprintf(&b, "account=%d\n", r.AccountID)
+	fmt.Fprintf(&b, "currency=%s\n", r.Currency)
+	fmt.Fprintf(&b, "initial_balance=%s\n", r.InitialBalance)
+	fmt.Fprintf(&b, "overdraft_limit=%s\n", r.OverdraftLimit)
+	fmt.Fprintf(&b, "unlimited_overdraft=%t\n", r.UnlimitedOverdraft)
+	fmt.Fprintf(&b, "hot=%t\n", r.Hot)
+
+	return fingerprint(b.String())
+}
+
+func fingerprint(canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// maxIdempotencyKeyLength is the longest key or client ID that is stored.
+const maxIdempotencyKeyLength = 255
+
+// RequestKey is the Idempotency-Key of a request and the API client that sent
+// it. Keys are scoped to their client, so two clients can use the same key
+// without seeing each other's results. An empty Key makes the request
+// non-idempotent.
+type RequestKey struct {
+	ClientID string
+	Key      string
+}
+
+func (k RequestKey) Validate() error {
+	if len(k.ClientID) > maxIdempotencyKeyLength || len(k.Key) > maxIdempotencyKeyLength {
+		return ErrInvalidIdempotencyKey
+	}
+	return nil
+}
+
+// IdempotencyKey is a key together with the outcome of the request that
+// first used it: the transaction or account it created, or the error it
+// failed with.
+type IdempotencyKey struct {
+	ClientID           string    `db:"client_id"`
+	Key                string    `db:"key"`
+	RequestFingerprint string    `db:"request_fingerprint"`
+	TransactionID      *string   `db:"transaction_id"`
+	AccountID          *int64    `db:"account_id"`
+	ErrorCode          string    `db:"error_code"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+// MatchesFingerprint reports whether the key was used for a request with the
+// given fingerprint. Keys recorded before fingerprints were stored match any
+// request.
+func (k *IdempotencyKey) MatchesFingerprint(fingerprint string) bool {
+	return k.RequestFingerprint == "" || k.RequestFingerprint == fingerprint
+}
+
+// Outcome returns the stored error of a failed request, or nil if the
+// request succeeded.
+func (k *IdempotencyKey) Outcome() error {
+	if k.ErrorCode == "" {
+		return nil
+	}
+	return OutcomeError(k.ErrorCode)
+}
+
+// outcomeErrors are the failures stored with an idempotency key and replayed
+// to later requests with the same key. They follow from the ledger's state
+// when the request ran, so a retry could otherwise succeed where the first
+// attempt failed. The codes are persisted and must not change.
+var outcomeErrors = map[string]error{
+	"account_not_found":      ErrAccountNotFound,
+	"account_exists":         ErrAccountExists,
+	"account_frozen":         ErrAccountFrozen,
+	"account_closed":         ErrAccountClosed,
+	"insufficient_funds":     ErrInsufficientFunds,
+	"invalid_amount":         ErrInvalidAmount,
+	"invalid_amount_format":  ErrInvalidAmountFormat,
+	"too_many_decimals":      ErrTooManyDecimals,
+	"unsupported_currency":   ErrUnsupportedCurrency,
+	"rate_unavailable":       ErrRateUnavailable,
+	"quote_not_found":        ErrQuoteNotFound,
+	"quote_expired":          ErrQuoteExpired,
+	"quote_mismatch":         ErrQuoteMismatch,
+	"transaction_not_found":  ErrTransactionNotFound,
+	"not_reversible":         ErrNotReversible,
+	"reversal_exceeds_total": ErrReversalExceedsTotal,
+	"partial_reversal":       ErrPartialReversal,
+}
+
+const currencyMismatchCode = "currency_mismatch"
+
+// OutcomeCode returns the code under which err is stored with an idempotency
+// key, or false if err is not a replayable outcome. Internal errors are not:
+// the key is released so the request can be retried.
+func OutcomeCode(err error) (string, bool) {
+	var mismatch *CurrencyMismatchError
+	if errors.As(err, &mismatch) {
+		return fmt.Sprintf("%s:%s:%s", currencyMismatchCode, mismatch.SourceCurrency, mismatch.DestinationCurrency), true
+	}
+
+	for code, outcome := range outcomeErrors {
+		if errors.Is(err, outcome) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// OutcomeError returns the error stored under code.
+func OutcomeError(code string) error {
+	if rest, ok := strings.CutPrefix(code, currencyMismatchCode+":"); ok {
+		source, destination, _ := strings.Cut(rest, ":")
+		return &CurrencyMismatchError{SourceCurrency: source, DestinationCurrency: destination}
+	}
+
+	if outcome, ok := outcomeErrors[code]; ok {
+		return outcome
+	}
+	return fmt.Errorf("unknown idempotency outcome %q", code)
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransactionRequest_Fingerprint(t *testing.T) {
+	decode := func(body string) CreateTransactionRequest {
+		var req CreateTransactionRequest
+		require.NoError(t, json.Unmarshal([]byte(body), &req))
+		return req
+	}
+
+	original := decode(`{"source_account_id": 1, "destination_account_id": 2, "amount": "10.00"}`)
+
+	same := decode(`{
+		"amount": "10.00",
+		"destination_account_id": 2,
+		"source_account_id": 1
+	}`)
+	assert.Equal(t, original.Fingerprint(), same.Fingerprint())
+
+	for _, body := range []string{
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "10.01"}`,
+		`{"source_account_id": 1, "destination_account_id": 3, "amount": "10.00"}`,
+		`{"source_account_id": 2, "destination_account_id": 1, "amount": "10.00"}`,
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "10.00", "pending": true}`,
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "10.00", "quote_id": "q"}`,
+		`{"sources": [{"account_id": 1, "amount": "10.00"}], "destinations": [{"account_id": 2, "amount": "10.00"}]}`,
+	} {
+		different := decode(body)
+		assert.NotEqual(t, original.Fingerprint(), different.Fingerprint(), body)
+	}
+}
+
+func TestCreateReversalRequest_Fingerprint(t *testing.T) {
+	full := CreateReversalRequest{}
+	partial := CreateReversalRequest{Amount: "5.00"}
+
+	assert.Equal(t, partial.Fingerprint("a"), partial.Fingerprint("a"))
+	assert.NotEqual(t, partial.Fingerprint("a"), partial.Fingerprint("b"))
+	assert.NotEqual(t, full.Fingerprint("a"), partial.Fingerprint("a"))
+}
+
+func TestCreateAccountRequest_Fingerprint(t *testing.T) {
+	original := CreateAccountRequest{AccountID: 1, InitialBalance: "10.00"}
+
+	assert.Equal(t, original.Fingerprint(), (&CreateAccountRequest{AccountID: 1, InitialBalance: "10.00"}).Fingerprint())
+
+	for _, different := range []CreateAccountRequest{
+		{AccountID: 2, InitialBalance: "10.00"},
+		{AccountID: 1, InitialBalance: "10.01"},
+		{AccountID: 1, InitialBalance: "10.00", Currency: "EUR"},
+		{AccountID: 1, InitialBalance: "10.00", OverdraftLimitRequest: OverdraftLimitRequest{OverdraftLimit: "5"}},
+		{AccountID: 1, InitialBalance: "10.00", OverdraftLimitRequest: OverdraftLimitRequest{UnlimitedOverdraft: true}},
+		{AccountID: 1, InitialBalance: "10.00", Hot: true},
+	} {
+		assert.NotEqual(t, original.Fingerprint(), different.Fingerprint(), different)
+	}
+
+	// Opening an account is never mistaken for a transfer
+	transfer := CreateTransactionRequest{SourceAccountID: 1}
+	assert.NotEqual(t, transfer.Fingerprint(), (&CreateAccountRequest{}).Fingerprint())
+}
+
+func TestRequestKey_Validate(t *testing.T) {
+	assert.NoError(t, RequestKey{}.Validate())
+	assert.NoError(t, RequestKey{ClientID: "acme", Key: strings.Repeat("k", 255)}.Validate())
+	assert.ErrorIs(t, RequestKey{Key: strings.Repeat("k", 256)}.Validate(), ErrInvalidIdempotencyKey)
+	assert.ErrorIs(t, RequestKey{ClientID: strings.Repeat("c", 256), Key: "k"}.Validate(), ErrInvalidIdempotencyKey)
+}
+
+func TestIdempotencyKey_MatchesFingerprint(t *testing.T) {
+	req := CreateReversalRequest{Amount: "5.00"}
+
+	assert.True(t, (&IdempotencyKey{}).MatchesFingerprint(req.Fingerprint("a")))
+	assert.True(t, (&IdempotencyKey{RequestFingerprint: req.Fingerprint("a")}).MatchesFingerprint(req.Fingerprint("a")))
+	assert.False(t, (&IdempotencyKey{RequestFingerprint: req.Fingerprint("a")}).MatchesFingerprint(req.Fingerprint("b")))
+}
+
+func TestOutcomeCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		replayed error
+	}{
+		{ErrInsufficientFunds, ErrInsufficientFunds},
+		{ErrAccountExists, ErrAccountExists},
+		{ErrAccountFrozen, ErrAccountFrozen},
+		{ErrQuoteExpired, ErrQuoteExpired},
+		{ErrReversalExceedsTotal, ErrReversalExceedsTotal},
+		{fmt.Errorf("failed to post journal entry: %w", ErrInsufficientFunds), ErrInsufficientFunds},
+		{
+			&CurrencyMismatchError{SourceCurrency: "USD", DestinationCurrency: "JPY"},
+			&CurrencyMismatchError{SourceCurrency: "USD", DestinationCurrency: "JPY"},
+		},
+	}
+
+	for _, tt := range tests {
+		code, ok := OutcomeCode(tt.err)
+		require.True(t, ok, tt.err)
+		assert.Equal(t, tt.replayed, (&IdempotencyKey{ErrorCode: code}).Outcome(), tt.err)
+	}
+
+	// Internal failures release the key instead
+	_, ok := OutcomeCode(errors.New("connection reset"))
+	assert.False(t, ok)
+	_, ok = OutcomeCode(context.Canceled)
+	assert.False(t, ok)
+
+	assert.NoError(t, (&IdempotencyKey{}).Outcome())
+	assert.Error(t, (&IdempotencyKey{ErrorCode: "no_such_code"}).Outcome())
+}
@@ -0,0 +1,145 @@
+package models
+
+import "time"
+
+const (
+	EntryKindTransfer       = "TRANSFER"
+	EntryKindOpeningBalance = "OPENING_BALANCE"
+)
+
+// System accounts are ledger-internal counterparties that keep every journal
+// entry balanced when money enters the ledger or changes currency.
+const (
+	SystemAccountOpeningBalances = "equity:opening_balances"
+	SystemAccountFXConversion    = "fx:conversion"
+)
+
+// JournalEntry is one balanced double-entry record. For every currency the
+// amounts of its postings sum to zero.
+type JournalEntry struct {
+	ID            string    `db:"id"`
+	TransactionID *string   `db:"transaction_id"`
+	Kind          string    `db:"kind"`
+	CreatedAt     time.Time `db:"created_at"`
+	Postings      []Posting
+}
+
+// Posting moves Amount minor units into (positive) or out of (negative) a
+// single account. Exactly one of AccountID and SystemAccount is set.
+//
+// A Deferred posting is a credit to a hot account that was not locked for
+// update: it is recorded as pending rather than added to the account's
+// balance.
+type Posting struct {
+	ID            int64     `db:"id"`
+	EntryID       string    `db:"entry_id"`
+	AccountID     *int64    `db:"account_id"`
+	SystemAccount *string   `db:"system_account"`
+	Amount        int64     `db:"amount"`
+	Currency      string    `db:"currency"`
+	CreatedAt     time.Time `db:"created_at"`
+	Deferred      bool      `db:"-"`
+}
+
+func AccountPosting(accountID int64, amount int64, currency string) Posting {
+	return Posting{AccountID: &accountID, Amount: amount, Currency: currency}
+}
+
+func SystemPosting(account string, amount int64, currency string) Posting {
+	return Posting{SystemAccount: &account, Amount: amount, Currency: currency}
+}
+
+// Validate enforces the double-entry invariant: at least two non-zero
+// postings, each against exactly one account, summing to zero per currency.
+func (e *JournalEntry) Validate() error {
+	if len(e.Postings) < 2 {
+		return ErrUnbalancedEntry
+	}
+
+	sums := make(map[string]int64)
+	for _, p := range e.Postings {
+		if p.Amount == 0 || (p.AccountID == nil) == (p.SystemAccount == nil) {
+			return ErrUnbalancedEntry
+		}
+		if p.Deferred && (p.AccountID == nil || p.Amount < 0) {
+			return ErrUnbalancedEntry
+		}
+		sums[p.Currency] += p.Amount
+	}
+
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedEntry
+		}
+	}
+
+	return nil
+}
+
+// DeferCredits marks the entry's credits to the given accounts as deferred.
+func (e *JournalEntry) DeferCredits(accountIDs map[int64]bool) {
+	for i := range e.Postings {
+		p := &e.Postings[i]
+		if p.AccountID != nil && p.Amount > 0 && accountIDs[*p.AccountID] {
+			p.Deferred = true
+		}
+	}
+}
+
+// NewTransferEntry builds the journal entry for a completed transfer. A
+// cross-currency transfer goes through the FX conversion account so that
+// each currency balances on its own.
+func NewTransferEntry(entryID string, t *Transaction) *JournalEntry {
+	if t.IsMultiLeg() {
+		return newMultiLegEntry(entryID, t)
+	}
+
+	entry := &JournalEntry{
+		ID:            entryID,
+		TransactionID: &t.ID,
+		Kind:          EntryKindTransfer,
+		Postings: []Posting{
+			AccountPosting(t.SourceAccountID, -t.Amount, t.Currency),
+			AccountPosting(t.DestinationAccountID, t.DestinationAmount, t.DestinationCurrency),
+		},
+	}
+
+	if t.Currency != t.DestinationCurrency {
+		entry.Postings = append(entry.Postings,
+			SystemPosting(SystemAccountFXConversion, t.Amount, t.Currency),
+			SystemPosting(SystemAccountFXConversion, -t.DestinationAmount, t.DestinationCurrency),
+		)
+	}
+
+	return entry
+}
+
+func newMultiLegEntry(entryID string, t *Transaction) *JournalEntry {
+	entry := &JournalEntry{
+		ID:            entryID,
+		TransactionID: &t.ID,
+		Kind:          EntryKindTransfer,
+	}
+
+	for _, leg := range t.Sources {
+		entry.Postings = append(entry.Postings, AccountPosting(leg.AccountID, -leg.Amount, t.Currency))
+	}
+	for _, leg := range t.Destinations {
+		entry.Postings = append(entry.Postings, AccountPosting(leg.AccountID, leg.Amount, t.Currency))
+	}
+
+	return entry
+}
+
+// NewOpeningBalanceEntry funds a new account from the opening balances
+// equity account.
+func NewOpeningBalanceEntry(entryID string, account *Account, amount int64) *JournalEntry {
+	return &JournalEntry{
+		ID:   entryID,
+		Kind: EntryKindOpeningBalance,
+		Postings: []Posting{
+			SystemPosting(SystemAccountOpeningBalances, -amount, account.Currency),
+			AccountPosting(account.ID, amount, account.Currency),
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		postings    []Posting
+		expectError error
+	}{
+		{
+			name: "Balanced transfer",
+			postings: []Posting{
+				AccountPosting(1, -100, "USD"),
+				AccountPosting(2, 100, "USD"),
+			},
+		},
+		{
+			name: "Balanced per currency",
+			postings: []Posting{
+				AccountPosting(1, -100, "USD"),
+				SystemPosting(SystemAccountFXConversion, 100, "USD"),
+				SystemPosting(SystemAccountFXConversion, -15050, "JPY"),
+				AccountPosting(2, 15050, "JPY"),
+			},
+		},
+		{
+			name: "Unbalanced",
+			postings: []Posting{
+				AccountPosting(1, -100, "USD"),
+				AccountPosting(2, 99, "USD"),
+			},
+			expectError: ErrUnbalancedEntry,
+		},
+		{
+			name: "Balanced total but not per currency",
+			postings: []Posting{
+				AccountPosting(1, -100, "USD"),
+				AccountPosting(2, 100, "JPY"),
+			},
+			expectError: ErrUnbalancedEntry,
+		},
+		{
+			name: "Single posting",
+			postings: []Posting{
+				AccountPosting(1, 0, "USD"),
+			},
+			expectError: ErrUnbalancedEntry,
+		},
+		{
+			name: "Zero posting",
+			postings: []Posting{
+				AccountPosting(1, 0, "USD"),
+				AccountPosting(2, 0, "USD"),
+			},
+			expectError: ErrUnbalancedEntry,
+		},
+		{
+			name: "Posting without account",
+			postings: []Posting{
+				AccountPosting(1, -100, "USD"),
+				{Amount: 100, Currency: "USD"},
+			},
+			expectError: ErrUnbalancedEntry,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &JournalEntry{ID: "entry", Kind: EntryKindTransfer, Postings: tt.postings}
+			err := entry.Validate()
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewTransferEntry(t *testing.T) {
+	sameCurrency := &Transaction{
+		ID:                   "txn-1",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               1000,
+		Currency:             "USD",
+		DestinationAmount:    1000,
+		DestinationCurrency:  "USD",
+	}
+
+	entry := NewTransferEntry("entry-1", sameCurrency)
+	assert.NoError(t, entry.Validate())
+	assert.Len(t, entry.Postings, 2)
+
+	crossCurrency := &Transaction{
+		ID:                   "txn-2",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               1001,
+		Currency:             "USD",
+		DestinationAmount:    1507,
+		DestinationCurrency:  "JPY",
+	}
+
+	entry = NewTransferEntry("entry-2", crossCurrency)
+	assert.NoError(t, entry.Validate())
+	assert.Len(t, entry.Postings, 4)
+}
+
+func TestNewOpeningBalanceEntry(t *testing.T) {
+	account := &Account{ID: 1, Currency: "KWD"}
+
+	entry := NewOpeningBalanceEntry("entry-1", account, 1005)
+	assert.NoError(t, entry.Validate())
+	assert.Equal(t, EntryKindOpeningBalance, entry.Kind)
+	assert.Nil(t, entry.TransactionID)
+}
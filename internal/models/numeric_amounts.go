@@ -0,0 +1,213 @@
+package models
+
+import "encoding/json"
+
+// NumericDecimal is a Decimal emitted as a bare JSON number, written from
+// its exact decimal text, for clients that have not moved to decimal strings
+// yet.
+type NumericDecimal string
+
+func (d NumericDecimal) MarshalJSON() ([]byte, error) {
+	if d == "" {
+		return json.Marshal("")
+	}
+	return []byte(d), nil
+}
+
+// NumericAmounter is implemented by the responses that hold amounts.
+// NumericAmounts returns the response with its amounts as NumericDecimals.
+//
+// The numeric variants below embed the response they stand for and repeat
+// only its amount fields, under the same JSON names: encoding/json writes the
+// shallower field of the two, so every other field is written as it is in
+// the response.
+type NumericAmounter interface {
+	NumericAmounts() any
+}
+
+type numericAccountResponse struct {
+	AccountResponse
+	Balance          NumericDecimal  `json:"balance"`
+	AvailableBalance NumericDecimal  `json:"available_balance"`
+	OverdraftLimit   *NumericDecimal `json:"overdraft_limit"`
+}
+
+func (a AccountResponse) NumericAmounts() any {
+	return numericAccountResponse{
+		AccountResponse:  a,
+		Balance:          NumericDecimal(a.Balance),
+		AvailableBalance: NumericDecimal(a.AvailableBalance),
+		OverdraftLimit:   (*NumericDecimal)(a.OverdraftLimit),
+	}
+}
+
+type numericBalanceResponse struct {
+	BalanceResponse
+	Balance NumericDecimal `json:"balance"`
+}
+
+func (b BalanceResponse) NumericAmounts() any {
+	return numericBalanceResponse{BalanceResponse: b, Balance: NumericDecimal(b.Balance)}
+}
+
+type numericFXQuoteResponse struct {
+	FXQuoteResponse
+	Rate NumericDecimal `json:"rate"`
+}
+
+func (q FXQuoteResponse) NumericAmounts() any {
+	return numericFXQuoteResponse{FXQuoteResponse: q, Rate: NumericDecimal(q.Rate)}
+}
+
+type numericLegResponse struct {
+	LegResponse
+	Amount NumericDecimal `json:"amount"`
+}
+
+type numericTransactionResponse struct {
+	TransactionResponse
+	Sources           []numericLegResponse `json:"sources,omitempty"`
+	Destinations      []numericLegResponse `json:"destinations,omitempty"`
+	Amount            NumericDecimal       `json:"amount"`
+	DestinationAmount NumericDecimal       `json:"destination_amount,omitempty"`
+	FXRate            NumericDecimal       `json:"fx_rate,omitempty"`
+	ReversedAmount    NumericDecimal       `json:"reversed_amount,omitempty"`
+	AuthorizedAmount  NumericDecimal       `json:"authorized_amount,omitempty"`
+}
+
+func (t TransactionResponse) NumericAmounts() any {
+	return t.numeric()
+}
+
+func (t TransactionResponse) numeric() numericTransactionResponse {
+	numericLeg := func(leg LegResponse) numericLegResponse {
+		return numericLegResponse{LegResponse: leg, Amount: NumericDecimal(leg.Amount)}
+	}
+	return numericTransactionResponse{
+		TransactionResponse: t,
+		Sources:             numericSlice(t.Sources, numericLeg),
+		Destinations:        numericSlice(t.Destinations, numericLeg),
+		Amount:              NumericDecimal(t.Amount),
+		DestinationAmount:   NumericDecimal(t.DestinationAmount),
+		FXRate:              NumericDecimal(t.FXRate),
+		ReversedAmount:      NumericDecimal(t.ReversedAmount),
+		AuthorizedAmount:    NumericDecimal(t.AuthorizedAmount),
+	}
+}
+
+// numericAccountTransactionResponse embeds the transaction's numeric variant
+// rather than the AccountTransactionResponse, whose amounts would be one
+// level too deep to be replaced.
+type numericAccountTransactionResponse struct {
+	numericTransactionResponse
+	Direction     string         `json:"direction"`
+	AccountAmount NumericDecimal `json:"account_amount"`
+}
+
+func (t AccountTransactionResponse) NumericAmounts() any {
+	return t.numeric()
+}
+
+func (t AccountTransactionResponse) numeric() numericAccountTransactionResponse {
+	return numericAccountTransactionResponse{
+		numericTransactionResponse: t.TransactionResponse.numeric(),
+		Direction:                  t.Direction,
+		AccountAmount:              NumericDecimal(t.AccountAmount),
+	}
+}
+
+type numericTransactionPageResponse struct {
+	TransactionPageResponse
+	Transactions []numericAccountTransactionResponse `json:"transactions"`
+}
+
+func (p TransactionPageResponse) NumericAmounts() any {
+	return numericTransactionPageResponse{
+		TransactionPageResponse: p,
+		Transactions:            numericSlice(p.Transactions, AccountTransactionResponse.numeric),
+	}
+}
+
+type numericBatchTransferResult struct {
+	BatchTransferResult
+	Transaction *numericTransactionResponse `json:"transaction,omitempty"`
+}
+
+type numericBatchTransferResponse struct {
+	BatchTransferResponse
+	Results []numericBatchTransferResult `json:"results"`
+}
+
+func (b BatchTransferResponse) NumericAmounts() any {
+	return numericBatchTransferResponse{
+		BatchTransferResponse: b,
+		Results: numericSlice(b.Results, func(result BatchTransferResult) numericBatchTransferResult {
+			numeric := numericBatchTransferResult{BatchTransferResult: result}
+			if result.Transaction != nil {
+				transaction := result.Transaction.numeric()
+				numeric.Transaction = &transaction
+			}
+			return numeric
+		}),
+	}
+}
+
+type numericAccountDriftResponse struct {
+	AccountDriftResponse
+	Balance             NumericDecimal `json:"balance"`
+	ExpectedBalance     NumericDecimal `json:"expected_balance"`
+	HeldBalance         NumericDecimal `json:"held_balance"`
+	ExpectedHeldBalance NumericDecimal `json:"expected_held_balance"`
+}
+
+type numericUnbalancedEntryResponse struct {
+	UnbalancedEntryResponse
+	Sum NumericDecimal `json:"sum"`
+}
+
+type numericReconciliationResponse struct {
+	ReconciliationResponse
+	AccountDrift      []numericAccountDriftResponse    `json:"account_drift"`
+	UnbalancedEntries []numericUnbalancedEntryResponse `json:"unbalanced_entries"`
+}
+
+func (r ReconciliationResponse) NumericAmounts() any {
+	return numericReconciliationResponse{
+		ReconciliationResponse: r,
+		AccountDrift: numericSlice(r.AccountDrift, func(drift AccountDriftResponse) numericAccountDriftResponse {
+			return numericAccountDriftResponse{
+				AccountDriftResponse: drift,
+				Balance:              NumericDecimal(drift.Balance),
+				ExpectedBalance:      NumericDecimal(drift.ExpectedBalance),
+				HeldBalance:          NumericDecimal(drift.HeldBalance),
+				ExpectedHeldBalance:  NumericDecimal(drift.ExpectedHeldBalance),
+			}
+		}),
+		UnbalancedEntries: numericSlice(r.UnbalancedEntries, func(entry UnbalancedEntryResponse) numericUnbalancedEntryResponse {
+			return numericUnbalancedEntryResponse{UnbalancedEntryResponse: entry, Sum: NumericDecimal(entry.Sum)}
+		}),
+	}
+}
+
+type numericStatementLine struct {
+	statementLine
+	Amount  NumericDecimal `json:"amount,omitempty"`
+	Balance NumericDecimal `json:"balance"`
+}
+
+func (l statementLine) NumericAmounts() any {
+	return numericStatementLine{statementLine: l, Amount: NumericDecimal(l.Amount), Balance: NumericDecimal(l.Balance)}
+}
+
+// numericSlice maps items to their numeric variants, keeping a nil slice nil
+// so it is still written as null or left out.
+func numericSlice[T, N any](items []T, numeric func(T) N) []N {
+	if items == nil {
+		return nil
+	}
+	numerics := make([]N, len(items))
+	for i, item := range items {
+		numerics[i] = numeric(item)
+	}
+	return numerics
+}
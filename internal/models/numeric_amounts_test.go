@@ -0,0 +1,109 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeNumbers decodes data keeping its numbers as their literal text.
+func decodeNumbers(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var decoded map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&decoded))
+	return decoded
+}
+
+func TestNumericAmounts_Account(t *testing.T) {
+	limit := Decimal("0.00")
+	account := &AccountResponse{
+		AccountID:        1,
+		Currency:         "USD",
+		Status:           AccountStatusActive,
+		Balance:          "100.50",
+		AvailableBalance: "90.50",
+		OverdraftLimit:   &limit,
+	}
+
+	data, err := json.Marshal(account.NumericAmounts())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"account_id":        json.Number("1"),
+		"currency":          "USD",
+		"status":            AccountStatusActive,
+		"balance":           json.Number("100.50"),
+		"available_balance": json.Number("90.50"),
+		"overdraft_limit":   json.Number("0.00"),
+	}, decodeNumbers(t, data))
+
+	// No limit is still null
+	account.OverdraftLimit = nil
+	data, err = json.Marshal(account.NumericAmounts())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"overdraft_limit":null`)
+
+	// The response itself still marshals as strings
+	data, err = json.Marshal(account)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"balance":"100.50"`)
+}
+
+func TestNumericAmounts_TransactionPage(t *testing.T) {
+	page := TransactionPageResponse{
+		Transactions: []AccountTransactionResponse{{
+			TransactionResponse: TransactionResponse{
+				TransactionID: "5f0c6d3e-8b1a-4c2e-9a57-0d5c8e2f6b11",
+				Sources:       []LegResponse{{AccountID: 1, Amount: "7.50"}},
+				Destinations:  []LegResponse{{AccountID: 2, Amount: "7.50"}},
+				Amount:        "7.50",
+				Currency:      "USD",
+				Status:        StatusCompleted,
+				CreatedAt:     time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC),
+			},
+			Direction:     "debit",
+			AccountAmount: "-7.50",
+		}},
+	}
+
+	data, err := json.Marshal(page.NumericAmounts())
+	require.NoError(t, err)
+
+	transactions := decodeNumbers(t, data)["transactions"].([]any)
+	require.Len(t, transactions, 1)
+	transaction := transactions[0].(map[string]any)
+	assert.Equal(t, json.Number("7.50"), transaction["amount"])
+	assert.Equal(t, json.Number("-7.50"), transaction["account_amount"])
+	assert.Equal(t, "debit", transaction["direction"])
+	assert.Equal(t, "2024-03-15T12:00:00Z", transaction["created_at"])
+	assert.Equal(t, json.Number("7.50"), transaction["sources"].([]any)[0].(map[string]any)["amount"])
+	// Empty amounts are still left out
+	assert.NotContains(t, transaction, "destination_amount")
+	assert.NotContains(t, transaction, "fx_rate")
+}
+
+func TestNumericAmounts_Batch(t *testing.T) {
+	batch := BatchTransferResponse{
+		Mode:      BatchModeBestEffort,
+		Succeeded: 1,
+		Failed:    1,
+		Results: []BatchTransferResult{
+			{Index: 0, Status: BatchItemCompleted, Transaction: &TransactionResponse{Amount: "1.25", Currency: "USD"}},
+			{Index: 1, Status: BatchItemFailed, Code: 422, Error: "Insufficient funds"},
+		},
+	}
+
+	data, err := json.Marshal(batch.NumericAmounts())
+	require.NoError(t, err)
+
+	results := decodeNumbers(t, data)["results"].([]any)
+	require.Len(t, results, 2)
+	assert.Equal(t, json.Number("1.25"), results[0].(map[string]any)["transaction"].(map[string]any)["amount"])
+	assert.NotContains(t, results[1], "transaction")
+	assert.Equal(t, "Insufficient funds", results[1].(map[string]any)["error"])
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FXQuote locks an exchange rate between two currencies until ExpiresAt.
+// Rate is the amount of DestinationCurrency bought by one unit of
+// SourceCurrency.
+type FXQuote struct {
+	ID                  string    `db:"id"`
+	SourceCurrency      string    `db:"source_currency"`
+	DestinationCurrency string    `db:"destination_currency"`
+	Rate                Decimal   `db:"rate"`
+	ExpiresAt           time.Time `db:"expires_at"`
+	CreatedAt           time.Time `db:"created_at"`
+}
+
+type FXQuoteResponse struct {
+	QuoteID             string    `json:"quote_id"`
+	SourceCurrency      string    `json:"source_currency"`
+	DestinationCurrency string    `json:"destination_currency"`
+	Rate                Decimal   `json:"rate"`
+	ExpiresAt           time.Time `json:"expires_at"`
+}
+
+func (q *FXQuote) ToResponse() FXQuoteResponse {
+	return FXQuoteResponse{
+		QuoteID:             q.ID,
+		SourceCurrency:      q.SourceCurrency,
+		DestinationCurrency: q.DestinationCurrency,
+		Rate:                q.Rate,
+		ExpiresAt:           q.ExpiresAt,
+	}
+}
+
+// Expired reports whether the quote can no longer be used at time now.
+func (q *FXQuote) Expired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
+
+type CreateFXQuoteRequest struct {
+	SourceCurrency      string `json:"source_currency"`
+	DestinationCurrency string `json:"destination_currency"`
+}
+
+func (r *CreateFXQuoteRequest) Validate() error {
+	source, err := NormalizeCurrency(r.SourceCurrency)
+	if err != nil {
+		return err
+	}
+	destination, err := NormalizeCurrency(r.DestinationCurrency)
+	if err != nil {
+		return err
+	}
+	if source == destination {
+		return ErrSameCurrency
+	}
+	return nil
+}
+
+func (q *FXQuote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.ToResponse())
+}
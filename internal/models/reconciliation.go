@@ -0,0 +1,117 @@
+package models
+
+import "time"
+
+// AccountDrift is an account whose cached balances disagree with the ledger:
+// the balance with the sum of its postings, or the held balance with the sum
+// of its pending holds.
+type AccountDrift struct {
+	AccountID           int64
+	Currency            string
+	Balance             int64
+	ExpectedBalance     int64
+	HeldBalance         int64
+	ExpectedHeldBalance int64
+}
+
+// UnbalancedEntry is a journal entry whose postings in Currency do not sum to
+// zero.
+type UnbalancedEntry struct {
+	EntryID  string
+	Currency string
+	Sum      int64
+}
+
+// TransactionMismatch is a transaction whose journal entries disagree with
+// its status: settled transactions have exactly one entry, holds that were
+// never captured have none.
+type TransactionMismatch struct {
+	TransactionID string
+	Status        string
+	Entries       int
+}
+
+// ReconciliationReport is the result of checking the whole ledger against
+// its journal at one point in time.
+type ReconciliationReport struct {
+	CheckedAt       time.Time
+	AccountsChecked int
+	Accounts        []AccountDrift
+	Entries         []UnbalancedEntry
+	Transactions    []TransactionMismatch
+}
+
+// Balanced reports whether no discrepancy was found.
+func (r *ReconciliationReport) Balanced() bool {
+	return len(r.Accounts) == 0 && len(r.Entries) == 0 && len(r.Transactions) == 0
+}
+
+type AccountDriftResponse struct {
+	AccountID           int64   `json:"account_id"`
+	Currency            string  `json:"currency"`
+	Balance             Decimal `json:"balance"`
+	ExpectedBalance     Decimal `json:"expected_balance"`
+	HeldBalance         Decimal `json:"held_balance"`
+	ExpectedHeldBalance Decimal `json:"expected_held_balance"`
+}
+
+type UnbalancedEntryResponse struct {
+	EntryID  string  `json:"entry_id"`
+	Currency string  `json:"currency"`
+	Sum      Decimal `json:"sum"`
+}
+
+type TransactionMismatchResponse struct {
+	TransactionID string `json:"transaction_id"`
+	Status        string `json:"status"`
+	Entries       int    `json:"journal_entries"`
+}
+
+type ReconciliationResponse struct {
+	Balanced          bool                          `json:"balanced"`
+	CheckedAt         time.Time                     `json:"checked_at"`
+	AccountsChecked   int                           `json:"accounts_checked"`
+	AccountDrift      []AccountDriftResponse        `json:"account_drift"`
+	UnbalancedEntries []UnbalancedEntryResponse     `json:"unbalanced_entries"`
+	Transactions      []TransactionMismatchResponse `json:"transaction_mismatches"`
+}
+
+func (r *ReconciliationReport) ToResponse() ReconciliationResponse {
+	response := ReconciliationResponse{
+		Balanced:          r.Balanced(),
+		CheckedAt:         r.CheckedAt,
+		AccountsChecked:   r.AccountsChecked,
+		AccountDrift:      make([]AccountDriftResponse, 0, len(r.Accounts)),
+		UnbalancedEntries: make([]UnbalancedEntryResponse, 0, len(r.Entries)),
+		Transactions:      make([]TransactionMismatchResponse, 0, len(r.Transactions)),
+	}
+
+	for _, drift := range r.Accounts {
+		response.AccountDrift = append(response.AccountDrift, AccountDriftResponse{
+			AccountID:           drift.AccountID,
+			Currency:            drift.Currency,
+			Balance:             MinorUnitsToDecimal(drift.Balance, drift.Currency),
+			ExpectedBalance:     MinorUnitsToDecimal(drift.ExpectedBalance, drift.Currency),
+			HeldBalance:         MinorUnitsToDecimal(drift.HeldBalance, drift.Currency),
+			ExpectedHeldBalance: MinorUnitsToDecimal(drift.ExpectedHeldBalance, drift.Currency),
+		})
+	}
+
+	for _, entry := range r.Entries {
+		response.UnbalancedEntries = append(response.UnbalancedEntries, UnbalancedEntryResponse{
+			EntryID:  entry.EntryID,
+			Currency: entry.Currency,
+			Sum:      MinorUnitsToDecimal(entry.Sum, entry.Currency),
+		})
+	}
+
+	for _, mismatch := range r.Transactions {
+		response.Transactions = append(response.Transactions, TransactionMismatchResponse{
+			TransactionID: mismatch.TransactionID,
+			Status:        mismatch.Status,
+			Entries:       mismatch.Entries,
+		})
+	}
+
+	return response
+}
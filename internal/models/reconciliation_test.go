@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciliationReport_Balanced(t *testing.T) {
+	report := &ReconciliationReport{AccountsChecked: 3}
+	assert.True(t, report.Balanced())
+
+	report.Accounts = []AccountDrift{{AccountID: 1, Currency: "USD", Balance: 100, ExpectedBalance: 90}}
+	assert.False(t, report.Balanced())
+
+	report = &ReconciliationReport{Entries: []UnbalancedEntry{{EntryID: "e", Currency: "USD", Sum: 1}}}
+	assert.False(t, report.Balanced())
+
+	report = &ReconciliationReport{Transactions: []TransactionMismatch{{TransactionID: "t", Status: StatusPending, Entries: 1}}}
+	assert.False(t, report.Balanced())
+}
+
+func TestReconciliationReport_ToResponse(t *testing.T) {
+	report := &ReconciliationReport{
+		CheckedAt:       time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC),
+		AccountsChecked: 2,
+		Accounts: []AccountDrift{
+			{AccountID: 7, Currency: "JPY", Balance: 1500, ExpectedBalance: 1000, HeldBalance: 0, ExpectedHeldBalance: 200},
+		},
+	}
+
+	data, err := json.Marshal(report.ToResponse())
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"balanced": false,
+		"checked_at": "2024-03-15T12:00:00Z",
+		"accounts_checked": 2,
+		"account_drift": [{
+			"account_id": 7,
+			"currency": "JPY",
+			"balance": "1500",
+			"expected_balance": "1000",
+			"held_balance": "0",
+			"expected_held_balance": "200"
+		}],
+		"unbalanced_entries": [],
+		"transaction_mismatches": []
+	}`, string(data))
+}
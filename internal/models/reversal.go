@@ -0,0 +1,83 @@
+package models
+
+// CreateReversalRequest asks for a compensating transaction that returns
+// money to the original's source accounts.
+type CreateReversalRequest struct {
+	// Amount is in the original transaction's currency. Left empty, whatever
+	// has not been reversed yet is reversed.
+	Amount Decimal `json:"amount,omitempty"`
+}
+
+func (r *CreateReversalRequest) Validate() error {
+	if r.Amount == "" {
+		return nil
+	}
+	_, err := validateAmount(r.Amount)
+	return err
+}
+
+// RemainingAmount is the part of the transaction, in its currency, that has
+// not been reversed yet.
+func (t *Transaction) RemainingAmount() int64 {
+	return t.Amount - t.ReversedAmount
+}
+
+// ReversalAmount resolves how much of the transaction a reversal request
+// returns. Reversals themselves can not be reversed, and multi-leg
+// transactions are only ever reversed in full.
+func (t *Transaction) ReversalAmount(req CreateReversalRequest) (int64, error) {
+	if t.ReversalOf != nil {
+		return 0, ErrNotReversible
+	}
+	if t.Status != StatusCompleted && t.Status != StatusPartiallyReversed {
+		return 0, ErrNotReversible
+	}
+
+	remaining := t.RemainingAmount()
+	amount := remaining
+	if req.Amount != "" {
+		var err error
+		if amount, err = positiveAmountIn(req.Amount, t.Currency); err != nil {
+			return 0, err
+		}
+	}
+
+	if amount > remaining || remaining == 0 {
+		return 0, ErrReversalExceedsTotal
+	}
+	if t.IsMultiLeg() && amount != t.Amount {
+		return 0, ErrPartialReversal
+	}
+
+	return amount, nil
+}
+
+// NewReversal builds the transaction that returns amount of t to its
+// source. destinationAmount is the matching share of t's destination amount,
+// which the reversal takes back from the destination.
+func (t *Transaction) NewReversal(amount, destinationAmount int64) *Transaction {
+	originalID := t.ID
+
+	return &Transaction{
+		SourceAccountID:      t.DestinationAccountID,
+		DestinationAccountID: t.SourceAccountID,
+		Amount:               destinationAmount,
+		Currency:             t.DestinationCurrency,
+		DestinationAmount:    amount,
+		DestinationCurrency:  t.Currency,
+		ReversalOf:           &originalID,
+		Sources:              append([]Leg(nil), t.Destinations...),
+		Destinations:         append([]Leg(nil), t.Sources...),
+	}
+}
+
+// ApplyReversal records that amount of t has been reversed and updates its
+// status accordingly.
+func (t *Transaction) ApplyReversal(amount int64) {
+	t.ReversedAmount += amount
+	if t.ReversedAmount == t.Amount {
+		t.Status = StatusReversed
+	} else {
+		t.Status = StatusPartiallyReversed
+	}
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_ReversalAmount(t *testing.T) {
+	reversalOf := "7b3c0c52-8a6f-4d8e-9f0e-2b7f1c1d5e11"
+
+	tests := []struct {
+		name        string
+		transaction Transaction
+		amount      Decimal
+		expected    int64
+		expectError error
+	}{
+		{
+			name:        "Defaults to the full amount",
+			transaction: Transaction{Amount: 10000, Currency: "USD", Status: StatusCompleted},
+			expected:    10000,
+		},
+		{
+			name:        "Defaults to what is left",
+			transaction: Transaction{Amount: 10000, ReversedAmount: 2500, Currency: "USD", Status: StatusPartiallyReversed},
+			expected:    7500,
+		},
+		{
+			name:        "Partial amount",
+			transaction: Transaction{Amount: 10000, Currency: "USD", Status: StatusCompleted},
+			amount:      "25.50",
+			expected:    2550,
+		},
+		{
+			name:        "More than is left",
+			transaction: Transaction{Amount: 10000, ReversedAmount: 2500, Currency: "USD", Status: StatusPartiallyReversed},
+			amount:      "75.01",
+			expectError: ErrReversalExceedsTotal,
+		},
+		{
+			name:        "Already fully reversed",
+			transaction: Transaction{Amount: 10000, ReversedAmount: 10000, Currency: "USD", Status: StatusReversed},
+			expectError: ErrNotReversible,
+		},
+		{
+			name:        "Reversal of a reversal",
+			transaction: Transaction{Amount: 10000, Currency: "USD", Status: StatusCompleted, ReversalOf: &reversalOf},
+			expectError: ErrNotReversible,
+		},
+		{
+			name:        "Too many decimals for the currency",
+			transaction: Transaction{Amount: 1000, Currency: "JPY", Status: StatusCompleted},
+			amount:      "1.5",
+			expectError: ErrTooManyDecimals,
+		},
+		{
+			name: "Partial multi-leg",
+			transaction: Transaction{
+				Amount:       10000,
+				Currency:     "USD",
+				Status:       StatusCompleted,
+				Sources:      []Leg{{AccountID: 1, Amount: 10000}},
+				Destinations: []Leg{{AccountID: 2, Amount: 6000}, {AccountID: 3, Amount: 4000}},
+			},
+			amount:      "50.00",
+			expectError: ErrPartialReversal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, err := tt.transaction.ReversalAmount(CreateReversalRequest{Amount: tt.amount})
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+}
+
+func TestTransaction_NewReversal(t *testing.T) {
+	original := Transaction{
+		ID:                   "7b3c0c52-8a6f-4d8e-9f0e-2b7f1c1d5e11",
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               10000,
+		Currency:             "USD",
+		DestinationAmount:    1505000,
+		DestinationCurrency:  "JPY",
+		Status:               StatusCompleted,
+	}
+
+	reversal := original.NewReversal(4000, 602000)
+	assert.Equal(t, int64(2), reversal.SourceAccountID)
+	assert.Equal(t, int64(1), reversal.DestinationAccountID)
+	assert.Equal(t, int64(602000), reversal.Amount)
+	assert.Equal(t, "JPY", reversal.Currency)
+	assert.Equal(t, int64(4000), reversal.DestinationAmount)
+	assert.Equal(t, "USD", reversal.DestinationCurrency)
+	require.NotNil(t, reversal.ReversalOf)
+	assert.Equal(t, original.ID, *reversal.ReversalOf)
+	assert.NoError(t, NewTransferEntry("entry", reversal).Validate())
+
+	original.ApplyReversal(4000)
+	assert.Equal(t, StatusPartiallyReversed, original.Status)
+	original.ApplyReversal(6000)
+	assert.Equal(t, StatusReversed, original.Status)
+	assert.Equal(t, int64(0), original.RemainingAmount())
+}
+
+func TestTransaction_NewReversalMultiLeg(t *testing.T) {
+	original := Transaction{
+		ID:                  "7b3c0c52-8a6f-4d8e-9f0e-2b7f1c1d5e11",
+		Amount:              10000,
+		Currency:            "USD",
+		DestinationAmount:   10000,
+		DestinationCurrency: "USD",
+		Sources:             []Leg{{AccountID: 1, Amount: 10000}},
+		Destinations:        []Leg{{AccountID: 2, Amount: 6000}, {AccountID: 3, Amount: 4000}},
+	}
+
+	reversal := original.NewReversal(10000, 10000)
+	assert.Equal(t, original.Destinations, reversal.Sources)
+	assert.Equal(t, original.Sources, reversal.Destinations)
+
+	entry := NewTransferEntry("entry", reversal)
+	require.NoError(t, entry.Validate())
+	assert.Equal(t, int64(-6000), entry.Postings[0].Amount)
+	assert.Equal(t, int64(2), *entry.Postings[0].AccountID)
+}
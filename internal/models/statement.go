@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// StatementPeriod is the span of an account statement. Both ends are
+// inclusive; a zero From starts the statement when the account was opened.
+type StatementPeriod struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseStatementPeriod reads the from and to query parameters of a
+// statement. Each is an RFC 3339 timestamp or a date (YYYY-MM-DD): from a
+// date the statement starts at the beginning of that day in UTC, to a date
+// it ends with that day. Without to the statement ends now. Like a balance
+// query, a statement may not end in the future.
+func ParseStatementPeriod(from, to string, now time.Time) (StatementPeriod, error) {
+	period := StatementPeriod{To: now.Truncate(time.Microsecond)}
+
+	if to != "" {
+		asOf, err := ParseAsOf(to, now)
+		if err != nil {
+			return StatementPeriod{}, ErrInvalidStatementPeriod
+		}
+		period.To = asOf
+	}
+
+	if from != "" {
+		start, err := time.Parse(time.RFC3339Nano, from)
+		if err != nil {
+			if start, err = time.Parse(time.DateOnly, from); err != nil {
+				return StatementPeriod{}, ErrInvalidStatementPeriod
+			}
+		}
+		period.From = start.Truncate(time.Microsecond)
+	}
+
+	if period.From.After(period.To) {
+		return StatementPeriod{}, ErrInvalidStatementPeriod
+	}
+
+	return period, nil
+}
+
+// Statement is the heading of an account statement: the account, the period
+// it covers and its balance just before From.
+type Statement struct {
+	AccountID      int64
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+}
+
+// StatementEntry is one posting to an account, with the journal entry it
+// belongs to. Postings are ordered by (PostedAt, PostingID), the order
+// BalanceAt sums them in.
+type StatementEntry struct {
+	PostingID     int64
+	EntryID       string
+	TransactionID *string
+	Kind          string
+	Amount        int64
+	PostedAt      time.Time
+}
+
+// PostingCursor is the keyset position of the last posting read.
+type PostingCursor struct {
+	PostedAt  time.Time
+	PostingID int64
+}
+
+// PostingFilter selects a page of an account's postings posted between From
+// and To inclusive and after Cursor, oldest first.
+type PostingFilter struct {
+	AccountID int64
+	From      time.Time
+	To        time.Time
+	Cursor    *PostingCursor
+	Limit     int
+}
+
+// StatementWriter renders a statement as it is read: Begin once, then Entry
+// for each posting with the account's balance after it, then End with the
+// closing balance. Nothing needs to be held back until the end, so a
+// statement of any length streams in constant memory.
+type StatementWriter interface {
+	Begin(statement *Statement) error
+	Entry(entry *StatementEntry, balance int64) error
+	End(closingBalance int64) error
+}
+
+// Statement formats.
+const (
+	StatementFormatCSV   = "csv"
+	StatementFormatJSONL = "jsonl"
+	StatementFormatOFX   = "ofx"
+)
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// NewStatementWriter returns a writer that renders a statement to w in the
+// given format. numericAmounts writes the amounts of a JSON Lines statement
+// as bare numbers, as NumericAmounts does for a response; CSV and OFX have
+// only the one form.
+func NewStatementWriter(format string, numericAmounts bool, w io.Writer) (StatementWriter, error) {
+	switch format {
+	case StatementFormatCSV:
+		return &csvStatementWriter{csv: csv.NewWriter(w)}, nil
+	case StatementFormatJSONL:
+		return &jsonlStatementWriter{json: json.NewEncoder(w), numericAmounts: numericAmounts}, nil
+	case StatementFormatOFX:
+		return &ofxStatementWriter{w: w}, nil
+	default:
+		return nil, ErrInvalidStatementFormat
+	}
+}
+
+// Kinds of statement lines in the CSV and JSON Lines formats.
+const (
+	StatementLineOpening = "opening"
+	StatementLineEntry   = "entry"
+	StatementLineClosing = "closing"
+)
+
+// statementLine is one line of a CSV or JSON Lines statement. The opening
+// line holds the balance just before the statement's From, each entry line
+// the balance after its posting and the closing line the balance at To.
+type statementLine struct {
+	Line          string    `json:"line"`
+	AccountID     int64     `json:"account_id,omitempty"`
+	Currency      string    `json:"currency,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+	EntryID       string    `json:"entry_id,omitempty"`
+	TransactionID *string   `json:"transaction_id,omitempty"`
+	Kind          string    `json:"kind,omitempty"`
+	Amount        Decimal   `json:"amount,omitempty"`
+	Balance       Decimal   `json:"balance"`
+}
+
+func openingLine(s *Statement) *statementLine {
+	return &statementLine{
+		Line:      StatementLineOpening,
+		AccountID: s.AccountID,
+		Currency:  s.Currency,
+		Timestamp: s.From.UTC(),
+		Balance:   MinorUnitsToDecimal(s.OpeningBalance, s.Currency),
+	}
+}
+
+func entryLine(s *Statement, entry *StatementEntry, balance int64) *statementLine {
+	return &statementLine{
+		Line:          StatementLineEntry,
+		Timestamp:     entry.PostedAt.UTC(),
+		EntryID:       entry.EntryID,
+		TransactionID: entry.TransactionID,
+		Kind:          entry.Kind,
+		Amount:        MinorUnitsToDecimal(entry.Amount, s.Currency),
+		Balance:       MinorUnitsToDecimal(balance, s.Currency),
+	}
+}
+
+func closingLine(s *Statement, balance int64) *statementLine {
+	return &statementLine{
+		Line:      StatementLineClosing,
+		AccountID: s.AccountID,
+		Currency:  s.Currency,
+		Timestamp: s.To.UTC(),
+		Balance:   MinorUnitsToDecimal(balance, s.Currency),
+	}
+}
+
+// csvStatementWriter writes a header and then one row per statement line.
+type csvStatementWriter struct {
+	csv       *csv.Writer
+	statement *Statement
+}
+
+func (w *csvStatementWriter) Begin(statement *Statement) error {
+	w.statement = statement
+	header := []string{"line", "account_id", "currency", "timestamp", "entry_id", "transaction_id", "kind", "amount", "balance"}
+	if err := w.csv.Write(header); err != nil {
+		return err
+	}
+	return w.write(openingLine(statement))
+}
+
+func (w *csvStatementWriter) Entry(entry *StatementEntry, balance int64) error {
+	return w.write(entryLine(w.statement, entry, balance))
+}
+
+func (w *csvStatementWriter) End(closingBalance int64) error {
+	if err := w.write(closingLine(w.statement, closingBalance)); err != nil {
+		return err
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *csvStatementWriter) write(line *statementLine) error {
+	accountID := ""
+	if line.AccountID != 0 {
+		accountID = strconv.FormatInt(line.AccountID, 10)
+	}
+	transactionID := ""
+	if line.TransactionID != nil {
+		transactionID = *line.TransactionID
+	}
+
+	return w.csv.Write([]string{
+		line.Line,
+		accountID,
+		line.Currency,
+		line.Timestamp.Format(time.RFC3339Nano),
+		line.EntryID,
+		transactionID,
+		line.Kind,
+		string(line.Amount),
+		string(line.Balance),
+	})
+}
+
+// jsonlStatementWriter writes each statement line as a JSON object on a line
+// of its own.
+type jsonlStatementWriter struct {
+	json           *json.Encoder
+	numericAmounts bool
+	statement      *Statement
+}
+
+func (w *jsonlStatementWriter) Begin(statement *Statement) error {
+	w.statement = statement
+	return w.write(openingLine(statement))
+}
+
+func (w *jsonlStatementWriter) Entry(entry *StatementEntry, balance int64) error {
+	return w.write(entryLine(w.statement, entry, balance))
+}
+
+func (w *jsonlStatementWriter) End(closingBalance int64) error {
+	return w.write(closingLine(w.statement, closingBalance))
+}
+
+func (w *jsonlStatementWriter) write(line *statementLine) error {
+	if w.numericAmounts {
+		return w.json.Encode(line.NumericAmounts())
+	}
+	return w.json.Encode(line)
+}
+
+// OFXBankID fills the bank ID that OFX requires of a bank account; the
+// ledger's accounts are not held at a bank with a routing number.
+const OFXBankID = "LEDGER"
+
+// ofxStatementWriter writes an OFX 2.2 bank statement download. OFX has no
+// element for an opening or a running balance: the opening balance is the
+// first balance of the statement's BALLIST, and each transaction's memo
+// gives the balance after it. The closing balance is the LEDGERBAL.
+type ofxStatementWriter struct {
+	w         io.Writer
+	statement *Statement
+	err       error
+}
+
+// printf writes to the statement until a write fails, and returns the first
+// error.
+func (w *ofxStatementWriter) printf(format string, args ...any) error {
+	if w.err == nil {
+		_, w.err = fmt.Fprintf(w.w, format, args...)
+	}
+	return w.err
+}
+
+func (w *ofxStatementWriter) Begin(statement *Statement) error {
+	w.statement = statement
+	return w.printf(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<DTSERVER>%s</DTSERVER>
+<LANGUAGE>ENG</LANGUAGE>
+</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS>
+<TRNUID>0</TRNUID>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS>
+<CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>%s</BANKID><ACCTID>%d</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s</DTSTART>
+<DTEND>%s</DTEND>
+`,
+		ofxTime(time.Now()),
+		statement.Currency,
+		OFXBankID,
+		statement.AccountID,
+		ofxTime(statement.From),
+		ofxTime(statement.To),
+	)
+}
+
+func (w *ofxStatementWriter) Entry(entry *StatementEntry, balance int64) error {
+	trnType := "CREDIT"
+	if entry.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	name := "Transfer"
+	if entry.Kind == EntryKindOpeningBalance {
+		name = "Opening deposit"
+	}
+
+	memo := fmt.Sprintf("Balance %s", MinorUnitsToDecimal(balance, w.statement.Currency))
+	if entry.TransactionID != nil {
+		memo = fmt.Sprintf("Transaction %s, balance %s", *entry.TransactionID, MinorUnitsToDecimal(balance, w.statement.Currency))
+	}
+
+	return w.printf(`<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>
+`,
+		trnType,
+		ofxTime(entry.PostedAt),
+		MinorUnitsToDecimal(entry.Amount, w.statement.Currency),
+		entry.EntryID,
+		name,
+		memo,
+	)
+}
+
+func (w *ofxStatementWriter) End(closingBalance int64) error {
+	s := w.statement
+	return w.printf(`</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+<BALLIST><BAL><NAME>Opening balance</NAME><DESC>Balance at the start of the statement</DESC><BALTYPE>DOLLAR</BALTYPE><VALUE>%s</VALUE><DTASOF>%s</DTASOF></BAL></BALLIST>
+</STMTRS>
+</STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`,
+		MinorUnitsToDecimal(closingBalance, s.Currency),
+		ofxTime(s.To),
+		MinorUnitsToDecimal(s.OpeningBalance, s.Currency),
+		ofxTime(s.From),
+	)
+}
+
+// ofxTime formats t as an OFX date and time in UTC.
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
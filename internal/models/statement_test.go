@@ -0,0 +1,123 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatementPeriod(t *testing.T) {
+	now := time.Date(2024, 4, 15, 12, 0, 0, 0, time.UTC)
+
+	period, err := ParseStatementPeriod("2024-03-01", "2024-03-31", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), period.From)
+	assert.Equal(t, time.Date(2024, 3, 31, 23, 59, 59, 999999000, time.UTC), period.To)
+
+	period, err = ParseStatementPeriod("", "", now)
+	require.NoError(t, err)
+	assert.True(t, period.From.IsZero())
+	assert.Equal(t, now, period.To)
+
+	period, err = ParseStatementPeriod("2024-04-01T10:00:00Z", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC), period.From)
+
+	invalid := []struct {
+		name     string
+		from, to string
+	}{
+		{"malformed from", "March", ""},
+		{"malformed to", "", "2024-13-01"},
+		{"to in the future", "", "2024-04-16"},
+		{"from after to", "2024-03-02", "2024-03-01"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStatementPeriod(tt.from, tt.to, now)
+			assert.ErrorIs(t, err, ErrInvalidStatementPeriod)
+		})
+	}
+}
+
+func TestStatementWriter(t *testing.T) {
+	transactionID := "6f1c2a7e-0000-4000-8000-000000000001"
+	statement := &Statement{
+		AccountID:      7,
+		Currency:       "USD",
+		From:           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2024, 3, 31, 23, 59, 59, 999999000, time.UTC),
+		OpeningBalance: 10000,
+	}
+	entry := &StatementEntry{
+		PostingID:     3,
+		EntryID:       "entry-1",
+		TransactionID: &transactionID,
+		Kind:          EntryKindTransfer,
+		Amount:        -2550,
+		PostedAt:      time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC),
+	}
+
+	write := func(t *testing.T, format string, numericAmounts bool) string {
+		var out bytes.Buffer
+		w, err := NewStatementWriter(format, numericAmounts, &out)
+		require.NoError(t, err)
+		require.NoError(t, w.Begin(statement))
+		require.NoError(t, w.Entry(entry, 7450))
+		require.NoError(t, w.End(7450))
+		return out.String()
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		assert.Equal(t, ""+
+			"line,account_id,currency,timestamp,entry_id,transaction_id,kind,amount,balance\n"+
+			"opening,7,USD,2024-03-01T00:00:00Z,,,,,100.00\n"+
+			"entry,,,2024-03-05T09:30:00Z,entry-1,"+transactionID+",TRANSFER,-25.50,74.50\n"+
+			"closing,7,USD,2024-03-31T23:59:59.999999Z,,,,,74.50\n",
+			write(t, StatementFormatCSV, false))
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(write(t, StatementFormatJSONL, false)), "\n")
+		require.Len(t, lines, 3)
+
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &line))
+		assert.Equal(t, "entry", line["line"])
+		assert.Equal(t, transactionID, line["transaction_id"])
+		assert.Equal(t, "-25.50", line["amount"])
+		assert.Equal(t, "74.50", line["balance"])
+		assert.NotContains(t, line, "account_id")
+
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &line))
+		assert.Equal(t, "closing", line["line"])
+		assert.Equal(t, "74.50", line["balance"])
+	})
+
+	t.Run("jsonl numeric amounts", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSpace(write(t, StatementFormatJSONL, true)), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, `{"line":"opening","account_id":7,"currency":"USD","timestamp":"2024-03-01T00:00:00Z","balance":100.00}`, lines[0])
+		assert.Contains(t, lines[1], `"amount":-25.50,"balance":74.50}`)
+		assert.Contains(t, lines[2], `"balance":74.50}`)
+	})
+
+	t.Run("ofx", func(t *testing.T) {
+		out := write(t, StatementFormatOFX, false)
+		assert.Contains(t, out, "<ACCTID>7</ACCTID>")
+		assert.Contains(t, out, "<DTSTART>20240301000000.000[0:GMT]</DTSTART>")
+		assert.Contains(t, out, "<TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240305093000.000[0:GMT]</DTPOSTED><TRNAMT>-25.50</TRNAMT><FITID>entry-1</FITID>")
+		assert.Contains(t, out, "<LEDGERBAL><BALAMT>74.50</BALAMT>")
+		assert.Contains(t, out, "<VALUE>100.00</VALUE>")
+		assert.True(t, strings.HasSuffix(out, "</OFX>\n"))
+	})
+
+	_, err := NewStatementWriter("pdf", false, &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrInvalidStatementFormat)
+}
@@ -2,58 +2,271 @@ package models
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 )
 
+// MaxTransactionLegs caps the number of legs of a multi-leg transaction.
+const MaxTransactionLegs = 100
+
+const (
+	StatusPending           = "PENDING"
+	StatusCompleted         = "COMPLETED"
+	StatusVoided            = "VOIDED"
+	StatusExpired           = "EXPIRED"
+	StatusPartiallyReversed = "PARTIALLY_REVERSED"
+	StatusReversed          = "REVERSED"
+)
+
+// Transaction is a business-level transfer. A simple transfer has one source
+// and one destination account; a multi-leg transaction leaves those IDs at
+// zero and lists its legs in Sources and Destinations instead.
 type Transaction struct {
-	ID                   string    `db:"id"`
-	SourceAccountID      int64     `db:"source_account_id"`
-	DestinationAccountID int64     `db:"destination_account_id"`
-	Amount               int64     `db:"amount"`
-	Status               string    `db:"status"`
-	IdempotencyKey       *string   `db:"idempotency_key"`
-	CreatedAt            time.Time `db:"created_at"`
+	ID                   string     `db:"id"`
+	SourceAccountID      int64      `db:"source_account_id"`
+	DestinationAccountID int64      `db:"destination_account_id"`
+	Amount               int64      `db:"amount"`
+	Currency             string     `db:"currency"`
+	DestinationAmount    int64      `db:"destination_amount"`
+	DestinationCurrency  string     `db:"destination_currency"`
+	FXRate               *Decimal   `db:"fx_rate"`
+	FXQuoteID            *string    `db:"fx_quote_id"`
+	Status               string     `db:"status"`
+	ReversalOf           *string    `db:"reversal_of"`
+	ReversedAmount       int64      `db:"reversed_amount"`
+	AuthorizedAmount     int64      `db:"authorized_amount"`
+	HoldExpiresAt        *time.Time `db:"hold_expires_at"`
+	ChainAccountID       int64      `db:"chain_account_id"`
+	ChainSeq             int64      `db:"chain_seq"`
+	PrevHash             string     `db:"prev_hash"`
+	Hash                 string     `db:"hash"`
+	SettlementHash       string     `db:"settlement_hash"`
+	CreatedAt            time.Time  `db:"created_at"`
+	Sources              []Leg
+	Destinations         []Leg
+}
+
+// Leg is one account's share of a multi-leg transaction, in minor units of
+// the transaction currency.
+type Leg struct {
+	AccountID int64
+	Amount    int64
+}
+
+// IsMultiLeg reports whether the transaction moves money between more than
+// one pair of accounts.
+func (t *Transaction) IsMultiLeg() bool {
+	return len(t.Sources) > 0
 }
 
 type TransactionResponse struct {
-	TransactionID        string    `json:"transaction_id"`
-	SourceAccountID      int64     `json:"source_account_id"`
-	DestinationAccountID int64     `json:"destination_account_id"`
-	Amount               float64   `json:"amount"`
-	Status               string    `json:"status"`
-	CreatedAt            time.Time `json:"created_at"`
+	TransactionID        string        `json:"transaction_id"`
+	SourceAccountID      int64         `json:"source_account_id,omitempty"`
+	DestinationAccountID int64         `json:"destination_account_id,omitempty"`
+	Sources              []LegResponse `json:"sources,omitempty"`
+	Destinations         []LegResponse `json:"destinations,omitempty"`
+	Amount               Decimal       `json:"amount"`
+	Currency             string        `json:"currency"`
+	DestinationAmount    Decimal       `json:"destination_amount,omitempty"`
+	DestinationCurrency  string        `json:"destination_currency,omitempty"`
+	FXRate               Decimal       `json:"fx_rate,omitempty"`
+	QuoteID              string        `json:"quote_id,omitempty"`
+	Status               string        `json:"status"`
+	ReversalOf           string        `json:"reversal_of,omitempty"`
+	ReversedAmount       Decimal       `json:"reversed_amount,omitempty"`
+	AuthorizedAmount     Decimal       `json:"authorized_amount,omitempty"`
+	HoldExpiresAt        *time.Time    `json:"hold_expires_at,omitempty"`
+	CreatedAt            time.Time     `json:"created_at"`
+}
+
+type LegResponse struct {
+	AccountID int64   `json:"account_id"`
+	Amount    Decimal `json:"amount"`
 }
 
 func (t *Transaction) ToResponse() TransactionResponse {
-	return TransactionResponse{
+	response := TransactionResponse{
 		TransactionID:        t.ID,
 		SourceAccountID:      t.SourceAccountID,
 		DestinationAccountID: t.DestinationAccountID,
-		Amount:               CentsToFloat(t.Amount),
+		Amount:               MinorUnitsToDecimal(t.Amount, t.Currency),
+		Currency:             t.Currency,
 		Status:               t.Status,
 		CreatedAt:            t.CreatedAt,
 	}
+
+	for _, leg := range t.Sources {
+		response.Sources = append(response.Sources, LegResponse{
+			AccountID: leg.AccountID,
+			Amount:    MinorUnitsToDecimal(leg.Amount, t.Currency),
+		})
+	}
+	for _, leg := range t.Destinations {
+		response.Destinations = append(response.Destinations, LegResponse{
+			AccountID: leg.AccountID,
+			Amount:    MinorUnitsToDecimal(leg.Amount, t.Currency),
+		})
+	}
+
+	// Reversals of cross-currency transfers carry no rate of their own but
+	// still move money between currencies.
+	if t.FXRate != nil || t.Currency != t.DestinationCurrency {
+		response.DestinationAmount = MinorUnitsToDecimal(t.DestinationAmount, t.DestinationCurrency)
+		response.DestinationCurrency = t.DestinationCurrency
+	}
+	if t.FXRate != nil {
+		response.FXRate = *t.FXRate
+	}
+	if t.FXQuoteID != nil {
+		response.QuoteID = *t.FXQuoteID
+	}
+	if t.ReversalOf != nil {
+		response.ReversalOf = *t.ReversalOf
+	}
+	if t.ReversedAmount > 0 {
+		response.ReversedAmount = MinorUnitsToDecimal(t.ReversedAmount, t.Currency)
+	}
+	if t.AuthorizedAmount > 0 {
+		response.AuthorizedAmount = MinorUnitsToDecimal(t.AuthorizedAmount, t.Currency)
+	}
+	if t.Status == StatusPending {
+		response.HoldExpiresAt = t.HoldExpiresAt
+	}
+
+	return response
 }
 
+// CreateTransactionRequest is either a simple transfer (source, destination
+// and amount) or a multi-leg transaction (sources and destinations), never
+// both.
 type CreateTransactionRequest struct {
 	SourceAccountID      int64   `json:"source_account_id"`
 	DestinationAccountID int64   `json:"destination_account_id"`
-	Amount               float64 `json:"amount"`
+	Amount               Decimal `json:"amount"`
+	// QuoteID references an FX quote and is required when the accounts hold
+	// different currencies. Amount is then in the source account's currency.
+	QuoteID string `json:"quote_id,omitempty"`
+
+	// Sources and Destinations move money from several accounts to several
+	// others atomically. All accounts must share one currency and the legs
+	// on each side must add up to the same total.
+	Sources      []TransactionLegRequest `json:"sources,omitempty"`
+	Destinations []TransactionLegRequest `json:"destinations,omitempty"`
+
+	// Pending only authorizes a simple transfer: the amount is held on the
+	// source until the transfer is captured, voided or the hold expires.
+	Pending bool `json:"pending,omitempty"`
+}
+
+type TransactionLegRequest struct {
+	AccountID int64   `json:"account_id"`
+	Amount    Decimal `json:"amount"`
+}
+
+func (r *CreateTransactionRequest) IsMultiLeg() bool {
+	return len(r.Sources) > 0 || len(r.Destinations) > 0
 }
 
 func (r *CreateTransactionRequest) Validate() error {
+	if r.IsMultiLeg() {
+		return r.validateLegs()
+	}
+
 	if r.SourceAccountID <= 0 || r.DestinationAccountID <= 0 {
 		return ErrInvalidAccountID
 	}
 	if r.SourceAccountID == r.DestinationAccountID {
 		return ErrSameAccount
 	}
-	if r.Amount <= 0 {
-		return ErrInvalidAmount
+	_, err := validateAmount(r.Amount)
+	return err
+}
+
+func (r *CreateTransactionRequest) validateLegs() error {
+	if r.SourceAccountID != 0 || r.DestinationAccountID != 0 || r.Amount != "" || r.QuoteID != "" || r.Pending {
+		return ErrInvalidLegs
+	}
+	if len(r.Sources) == 0 || len(r.Destinations) == 0 {
+		return ErrInvalidLegs
+	}
+	if len(r.Sources)+len(r.Destinations) > MaxTransactionLegs {
+		return ErrInvalidLegs
+	}
+
+	// Totals are compared at the finest scale any currency uses: if they
+	// match there, they match in the accounts' currency too.
+	seen := make(map[int64]bool)
+	var totals [2]int64
+
+	for side, legs := range [][]TransactionLegRequest{r.Sources, r.Destinations} {
+		for _, leg := range legs {
+			if leg.AccountID <= 0 {
+				return ErrInvalidAccountID
+			}
+			if seen[leg.AccountID] {
+				return ErrSameAccount
+			}
+			seen[leg.AccountID] = true
+
+			amount, err := validateAmount(leg.Amount)
+			if err != nil {
+				return err
+			}
+			if totals[side] > math.MaxInt64-amount {
+				return ErrInvalidAmountFormat
+			}
+			totals[side] += amount
+		}
 	}
+
+	if totals[0] != totals[1] {
+		return ErrUnbalancedLegs
+	}
+
 	return nil
 }
 
+// validateAmount checks an amount is a positive decimal. The exact scale
+// depends on the accounts' currency, which is only known once they are
+// loaded; here we only reject what no currency could hold.
+func validateAmount(amount Decimal) (int64, error) {
+	if amount == "" {
+		return 0, ErrInvalidAmount
+	}
+	value, err := parseDecimal(string(amount), maxMinorUnits)
+	if err != nil {
+		return 0, err
+	}
+	if value <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return value, nil
+}
+
+// AmountIn returns the transfer amount in the minor units of currency.
+func (r *CreateTransactionRequest) AmountIn(currency string) (int64, error) {
+	return positiveAmountIn(r.Amount, currency)
+}
+
+// AmountIn returns the leg amount in the minor units of currency.
+func (l *TransactionLegRequest) AmountIn(currency string) (int64, error) {
+	return positiveAmountIn(l.Amount, currency)
+}
+
+func positiveAmountIn(amount Decimal, currency string) (int64, error) {
+	if amount == "" {
+		return 0, ErrInvalidAmount
+	}
+	value, err := DecimalToMinorUnits(amount, currency)
+	if err != nil {
+		return 0, err
+	}
+	if value <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return value, nil
+}
+
 func (t *Transaction) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.ToResponse())
 }
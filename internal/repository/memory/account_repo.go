@@ -0,0 +1,253 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type AccountRepository struct {
+	store *Store
+}
+
+var _ repository.AccountRepository = (*AccountRepository)(nil)
+
+func NewAccountRepository(store *Store) *AccountRepository {
+	return &AccountRepository{store: store}
+}
+
+func (r *AccountRepository) Create(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	if l.accounts.has(account.ID) {
+		return models.ErrAccountExists
+	}
+
+	l.accounts.set(account.ID, models.Account{
+		ID:             account.ID,
+		OverdraftLimit: clonePtr(account.OverdraftLimit),
+		Currency:       account.Currency,
+		Status:         models.AccountStatusActive,
+		Hot:            account.Hot,
+		CreatedAt:      now(),
+	})
+	return nil
+}
+
+func (r *AccountRepository) Import(ctx context.Context, tx repository.Tx, accounts []models.Account) ([]int64, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []int64
+	for _, account := range accounts {
+		if l.accounts.has(account.ID) {
+			continue
+		}
+		l.accounts.set(account.ID, models.Account{
+			ID:             account.ID,
+			OverdraftLimit: clonePtr(account.OverdraftLimit),
+			Currency:       account.Currency,
+			Status:         models.AccountStatusActive,
+			Hot:            account.Hot,
+			CreatedAt:      now(),
+		})
+		created = append(created, account.ID)
+	}
+	return created, nil
+}
+
+func (r *AccountRepository) ListOpenings(ctx context.Context, tx repository.Tx, ids []int64) ([]models.AccountOpening, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var openings []models.AccountOpening
+	for _, id := range ids {
+		account, ok := l.accounts.get(id)
+		if !ok {
+			continue
+		}
+
+		opening := models.AccountOpening{Account: *cloneAccount(account)}
+		for posting := range l.postingsOf(id) {
+			if entry, _ := l.entries.get(posting.EntryID); entry.Kind == models.EntryKindOpeningBalance {
+				opening.Balance += posting.Amount
+			}
+		}
+		openings = append(openings, opening)
+	}
+	return openings, nil
+}
+
+func (r *AccountRepository) GetByID(ctx context.Context, id int64) (*models.Account, error) {
+	return getAccount(r.store.read(), id)
+}
+
+// GetForUpdate needs no lock of its own: write transactions already run one
+// at a time.
+func (r *AccountRepository) GetForUpdate(ctx context.Context, tx repository.Tx, id int64) (*models.Account, error) {
+	return r.getLocked(tx, id)
+}
+
+func (r *AccountRepository) GetForCredit(ctx context.Context, tx repository.Tx, id int64) (*models.Account, error) {
+	return r.getLocked(tx, id)
+}
+
+// getLocked returns the account without its pending credits, as the Postgres
+// store's locking reads do.
+func (r *AccountRepository) getLocked(tx repository.Tx, id int64) (*models.Account, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := getAccount(l, id)
+	if err != nil {
+		return nil, err
+	}
+	account.PendingCredits = 0
+	return account, nil
+}
+
+func getAccount(l *ledger, id int64) (*models.Account, error) {
+	account, ok := l.accounts.get(id)
+	if !ok {
+		return nil, models.ErrAccountNotFound
+	}
+	return cloneAccount(account), nil
+}
+
+func (r *AccountRepository) AdjustHold(ctx context.Context, tx repository.Tx, id int64, delta int64) error {
+	return r.updateAccount(tx, id, func(account *models.Account) error {
+		if account.HeldBalance+delta < 0 {
+			return errNegativeHold
+		}
+		account.HeldBalance += delta
+		return nil
+	})
+}
+
+func (r *AccountRepository) UpdateStatus(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	return r.updateAccount(tx, account.ID, func(stored *models.Account) error {
+		stored.Status = account.Status
+		return nil
+	})
+}
+
+func (r *AccountRepository) UpdateOverdraftLimit(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	return r.updateAccount(tx, account.ID, func(stored *models.Account) error {
+		if account.OverdraftLimit != nil && stored.Balance < -*account.OverdraftLimit {
+			return errOverdraftLimit
+		}
+		stored.OverdraftLimit = clonePtr(account.OverdraftLimit)
+		return nil
+	})
+}
+
+func (r *AccountRepository) UpdateHot(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	return r.updateAccount(tx, account.ID, func(stored *models.Account) error {
+		stored.Hot = account.Hot
+		return nil
+	})
+}
+
+func (r *AccountRepository) SettleCredits(ctx context.Context, tx repository.Tx, id int64) (int64, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	account, _ := l.accounts.get(id)
+	settled := account.PendingCredits
+	if settled == 0 {
+		return 0, nil
+	}
+
+	err = l.updateAccount(id, func(account *models.Account) error {
+		account.Balance += account.PendingCredits
+		account.PendingCredits = 0
+		return nil
+	})
+	return settled, err
+}
+
+func (r *AccountRepository) ListUnsettled(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	var ids []int64
+	for id, account := range r.store.read().accounts.all() {
+		if id > afterID && account.PendingCredits != 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// updateAccount applies fn to the stored account, if there is one, and sets
+// its updated_at.
+func (r *AccountRepository) updateAccount(tx repository.Tx, id int64, fn func(account *models.Account) error) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+	return l.updateAccount(id, fn)
+}
+
+func (l *ledger) updateAccount(id int64, fn func(account *models.Account) error) error {
+	account, ok := l.accounts.get(id)
+	if !ok {
+		return nil
+	}
+
+	if err := fn(&account); err != nil {
+		return err
+	}
+
+	updatedAt := now()
+	account.UpdatedAt = &updatedAt
+	l.accounts.set(id, account)
+	return nil
+}
+
+func (r *AccountRepository) CreateStatusChange(ctx context.Context, tx repository.Tx, change *models.AccountStatusChange) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	if !l.accounts.has(change.AccountID) {
+		return errUnknownAccount
+	}
+
+	change.ID = int64(len(l.statusChanges)) + 1
+	change.CreatedAt = now()
+	l.statusChanges = append(l.statusChanges, *change)
+	return nil
+}
+
+func (r *AccountRepository) ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error) {
+	changes := []models.AccountStatusChange{}
+	for _, change := range r.store.read().statusChanges {
+		if change.AccountID == accountID {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func cloneAccount(account models.Account) *models.Account {
+	account.OverdraftLimit = clonePtr(account.OverdraftLimit)
+	account.UpdatedAt = clonePtr(account.UpdatedAt)
+	return &account
+}
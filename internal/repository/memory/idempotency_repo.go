@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type IdempotencyRepository struct {
+	store *Store
+}
+
+var _ repository.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+func NewIdempotencyRepository(store *Store) *IdempotencyRepository {
+	return &IdempotencyRepository{store: store}
+}
+
+// Claim never has to wait: the transaction that held the key, if any, has
+// already ended.
+func (r *IdempotencyRepository) Claim(ctx context.Context, tx repository.Tx, key models.RequestKey, fingerprint string) (*models.IdempotencyKey, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, ok := l.idempotencyKeys.get(key); ok {
+		return cloneIdempotencyKey(existing), nil
+	}
+
+	l.idempotencyKeys.set(key, models.IdempotencyKey{
+		ClientID:           key.ClientID,
+		Key:                key.Key,
+		RequestFingerprint: fingerprint,
+		CreatedAt:          now(),
+	})
+	return nil, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, tx repository.Tx, outcome *models.IdempotencyKey) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	key := models.RequestKey{ClientID: outcome.ClientID, Key: outcome.Key}
+	stored, ok := l.idempotencyKeys.get(key)
+	if !ok {
+		return nil
+	}
+
+	stored.TransactionID = clonePtr(outcome.TransactionID)
+	stored.AccountID = clonePtr(outcome.AccountID)
+	stored.ErrorCode = outcome.ErrorCode
+	l.idempotencyKeys.set(key, stored)
+	return nil
+}
+
+func (r *IdempotencyRepository) PurgeBefore(ctx context.Context, tx repository.Tx, cutoff time.Time, limit int) (int, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired []models.RequestKey
+	for key, stored := range l.idempotencyKeys.all() {
+		if len(expired) == limit {
+			break
+		}
+		if stored.CreatedAt.Before(cutoff) {
+			expired = append(expired, key)
+		}
+	}
+
+	for _, key := range expired {
+		l.idempotencyKeys.delete(key)
+	}
+	return len(expired), nil
+}
+
+func cloneIdempotencyKey(k models.IdempotencyKey) *models.IdempotencyKey {
+	k.TransactionID = clonePtr(k.TransactionID)
+	k.AccountID = clonePtr(k.AccountID)
+	return &k
+}
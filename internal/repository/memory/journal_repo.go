@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type JournalRepository struct {
+	store *Store
+}
+
+var _ repository.JournalRepository = (*JournalRepository)(nil)
+
+func NewJournalRepository(store *Store) *JournalRepository {
+	return &JournalRepository{store: store}
+}
+
+func (r *JournalRepository) Post(ctx context.Context, tx repository.Tx, entry *models.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	if l.entries.has(entry.ID) {
+		return errDuplicateID
+	}
+	if entry.TransactionID != nil {
+		if !l.transactions.has(*entry.TransactionID) {
+			return errUnknownTransaction
+		}
+	}
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now()
+	}
+
+	for i := range entry.Postings {
+		posting := &entry.Postings[i]
+		posting.ID = int64(len(l.postings)) + 1
+		posting.EntryID = entry.ID
+		posting.CreatedAt = entry.CreatedAt
+
+		if posting.AccountID != nil {
+			if !l.accounts.has(*posting.AccountID) {
+				return models.ErrAccountNotFound
+			}
+
+			// The limit is checked before posting; this is the backstop
+			// should the two ever disagree. Pending credits are not
+			// counted until they are settled.
+			err := l.updateAccount(*posting.AccountID, func(account *models.Account) error {
+				if posting.Deferred {
+					account.PendingCredits += posting.Amount
+					return nil
+				}
+
+				account.Balance += posting.Amount
+				if account.OverdraftLimit != nil && account.Balance < -*account.OverdraftLimit {
+					return models.ErrInsufficientFunds
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if posting.AccountID != nil {
+			indexes, _ := l.accountPostings.get(*posting.AccountID)
+			l.accountPostings.set(*posting.AccountID, append(indexes, len(l.postings)))
+		}
+		l.postings = append(l.postings, clonePosting(*posting))
+	}
+
+	stored := *entry
+	stored.TransactionID = clonePtr(entry.TransactionID)
+	stored.Postings = nil
+	l.entries.set(stored.ID, stored)
+	return nil
+}
+
+func (r *JournalRepository) BalanceAt(ctx context.Context, accountID int64, asOf time.Time) (int64, error) {
+	l := r.store.read()
+
+	var balance int64
+	var since time.Time
+	snapshots, _ := l.snapshots.get(accountID)
+	for _, snapshot := range snapshots {
+		if snapshot.AsOf.After(asOf) {
+			break
+		}
+		balance, since = snapshot.Balance, snapshot.AsOf
+	}
+
+	for posting := range l.postingsOf(accountID) {
+		if posting.CreatedAt.After(since) && !posting.CreatedAt.After(asOf) {
+			balance += posting.Amount
+		}
+	}
+
+	return balance, nil
+}
+
+func (r *JournalRepository) ListPostings(ctx context.Context, filter *models.PostingFilter) ([]models.StatementEntry, error) {
+	l := r.store.read()
+
+	entries := []models.StatementEntry{}
+	for posting := range l.postingsOf(filter.AccountID) {
+		if posting.CreatedAt.Before(filter.From) || posting.CreatedAt.After(filter.To) {
+			continue
+		}
+		if filter.Cursor != nil && comparePosting(posting, filter.Cursor) <= 0 {
+			continue
+		}
+
+		entry, _ := l.entries.get(posting.EntryID)
+		entries = append(entries, models.StatementEntry{
+			PostingID:     posting.ID,
+			EntryID:       posting.EntryID,
+			TransactionID: clonePtr(entry.TransactionID),
+			Kind:          entry.Kind,
+			Amount:        posting.Amount,
+			PostedAt:      posting.CreatedAt,
+		})
+	}
+
+	slices.SortFunc(entries, func(a, b models.StatementEntry) int {
+		return cmp.Or(a.PostedAt.Compare(b.PostedAt), cmp.Compare(a.PostingID, b.PostingID))
+	})
+	return entries[:min(filter.Limit, len(entries))], nil
+}
+
+// comparePosting orders postings by (created_at, id), the keyset of an
+// account's statement.
+func comparePosting(p *models.Posting, cursor *models.PostingCursor) int {
+	return cmp.Or(p.CreatedAt.Compare(cursor.PostedAt), cmp.Compare(p.ID, cursor.PostingID))
+}
+
+func clonePosting(p models.Posting) models.Posting {
+	p.AccountID = clonePtr(p.AccountID)
+	p.SystemAccount = clonePtr(p.SystemAccount)
+	return p
+}
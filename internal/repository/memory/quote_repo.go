@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type QuoteRepository struct {
+	store *Store
+}
+
+var _ repository.QuoteRepository = (*QuoteRepository)(nil)
+
+func NewQuoteRepository(store *Store) *QuoteRepository {
+	return &QuoteRepository{store: store}
+}
+
+func (r *QuoteRepository) Create(ctx context.Context, tx repository.Tx, quote *models.FXQuote) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	if l.quotes.has(quote.ID) {
+		return errDuplicateID
+	}
+
+	quote.CreatedAt = now()
+	l.quotes.set(quote.ID, *quote)
+	return nil
+}
+
+func (r *QuoteRepository) GetByID(ctx context.Context, id string) (*models.FXQuote, error) {
+	quote, ok := r.store.read().quotes.get(id)
+	if !ok {
+		return nil, models.ErrQuoteNotFound
+	}
+	return &quote, nil
+}
@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type ReconciliationRepository struct {
+	store *Store
+}
+
+var _ repository.ReconciliationRepository = (*ReconciliationRepository)(nil)
+
+func NewReconciliationRepository(store *Store) *ReconciliationRepository {
+	return &ReconciliationRepository{store: store}
+}
+
+func (r *ReconciliationRepository) CountAccounts(ctx context.Context, tx repository.Tx) (int, error) {
+	l, err := r.store.reading(tx)
+	if err != nil {
+		return 0, err
+	}
+	return l.accounts.len(), nil
+}
+
+func (r *ReconciliationRepository) AccountDrift(ctx context.Context, tx repository.Tx) ([]models.AccountDrift, error) {
+	l, err := r.store.reading(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := map[int64]int64{}
+	for _, posting := range l.postings {
+		if posting.AccountID != nil {
+			balances[*posting.AccountID] += posting.Amount
+		}
+	}
+
+	holds := map[int64]int64{}
+	for _, t := range l.transactions.all() {
+		if t.Status == models.StatusPending {
+			holds[t.SourceAccountID] += t.AuthorizedAmount
+		}
+	}
+
+	var drift []models.AccountDrift
+	for id, account := range l.accounts.all() {
+		if account.LedgerBalance() != balances[id] || account.HeldBalance != holds[id] {
+			drift = append(drift, models.AccountDrift{
+				AccountID:           id,
+				Currency:            account.Currency,
+				Balance:             account.LedgerBalance(),
+				ExpectedBalance:     balances[id],
+				HeldBalance:         account.HeldBalance,
+				ExpectedHeldBalance: holds[id],
+			})
+		}
+	}
+
+	slices.SortFunc(drift, func(a, b models.AccountDrift) int {
+		return cmp.Compare(a.AccountID, b.AccountID)
+	})
+	return drift, nil
+}
+
+func (r *ReconciliationRepository) UnbalancedEntries(ctx context.Context, tx repository.Tx) ([]models.UnbalancedEntry, error) {
+	l, err := r.store.reading(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	type sumKey struct{ entryID, currency string }
+	sums := map[sumKey]int64{}
+	for _, posting := range l.postings {
+		sums[sumKey{posting.EntryID, posting.Currency}] += posting.Amount
+	}
+
+	var entries []models.UnbalancedEntry
+	for key, sum := range sums {
+		if sum != 0 {
+			entries = append(entries, models.UnbalancedEntry{EntryID: key.entryID, Currency: key.currency, Sum: sum})
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b models.UnbalancedEntry) int {
+		return cmp.Or(cmp.Compare(a.EntryID, b.EntryID), cmp.Compare(a.Currency, b.Currency))
+	})
+	return entries, nil
+}
+
+func (r *ReconciliationRepository) TransactionMismatches(ctx context.Context, tx repository.Tx) ([]models.TransactionMismatch, error) {
+	l, err := r.store.reading(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, entry := range l.entries.all() {
+		if entry.TransactionID != nil {
+			counts[*entry.TransactionID]++
+		}
+	}
+
+	var mismatches []models.TransactionMismatch
+	for id, t := range l.transactions.all() {
+		expected := 1
+		switch t.Status {
+		case models.StatusPending, models.StatusVoided, models.StatusExpired:
+			expected = 0
+		}
+
+		if counts[id] != expected {
+			mismatches = append(mismatches, models.TransactionMismatch{TransactionID: id, Status: t.Status, Entries: counts[id]})
+		}
+	}
+
+	slices.SortFunc(mismatches, func(a, b models.TransactionMismatch) int {
+		return cmp.Compare(a.TransactionID, b.TransactionID)
+	})
+	return mismatches, nil
+}
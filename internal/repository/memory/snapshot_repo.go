@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type SnapshotRepository struct {
+	store *Store
+}
+
+var _ repository.SnapshotRepository = (*SnapshotRepository)(nil)
+
+func NewSnapshotRepository(store *Store) *SnapshotRepository {
+	return &SnapshotRepository{store: store}
+}
+
+func (r *SnapshotRepository) CreateAll(ctx context.Context, tx repository.Tx, asOf time.Time) (int64, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var created int64
+	for id, account := range l.accounts.all() {
+		if account.CreatedAt.After(asOf) {
+			continue
+		}
+
+		snapshots, _ := l.snapshots.get(id)
+		i, found := slices.BinarySearchFunc(snapshots, asOf, func(s models.BalanceSnapshot, t time.Time) int {
+			return s.AsOf.Compare(t)
+		})
+		if found {
+			continue
+		}
+
+		var balance int64
+		var since time.Time
+		if i > 0 {
+			balance, since = snapshots[i-1].Balance, snapshots[i-1].AsOf
+		}
+		for posting := range l.postingsOf(id) {
+			if posting.CreatedAt.After(since) && !posting.CreatedAt.After(asOf) {
+				balance += posting.Amount
+			}
+		}
+
+		// Insert into a copy, which the ledgers sharing the old slice
+		// never see.
+		l.snapshots.set(id, slices.Insert(slices.Clip(snapshots), i, models.BalanceSnapshot{
+			AccountID: id,
+			AsOf:      asOf,
+			Balance:   balance,
+			CreatedAt: now(),
+		}))
+		created++
+	}
+	return created, nil
+}
+
+func (r *SnapshotRepository) LatestAsOf(ctx context.Context) (*time.Time, error) {
+	var latest *time.Time
+	for _, snapshots := range r.store.read().snapshots.all() {
+		if last := snapshots[len(snapshots)-1].AsOf; latest == nil || last.After(*latest) {
+			latest = &last
+		}
+	}
+	return latest, nil
+}
+
+func (r *SnapshotRepository) FirstPostingAt(ctx context.Context) (*time.Time, error) {
+	for _, posting := range r.store.read().postings {
+		if posting.AccountID != nil {
+			createdAt := posting.CreatedAt
+			return &createdAt, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,248 @@
+// Package memory implements the repositories on a ledger kept in process. It
+// behaves like the Postgres store, constraints included, so services can run
+// without a database. Transactions copy only the records they change.
+package memory
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync/atomic"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+var (
+	errTxDone      = errors.New("transaction has already been committed or rolled back")
+	errReadOnly    = errors.New("cannot write in a read-only transaction")
+	errForeignTx   = errors.New("transaction belongs to another store")
+	errNoSavepoint = errors.New("savepoint does not exist")
+)
+
+// Violations of the constraints the Postgres schema enforces.
+var (
+	errUnknownAccount     = errors.New("account does not exist")
+	errUnknownTransaction = errors.New("transaction does not exist")
+	errNegativeHold       = errors.New("held balance can not be negative")
+	errOverdraftLimit     = errors.New("balance is below the overdraft limit")
+	errDuplicateID        = errors.New("a record with this ID already exists")
+)
+
+// Store holds one ledger. Transactions that write are serialized: each works
+// on its own version of the ledger, which replaces the committed one when it
+// commits. Reads outside a transaction and read-only transactions use the
+// last committed ledger, so they never wait for a writer and never see its
+// uncommitted changes.
+type Store struct {
+	// writer is held by the one open write transaction.
+	writer    chan struct{}
+	committed atomic.Pointer[ledger]
+}
+
+var _ repository.UnitOfWork = (*Store)(nil)
+
+func NewStore() *Store {
+	s := &Store{writer: make(chan struct{}, 1)}
+	s.committed.Store(&ledger{})
+	return s
+}
+
+// Begin starts a transaction. A write transaction waits until the one before
+// it has ended, or ctx is done.
+func (s *Store) Begin(ctx context.Context, opts repository.TxOptions) (repository.Tx, error) {
+	if opts.ReadOnly {
+		return &Tx{store: s, ledger: s.committed.Load(), readOnly: true}, nil
+	}
+
+	select {
+	case s.writer <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return &Tx{store: s, ledger: s.committed.Load().clone()}, nil
+}
+
+// Retryable reports false: write transactions run one at a time, so none is
+// ever aborted by another.
+func (s *Store) Retryable(err error) bool {
+	return false
+}
+
+// read returns the committed ledger. It must not be modified.
+func (s *Store) read() *ledger {
+	return s.committed.Load()
+}
+
+// reading returns the ledger tx reads from.
+func (s *Store) reading(tx repository.Tx) (*ledger, error) {
+	t, ok := tx.(*Tx)
+	if !ok || t.store != s {
+		return nil, errForeignTx
+	}
+	if t.done {
+		return nil, errTxDone
+	}
+	return t.ledger, nil
+}
+
+// writing returns the ledger tx writes to.
+func (s *Store) writing(tx repository.Tx) (*ledger, error) {
+	l, err := s.reading(tx)
+	if err != nil {
+		return nil, err
+	}
+	if tx.(*Tx).readOnly {
+		return nil, errReadOnly
+	}
+	return l, nil
+}
+
+// Tx is a transaction on a Store. Like a database transaction, it must only
+// be used by one goroutine at a time.
+type Tx struct {
+	store      *Store
+	ledger     *ledger
+	readOnly   bool
+	done       bool
+	savepoints []savepoint
+}
+
+type savepoint struct {
+	name   string
+	ledger *ledger
+}
+
+func (t *Tx) Commit() error {
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+
+	if !t.readOnly {
+		t.ledger.freeze()
+		t.ledger.compact()
+		t.store.committed.Store(t.ledger)
+		<-t.store.writer
+	}
+	return nil
+}
+
+func (t *Tx) Rollback() error {
+	if t.done {
+		return errTxDone
+	}
+	t.done = true
+
+	if !t.readOnly {
+		<-t.store.writer
+	}
+	return nil
+}
+
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	if t.done {
+		return errTxDone
+	}
+	t.ledger.freeze()
+	t.savepoints = append(t.savepoints, savepoint{name: name, ledger: t.ledger.clone()})
+	return nil
+}
+
+// RollbackToSavepoint undoes everything since the latest savepoint with the
+// given name, which is kept, and forgets the savepoints made after it.
+func (t *Tx) RollbackToSavepoint(ctx context.Context, name string) error {
+	if t.done {
+		return errTxDone
+	}
+
+	for i := len(t.savepoints) - 1; i >= 0; i-- {
+		if t.savepoints[i].name == name {
+			t.ledger = t.savepoints[i].ledger.clone()
+			t.savepoints = t.savepoints[:i+1]
+			return nil
+		}
+	}
+	return errNoSavepoint
+}
+
+// ledger is the whole state of a store. Its tables hold copies, never
+// pointers shared with callers. A clone shares the frozen layers of its
+// tables and the arrays of its slices: slices are only appended to, by the
+// one open write transaction, past the length of any version that a reader
+// or savepoint can still see.
+type ledger struct {
+	accounts      table[int64, models.Account]
+	statusChanges []models.AccountStatusChange
+	transactions  table[string, models.Transaction]
+	entries       table[string, models.JournalEntry]
+	postings      []models.Posting
+	// accountPostings indexes postings by account, oldest first.
+	accountPostings table[int64, []int]
+	chainHeads      table[int64, chainLink]
+	quotes          table[string, models.FXQuote]
+	idempotencyKeys table[models.RequestKey, models.IdempotencyKey]
+	snapshots       table[int64, []models.BalanceSnapshot]
+}
+
+type chainLink struct {
+	seq  int64
+	hash string
+}
+
+// clone returns a version of a frozen ledger to write to.
+func (l *ledger) clone() *ledger {
+	c := *l
+	return &c
+}
+
+// freeze freezes the top layers of the ledger's tables, so clones taken
+// after it do not see its later writes.
+func (l *ledger) freeze() {
+	l.accounts.freeze()
+	l.transactions.freeze()
+	l.entries.freeze()
+	l.accountPostings.freeze()
+	l.chainHeads.freeze()
+	l.quotes.freeze()
+	l.idempotencyKeys.freeze()
+	l.snapshots.freeze()
+}
+
+func (l *ledger) compact() {
+	l.accounts.compact()
+	l.transactions.compact()
+	l.entries.compact()
+	l.accountPostings.compact()
+	l.chainHeads.compact()
+	l.quotes.compact()
+	l.idempotencyKeys.compact()
+	l.snapshots.compact()
+}
+
+// postingsOf returns the account's postings, oldest first.
+func (l *ledger) postingsOf(accountID int64) iter.Seq[*models.Posting] {
+	return func(yield func(*models.Posting) bool) {
+		indexes, _ := l.accountPostings.get(accountID)
+		for _, i := range indexes {
+			if !yield(&l.postings[i]) {
+				return
+			}
+		}
+	}
+}
+
+// now returns the current time at the precision Postgres stores.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
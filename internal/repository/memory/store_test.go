@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_CommitAndRollback(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	accounts := NewAccountRepository(store)
+
+	tx, err := store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: 1, Currency: "USD"}))
+
+	// Uncommitted writes are only visible through the transaction.
+	_, err = accounts.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, models.ErrAccountNotFound)
+	_, err = accounts.GetForUpdate(ctx, tx, 1)
+	assert.NoError(t, err)
+
+	require.NoError(t, tx.Rollback())
+	_, err = accounts.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, models.ErrAccountNotFound)
+
+	tx, err = store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: 1, Currency: "USD"}))
+	require.NoError(t, tx.Commit())
+	assert.ErrorIs(t, tx.Rollback(), errTxDone)
+
+	account, err := accounts.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.AccountStatusActive, account.Status)
+}
+
+func TestStore_Savepoint(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	accounts := NewAccountRepository(store)
+
+	tx, err := store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: 1, Currency: "USD"}))
+	require.NoError(t, tx.Savepoint(ctx, "work"))
+	require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: 2, Currency: "USD"}))
+	require.NoError(t, accounts.AdjustHold(ctx, tx, 1, 100))
+
+	require.NoError(t, tx.RollbackToSavepoint(ctx, "work"))
+	_, err = accounts.GetForUpdate(ctx, tx, 2)
+	assert.ErrorIs(t, err, models.ErrAccountNotFound)
+	account, err := accounts.GetForUpdate(ctx, tx, 1)
+	require.NoError(t, err)
+	assert.Zero(t, account.HeldBalance)
+
+	// The savepoint survives being rolled back to.
+	require.NoError(t, tx.RollbackToSavepoint(ctx, "work"))
+	assert.ErrorIs(t, tx.RollbackToSavepoint(ctx, "other"), errNoSavepoint)
+}
+
+func TestStore_WritersAreSerialized(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+
+	tx, err := store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+
+	// A read-only transaction does not wait for the writer.
+	readOnly, err := store.Begin(ctx, repository.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	assert.ErrorIs(t, NewAccountRepository(store).Create(ctx, readOnly, &models.Account{ID: 1}), errReadOnly)
+	require.NoError(t, readOnly.Rollback())
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = store.Begin(waitCtx, repository.TxOptions{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, tx.Rollback())
+	tx, err = store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+}
+
+func TestJournal_Post(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	accounts := NewAccountRepository(store)
+	journal := NewJournalRepository(store)
+
+	limit := int64(500)
+	tx, err := store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+	require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: 1, Currency: "USD", OverdraftLimit: &limit}))
+
+	entry := &models.JournalEntry{
+		ID:   "entry-1",
+		Kind: models.EntryKindTransfer,
+		Postings: []models.Posting{
+			models.AccountPosting(1, -500, "USD"),
+			models.SystemPosting(models.SystemAccountOpeningBalances, 500, "USD"),
+		},
+	}
+	require.NoError(t, journal.Post(ctx, tx, entry))
+
+	overdrawn := &models.JournalEntry{
+		ID:   "entry-2",
+		Kind: models.EntryKindTransfer,
+		Postings: []models.Posting{
+			models.AccountPosting(1, -1, "USD"),
+			models.SystemPosting(models.SystemAccountOpeningBalances, 1, "USD"),
+		},
+	}
+	assert.ErrorIs(t, journal.Post(ctx, tx, overdrawn), models.ErrInsufficientFunds)
+	require.NoError(t, tx.Commit())
+
+	balance, err := journal.BalanceAt(ctx, 1, entry.CreatedAt)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-500), balance)
+
+	balance, err = journal.BalanceAt(ctx, 1, entry.CreatedAt.Add(-time.Microsecond))
+	require.NoError(t, err)
+	assert.Zero(t, balance)
+}
+
+func TestStore_ReadOnlyTxIsASnapshot(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	accounts := NewAccountRepository(store)
+	reconciliation := NewReconciliationRepository(store)
+
+	create := func(id int64) {
+		tx, err := store.Begin(ctx, repository.TxOptions{})
+		require.NoError(t, err)
+		require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: id, Currency: "USD"}))
+		require.NoError(t, tx.Commit())
+	}
+
+	create(1)
+	readOnly, err := store.Begin(ctx, repository.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	defer readOnly.Rollback()
+
+	// Readers run alongside the writers that commit after them.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for id := int64(2); id <= 200; id++ {
+			create(id)
+		}
+	}()
+	for range 100 {
+		count, err := reconciliation.CountAccounts(ctx, readOnly)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		_, err = accounts.ListUnsettled(ctx, 0, 10)
+		require.NoError(t, err)
+	}
+	<-done
+
+	tx, err := store.Begin(ctx, repository.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	count, err := reconciliation.CountAccounts(ctx, tx)
+	require.NoError(t, err)
+	assert.Equal(t, 200, count)
+	require.NoError(t, tx.Rollback())
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"iter"
+	"slices"
+)
+
+// table is a map that a transaction can change without copying it. Its
+// entries are kept in layers: the frozen ones, oldest first, are shared with
+// every ledger built on them and never written again, and a ledger's own
+// writes go to its top layer. Freezing the top layer takes a snapshot of the
+// table in time proportional to its number of layers, and compact keeps that
+// number logarithmic in the table's size.
+type table[K comparable, V any] struct {
+	layers []map[K]cell[V]
+	top    map[K]cell[V]
+	// n is the number of entries, deleted ones excluded.
+	n int
+}
+
+// cell is an entry of a layer. A deleted cell hides the entries with the same
+// key in the layers below it.
+type cell[V any] struct {
+	value   V
+	deleted bool
+}
+
+func (t *table[K, V]) get(key K) (V, bool) {
+	if c, ok := t.top[key]; ok {
+		return c.value, !c.deleted
+	}
+	for i := len(t.layers) - 1; i >= 0; i-- {
+		if c, ok := t.layers[i][key]; ok {
+			return c.value, !c.deleted
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+func (t *table[K, V]) has(key K) bool {
+	_, ok := t.get(key)
+	return ok
+}
+
+func (t *table[K, V]) set(key K, value V) {
+	if !t.has(key) {
+		t.n++
+	}
+	if t.top == nil {
+		t.top = map[K]cell[V]{}
+	}
+	t.top[key] = cell[V]{value: value}
+}
+
+func (t *table[K, V]) delete(key K) {
+	if !t.has(key) {
+		return
+	}
+	t.n--
+	if t.top == nil {
+		t.top = map[K]cell[V]{}
+	}
+	t.top[key] = cell[V]{deleted: true}
+}
+
+func (t *table[K, V]) len() int {
+	return t.n
+}
+
+// all yields every entry once, in no particular order.
+func (t *table[K, V]) all() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		layers := t.layers
+		if len(t.top) > 0 {
+			layers = append(slices.Clip(layers), t.top)
+		}
+
+		// A single layer has no entries hidden by another.
+		if len(layers) == 1 {
+			for key, c := range layers[0] {
+				if !c.deleted && !yield(key, c.value) {
+					return
+				}
+			}
+			return
+		}
+
+		seen := make(map[K]struct{}, t.n)
+		for i := len(layers) - 1; i >= 0; i-- {
+			for key, c := range layers[i] {
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				if !c.deleted && !yield(key, c.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// freeze makes the top layer part of the shared ones. A copy of the table
+// taken after it is a snapshot that later writes do not change.
+func (t *table[K, V]) freeze() {
+	if len(t.top) > 0 {
+		t.layers = append(slices.Clip(t.layers), t.top)
+	}
+	t.top = nil
+}
+
+// compact merges the newest frozen layers while the newer of two is at least
+// half the size of the older, so each layer is at most half the one below
+// it. Merging builds a new map, as snapshots may still read the old ones;
+// each entry is merged O(log n) times over the table's life.
+func (t *table[K, V]) compact() {
+	for len(t.layers) >= 2 {
+		newer, older := t.layers[len(t.layers)-1], t.layers[len(t.layers)-2]
+		if 2*len(newer) < len(older) {
+			return
+		}
+
+		bottom := len(t.layers) == 2
+		merged := make(map[K]cell[V], len(older)+len(newer))
+		for _, layer := range []map[K]cell[V]{older, newer} {
+			for key, c := range layer {
+				if c.deleted && bottom {
+					delete(merged, key)
+					continue
+				}
+				merged[key] = c
+			}
+		}
+
+		t.layers = append(slices.Clip(t.layers[:len(t.layers)-2]), merged)
+	}
+}
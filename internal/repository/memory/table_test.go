@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_Snapshots(t *testing.T) {
+	var tbl table[int, string]
+	tbl.set(1, "a")
+	tbl.set(2, "b")
+	tbl.freeze()
+	snapshot := tbl
+
+	tbl.set(1, "A")
+	tbl.delete(2)
+	tbl.set(3, "c")
+	tbl.delete(4)
+
+	value, ok := tbl.get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "A", value)
+	assert.False(t, tbl.has(2))
+	assert.Equal(t, 2, tbl.len())
+	assert.Equal(t, map[int]string{1: "A", 3: "c"}, maps.Collect(tbl.all()))
+
+	// The snapshot still sees the table as it was frozen.
+	assert.Equal(t, map[int]string{1: "a", 2: "b"}, maps.Collect(snapshot.all()))
+	assert.Equal(t, 2, snapshot.len())
+}
+
+func TestTable_Compact(t *testing.T) {
+	var tbl table[int, int]
+	for i := range 1000 {
+		tbl.set(i, i)
+		if i%3 == 0 {
+			tbl.delete(i - 1)
+		}
+		tbl.freeze()
+		tbl.compact()
+		require.LessOrEqual(t, len(tbl.layers), 11, "layers after %d writes", i+1)
+	}
+
+	for i, layer := range tbl.layers[1:] {
+		assert.Less(t, 2*len(layer), len(tbl.layers[i]))
+	}
+
+	collected := maps.Collect(tbl.all())
+	assert.Len(t, collected, tbl.len())
+	assert.Equal(t, 997, collected[997])
+	assert.NotContains(t, collected, 998)
+}
@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type TransactionRepository struct {
+	store *Store
+}
+
+var _ repository.TransactionRepository = (*TransactionRepository)(nil)
+
+func NewTransactionRepository(store *Store) *TransactionRepository {
+	return &TransactionRepository{store: store}
+}
+
+func (r *TransactionRepository) Create(ctx context.Context, tx repository.Tx, transaction *models.Transaction) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	if l.transactions.has(transaction.ID) {
+		return errDuplicateID
+	}
+	if !transaction.IsMultiLeg() {
+		for _, id := range []int64{transaction.SourceAccountID, transaction.DestinationAccountID} {
+			if !l.accounts.has(id) {
+				return errUnknownAccount
+			}
+		}
+	}
+	if transaction.ReversalOf != nil {
+		if !l.transactions.has(*transaction.ReversalOf) {
+			return errUnknownTransaction
+		}
+	}
+
+	transaction.CreatedAt = now()
+
+	stored := *cloneTransaction(*transaction)
+	stored.ReversedAmount = 0
+	stored.ChainAccountID, stored.ChainSeq, stored.PrevHash, stored.Hash = 0, 0, "", ""
+	stored.SettlementHash = ""
+	l.transactions.set(stored.ID, stored)
+	return nil
+}
+
+func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*models.Transaction, error) {
+	return getTransaction(r.store.read(), id)
+}
+
+// GetForUpdate needs no lock of its own: write transactions already run one
+// at a time.
+func (r *TransactionRepository) GetForUpdate(ctx context.Context, tx repository.Tx, id string) (*models.Transaction, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return nil, err
+	}
+	return getTransaction(l, id)
+}
+
+func getTransaction(l *ledger, id string) (*models.Transaction, error) {
+	transaction, ok := l.transactions.get(id)
+	if !ok {
+		return nil, models.ErrTransactionNotFound
+	}
+	return cloneTransaction(transaction), nil
+}
+
+func (r *TransactionRepository) Update(ctx context.Context, tx repository.Tx, transaction *models.Transaction) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	stored, ok := l.transactions.get(transaction.ID)
+	if !ok {
+		return nil
+	}
+
+	stored.Amount = transaction.Amount
+	stored.DestinationAmount = transaction.DestinationAmount
+	stored.Status = transaction.Status
+	stored.ReversedAmount = transaction.ReversedAmount
+	stored.SettlementHash = transaction.SettlementHash
+	l.transactions.set(stored.ID, stored)
+	return nil
+}
+
+func (r *TransactionRepository) ChainHead(ctx context.Context, tx repository.Tx, accountID int64) (int64, string, error) {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return 0, "", err
+	}
+
+	head, ok := l.chainHeads.get(accountID)
+	if !ok {
+		return 0, models.GenesisHash, nil
+	}
+	return head.seq, head.hash, nil
+}
+
+func (r *TransactionRepository) Seal(ctx context.Context, tx repository.Tx, transaction *models.Transaction) error {
+	l, err := r.store.writing(tx)
+	if err != nil {
+		return err
+	}
+
+	stored, ok := l.transactions.get(transaction.ID)
+	if !ok || stored.Hash != "" {
+		return models.ErrTransactionNotFound
+	}
+
+	stored.ChainAccountID = transaction.ChainAccountID
+	stored.ChainSeq = transaction.ChainSeq
+	stored.PrevHash = transaction.PrevHash
+	stored.Hash = transaction.Hash
+	l.transactions.set(stored.ID, stored)
+
+	if head, _ := l.chainHeads.get(stored.ChainAccountID); stored.ChainSeq > head.seq {
+		l.chainHeads.set(stored.ChainAccountID, chainLink{seq: stored.ChainSeq, hash: stored.Hash})
+	}
+	return nil
+}
+
+func (r *TransactionRepository) ListChainAccounts(ctx context.Context) ([]int64, error) {
+	l := r.store.read()
+
+	var ids []int64
+	for id := range l.chainHeads.all() {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+func (r *TransactionRepository) ListChain(ctx context.Context, accountID, afterSeq int64, limit int) ([]models.Transaction, error) {
+	transactions := r.filter(func(t *models.Transaction) bool {
+		return t.ChainAccountID == accountID && t.ChainSeq > afterSeq
+	})
+	slices.SortFunc(transactions, func(a, b models.Transaction) int {
+		return cmp.Compare(a.ChainSeq, b.ChainSeq)
+	})
+	return transactions[:min(limit, len(transactions))], nil
+}
+
+func (r *TransactionRepository) ListUnsealed(ctx context.Context, after time.Time) ([]models.Transaction, error) {
+	transactions := r.filter(func(t *models.Transaction) bool {
+		return t.Hash == "" && t.CreatedAt.After(after)
+	})
+	slices.SortFunc(transactions, func(a, b models.Transaction) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return transactions, nil
+}
+
+func (r *TransactionRepository) FirstSealedAt(ctx context.Context) (*time.Time, error) {
+	var first *time.Time
+	for _, t := range r.store.read().transactions.all() {
+		if t.Hash != "" && (first == nil || t.CreatedAt.Before(*first)) {
+			createdAt := t.CreatedAt
+			first = &createdAt
+		}
+	}
+	return first, nil
+}
+
+func (r *TransactionRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	holds := r.filter(func(t *models.Transaction) bool {
+		return t.Status == models.StatusPending && t.HoldExpiresAt != nil && !t.HoldExpiresAt.After(now)
+	})
+	slices.SortFunc(holds, func(a, b models.Transaction) int {
+		return a.HoldExpiresAt.Compare(*b.HoldExpiresAt)
+	})
+
+	var ids []string
+	for _, t := range holds[:min(limit, len(holds))] {
+		ids = append(ids, t.ID)
+	}
+	return ids, nil
+}
+
+// ListByAccount finds the account among the source and destination of simple
+// transfers and among the legs of multi-leg ones.
+func (r *TransactionRepository) ListByAccount(ctx context.Context, filter *models.TransactionFilter) ([]models.AccountTransaction, error) {
+	transactions := []models.AccountTransaction{}
+
+	add := func(t *models.Transaction, direction string, amount int64) {
+		if filter.Direction != "" && direction != filter.Direction {
+			return
+		}
+		if (filter.MinAmount != nil && amount < *filter.MinAmount) || (filter.MaxAmount != nil && amount > *filter.MaxAmount) {
+			return
+		}
+		if (filter.From != nil && t.CreatedAt.Before(*filter.From)) || (filter.To != nil && !t.CreatedAt.Before(*filter.To)) {
+			return
+		}
+		if filter.Cursor != nil && compareHistory(t, filter.Cursor.CreatedAt, filter.Cursor.ID) >= 0 {
+			return
+		}
+		transactions = append(transactions, models.AccountTransaction{
+			Transaction:   *cloneTransaction(*t),
+			Direction:     direction,
+			AccountAmount: amount,
+		})
+	}
+
+	for _, t := range r.store.read().transactions.all() {
+		if t.SourceAccountID == filter.AccountID {
+			add(&t, models.DirectionDebit, t.Amount)
+		}
+		if t.DestinationAccountID == filter.AccountID {
+			add(&t, models.DirectionCredit, t.DestinationAmount)
+		}
+		for _, leg := range t.Sources {
+			if leg.AccountID == filter.AccountID {
+				add(&t, models.DirectionDebit, leg.Amount)
+			}
+		}
+		for _, leg := range t.Destinations {
+			if leg.AccountID == filter.AccountID {
+				add(&t, models.DirectionCredit, leg.Amount)
+			}
+		}
+	}
+
+	slices.SortFunc(transactions, func(a, b models.AccountTransaction) int {
+		return -compareHistory(&a.Transaction, b.CreatedAt, b.ID)
+	})
+	return transactions[:min(filter.Limit, len(transactions))], nil
+}
+
+// compareHistory orders transactions by (created_at, id), the keyset of an
+// account's history.
+func compareHistory(t *models.Transaction, createdAt time.Time, id string) int {
+	return cmp.Or(t.CreatedAt.Compare(createdAt), cmp.Compare(t.ID, id))
+}
+
+// filter returns copies of the committed transactions that match.
+func (r *TransactionRepository) filter(match func(t *models.Transaction) bool) []models.Transaction {
+	var transactions []models.Transaction
+	for _, t := range r.store.read().transactions.all() {
+		if match(&t) {
+			transactions = append(transactions, *cloneTransaction(t))
+		}
+	}
+	return transactions
+}
+
+func cloneTransaction(t models.Transaction) *models.Transaction {
+	t.FXRate = clonePtr(t.FXRate)
+	t.FXQuoteID = clonePtr(t.FXQuoteID)
+	t.ReversalOf = clonePtr(t.ReversalOf)
+	t.HoldExpiresAt = clonePtr(t.HoldExpiresAt)
+	t.Sources = slices.Clone(t.Sources)
+	t.Destinations = slices.Clone(t.Destinations)
+	return &t
+}
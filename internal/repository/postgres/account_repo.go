@@ -0,0 +1,398 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/lib/pq"
+)
+
+type AccountRepository struct {
+	db *sql.DB
+}
+
+var _ repository.AccountRepository = (*AccountRepository)(nil)
+
+func NewAccountRepository(db *sql.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// Create inserts an account with a zero balance. Funds only ever enter an
+// account through journal postings.
+func (r *AccountRepository) Create(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		INSERT INTO accounts (id, balance, currency, overdraft_limit, hot, created_at)
+		VALUES ($1, 0, $2, $3, $4, NOW())
+	`
+
+	_, err := sqlTx(tx).ExecContext(ctx, query, account.ID, account.Currency, account.OverdraftLimit, account.Hot)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return models.ErrAccountExists
+		}
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+
+	return nil
+}
+
+// Import loads the accounts with COPY into a temporary table and creates
+// those that do not exist yet from it in one statement.
+func (r *AccountRepository) Import(ctx context.Context, tx repository.Tx, accounts []models.Account) ([]int64, error) {
+	stagingQuery := `
+		CREATE TEMPORARY TABLE account_import (
+			id BIGINT NOT NULL,
+			currency CHAR(3) NOT NULL,
+			overdraft_limit BIGINT,
+			hot BOOLEAN NOT NULL
+		) ON COMMIT DROP
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, stagingQuery); err != nil {
+		return nil, fmt.Errorf("failed to create import table: %w", err)
+	}
+
+	stmt, err := sqlTx(tx).PrepareContext(ctx, pq.CopyIn("account_import", "id", "currency", "overdraft_limit", "hot"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start copying accounts: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, account := range accounts {
+		if _, err := stmt.ExecContext(ctx, account.ID, account.Currency, account.OverdraftLimit, account.Hot); err != nil {
+			return nil, fmt.Errorf("failed to copy account: %w", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to copy accounts: %w", err)
+	}
+
+	insertQuery := `
+		INSERT INTO accounts (id, balance, currency, overdraft_limit, hot, created_at)
+		SELECT id, 0, currency, overdraft_limit, hot, NOW()
+		FROM account_import
+		ORDER BY id
+		ON CONFLICT (id) DO NOTHING
+		RETURNING id
+	`
+
+	rows, err := sqlTx(tx).QueryContext(ctx, insertQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var created []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan account ID: %w", err)
+		}
+		created = append(created, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to import accounts: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *AccountRepository) ListOpenings(ctx context.Context, tx repository.Tx, ids []int64) ([]models.AccountOpening, error) {
+	query := `
+		SELECT a.id, a.currency, a.overdraft_limit, a.hot,
+			(SELECT COALESCE(SUM(p.amount), 0)
+			 FROM postings p
+			 JOIN journal_entries e ON e.id = p.entry_id
+			 WHERE p.account_id = a.id AND e.kind = $2)
+		FROM accounts a
+		WHERE a.id = ANY($1)
+		ORDER BY a.id
+	`
+
+	rows, err := sqlTx(tx).QueryContext(ctx, query, pq.Array(ids), models.EntryKindOpeningBalance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account openings: %w", err)
+	}
+	defer rows.Close()
+
+	var openings []models.AccountOpening
+	for rows.Next() {
+		var opening models.AccountOpening
+		account := &opening.Account
+		if err := rows.Scan(&account.ID, &account.Currency, &account.OverdraftLimit, &account.Hot, &opening.Balance); err != nil {
+			return nil, fmt.Errorf("failed to scan account opening: %w", err)
+		}
+		openings = append(openings, opening)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list account openings: %w", err)
+	}
+
+	return openings, nil
+}
+
+const accountColumns = `id, balance, held_balance, overdraft_limit, currency, status, hot, created_at, updated_at`
+
+// scanAccount scans a row of accountColumns and, if pending is set, the sum
+// of the account's pending credits after them.
+func scanAccount(row *sql.Row, pending bool) (*models.Account, error) {
+	var account models.Account
+	dest := []any{
+		&account.ID,
+		&account.Balance,
+		&account.HeldBalance,
+		&account.OverdraftLimit,
+		&account.Currency,
+		&account.Status,
+		&account.Hot,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+	}
+	if pending {
+		dest = append(dest, &account.PendingCredits)
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *AccountRepository) GetByID(ctx context.Context, id int64) (*models.Account, error) {
+	query := `
+		SELECT ` + accountColumns + `,
+			(SELECT COALESCE(SUM(amount), 0) FROM pending_credits WHERE account_id = accounts.id)
+		FROM accounts
+		WHERE id = $1
+	`
+
+	account, err := scanAccount(r.db.QueryRowContext(ctx, query, id), true)
+	if err != nil && !errors.Is(err, models.ErrAccountNotFound) {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return account, err
+}
+
+// GetForUpdate locks the account's row. Pending credits are not read: a
+// statement that waited for the lock would see the row as it is now but
+// pending_credits as it was when the statement began.
+func (r *AccountRepository) GetForUpdate(ctx context.Context, tx repository.Tx, id int64) (*models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = $1 FOR UPDATE`
+
+	account, err := scanAccount(sqlTx(tx).QueryRowContext(ctx, query, id), false)
+	if err != nil && !errors.Is(err, models.ErrAccountNotFound) {
+		return nil, fmt.Errorf("failed to get account for update: %w", err)
+	}
+	return account, err
+}
+
+// GetForCredit locks the account's row FOR SHARE, which conflicts with FOR
+// UPDATE and with the row updates of a settlement, but not with itself.
+func (r *AccountRepository) GetForCredit(ctx context.Context, tx repository.Tx, id int64) (*models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = $1 FOR SHARE`
+
+	account, err := scanAccount(sqlTx(tx).QueryRowContext(ctx, query, id), false)
+	if err != nil && !errors.Is(err, models.ErrAccountNotFound) {
+		return nil, fmt.Errorf("failed to get account for credit: %w", err)
+	}
+	return account, err
+}
+
+// SettleCredits moves the account's pending credits into its balance. The
+// caller must hold the account's row lock.
+func (r *AccountRepository) SettleCredits(ctx context.Context, tx repository.Tx, id int64) (int64, error) {
+	query := `
+		WITH settled AS (
+			DELETE FROM pending_credits
+			WHERE account_id = $1
+			RETURNING amount
+		), total AS (
+			SELECT SUM(amount) AS amount FROM settled
+		)
+		UPDATE accounts
+		SET balance = balance + total.amount, updated_at = NOW()
+		FROM total
+		WHERE id = $1 AND total.amount IS NOT NULL
+		RETURNING total.amount
+	`
+
+	var settled int64
+	err := sqlTx(tx).QueryRowContext(ctx, query, id).Scan(&settled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to settle pending credits: %w", err)
+	}
+
+	return settled, nil
+}
+
+func (r *AccountRepository) ListUnsettled(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	query := `
+		SELECT account_id
+		FROM pending_credits
+		WHERE account_id > $1
+		GROUP BY account_id
+		ORDER BY account_id
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unsettled accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan account ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list unsettled accounts: %w", err)
+	}
+
+	return ids, nil
+}
+
+// AdjustHold adds delta to the funds held on the account; a negative delta
+// releases a hold. The caller must hold the account's row lock.
+func (r *AccountRepository) AdjustHold(ctx context.Context, tx repository.Tx, id int64, delta int64) error {
+	query := `
+		UPDATE accounts
+		SET held_balance = held_balance + $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, id, delta); err != nil {
+		return fmt.Errorf("failed to update held balance: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus stores the account's status. The caller must hold the
+// account's row lock.
+func (r *AccountRepository) UpdateStatus(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		UPDATE accounts
+		SET status = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, account.ID, account.Status); err != nil {
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) CreateStatusChange(ctx context.Context, tx repository.Tx, change *models.AccountStatusChange) error {
+	query := `
+		INSERT INTO account_status_changes (account_id, from_status, to_status, reason, actor)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := sqlTx(tx).QueryRowContext(
+		ctx,
+		query,
+		change.AccountID,
+		change.FromStatus,
+		change.ToStatus,
+		change.Reason,
+		change.Actor,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+
+	return nil
+}
+
+// ListStatusChanges returns the account's status changes, oldest first.
+func (r *AccountRepository) ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error) {
+	query := `
+		SELECT id, account_id, from_status, to_status, reason, actor, created_at
+		FROM account_status_changes
+		WHERE account_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.AccountStatusChange{}
+	for rows.Next() {
+		var change models.AccountStatusChange
+		err := rows.Scan(
+			&change.ID,
+			&change.AccountID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Reason,
+			&change.Actor,
+			&change.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list status changes: %w", err)
+	}
+
+	return changes, nil
+}
+
+// UpdateOverdraftLimit stores the account's overdraft limit. The caller must
+// hold the account's row lock.
+func (r *AccountRepository) UpdateOverdraftLimit(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		UPDATE accounts
+		SET overdraft_limit = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, account.ID, account.OverdraftLimit); err != nil {
+		return fmt.Errorf("failed to update overdraft limit: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateHot stores the account's hot mode. The caller must hold the
+// account's row lock.
+func (r *AccountRepository) UpdateHot(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		UPDATE accounts
+		SET hot = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, account.ID, account.Hot); err != nil {
+		return fmt.Errorf("failed to update hot mode: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// claimAttempts bounds how often Claim retries when the key it waited on
+// disappears before it can be read.
+const claimAttempts = 3
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+var _ repository.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim inserts key in tx, reserving it for the work done in tx. If another
+// transaction holds the key, Claim blocks until it ends: it then returns the
+// key as committed, or claims it in turn if the other transaction rolled
+// back. A nil key means tx now holds it; it must be completed before tx
+// commits.
+func (r *IdempotencyRepository) Claim(ctx context.Context, tx repository.Tx, key models.RequestKey, fingerprint string) (*models.IdempotencyKey, error) {
+	insertQuery := `
+		INSERT INTO idempotency_keys (client_id, key, request_fingerprint)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (client_id, key) DO NOTHING
+	`
+
+	selectQuery := `
+		SELECT client_id, key, COALESCE(request_fingerprint, ''), transaction_id, account_id,
+			COALESCE(error_code, ''), created_at
+		FROM idempotency_keys
+		WHERE client_id = $1 AND key = $2
+	`
+
+	for attempt := 0; attempt < claimAttempts; attempt++ {
+		result, err := sqlTx(tx).ExecContext(ctx, insertQuery, key.ClientID, key.Key, fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if claimed == 1 {
+			return nil, nil
+		}
+
+		var existing models.IdempotencyKey
+		var transactionID sql.NullString
+		var accountID sql.NullInt64
+		err = sqlTx(tx).QueryRowContext(ctx, selectQuery, key.ClientID, key.Key).Scan(
+			&existing.ClientID,
+			&existing.Key,
+			&existing.RequestFingerprint,
+			&transactionID,
+			&accountID,
+			&existing.ErrorCode,
+			&existing.CreatedAt,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+
+		if transactionID.Valid {
+			existing.TransactionID = &transactionID.String
+		}
+		if accountID.Valid {
+			existing.AccountID = &accountID.Int64
+		}
+		return &existing, nil
+	}
+
+	return nil, fmt.Errorf("failed to claim idempotency key %q: removed while waiting for it", key.Key)
+}
+
+// Complete records the outcome of the request that claimed the key in tx:
+// the transaction or account it created, or the code of the error it failed
+// with.
+func (r *IdempotencyRepository) Complete(ctx context.Context, tx repository.Tx, outcome *models.IdempotencyKey) error {
+	query := `
+		UPDATE idempotency_keys
+		SET transaction_id = $3, account_id = $4, error_code = NULLIF($5, '')
+		WHERE client_id = $1 AND key = $2
+	`
+
+	_, err := sqlTx(tx).ExecContext(ctx, query,
+		outcome.ClientID,
+		outcome.Key,
+		outcome.TransactionID,
+		outcome.AccountID,
+		outcome.ErrorCode,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) PurgeBefore(ctx context.Context, tx repository.Tx, cutoff time.Time, limit int) (int, error) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE ctid IN (
+			SELECT ctid FROM idempotency_keys
+			WHERE created_at < $1
+			LIMIT $2
+		)
+	`
+
+	result, err := sqlTx(tx).ExecContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(purged), nil
+}
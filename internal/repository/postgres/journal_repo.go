@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/lib/pq"
+)
+
+type JournalRepository struct {
+	db *sql.DB
+}
+
+var _ repository.JournalRepository = (*JournalRepository)(nil)
+
+func NewJournalRepository(db *sql.DB) *JournalRepository {
+	return &JournalRepository{db: db}
+}
+
+// Post writes a balanced journal entry and folds each account posting into the
+// cached accounts.balance, or into pending_credits if it is deferred. The
+// accounts must already be locked by the caller.
+//
+// The entry and its postings share one timestamp: entry.CreatedAt if set,
+// which must itself have been taken after the locks, or the current time.
+func (r *JournalRepository) Post(ctx context.Context, tx repository.Tx, entry *models.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	entryQuery := `
+		INSERT INTO journal_entries (id, transaction_id, kind, created_at)
+		VALUES ($1, $2, $3, COALESCE($4, clock_timestamp()))
+		RETURNING created_at
+	`
+
+	var createdAt *time.Time
+	if !entry.CreatedAt.IsZero() {
+		createdAt = &entry.CreatedAt
+	}
+
+	err := sqlTx(tx).QueryRowContext(ctx, entryQuery, entry.ID, entry.TransactionID, entry.Kind, createdAt).Scan(&entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	postingQuery := `
+		INSERT INTO postings (entry_id, account_id, system_account, amount, currency, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	balanceQuery := `
+		UPDATE accounts
+		SET balance = balance + $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	pendingQuery := `
+		INSERT INTO pending_credits (posting_id, account_id, amount)
+		VALUES ($1, $2, $3)
+	`
+
+	for i := range entry.Postings {
+		posting := &entry.Postings[i]
+		posting.EntryID = entry.ID
+
+		err := sqlTx(tx).QueryRowContext(
+			ctx,
+			postingQuery,
+			posting.EntryID,
+			posting.AccountID,
+			posting.SystemAccount,
+			posting.Amount,
+			posting.Currency,
+			entry.CreatedAt,
+		).Scan(&posting.ID, &posting.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create posting: %w", err)
+		}
+
+		if posting.AccountID == nil {
+			continue
+		}
+
+		if posting.Deferred {
+			if _, err := sqlTx(tx).ExecContext(ctx, pendingQuery, posting.ID, *posting.AccountID, posting.Amount); err != nil {
+				return fmt.Errorf("failed to record pending credit: %w", err)
+			}
+			continue
+		}
+
+		result, err := sqlTx(tx).ExecContext(ctx, balanceQuery, posting.Amount, *posting.AccountID)
+		if err != nil {
+			// The limit is checked before posting; the constraint is the
+			// backstop should the two ever disagree.
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "within_overdraft_limit" {
+				return models.ErrInsufficientFunds
+			}
+			return fmt.Errorf("failed to update balance: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return models.ErrAccountNotFound
+		}
+	}
+
+	return nil
+}
+
+// BalanceAt returns the account's balance after the postings up to and
+// including asOf. It starts from the nearest balance snapshot at or before
+// asOf and adds the postings after it. Postings are timestamped after the
+// account is locked, so for a single account their order is the order in
+// which the transfers committed.
+func (r *JournalRepository) BalanceAt(ctx context.Context, accountID int64, asOf time.Time) (int64, error) {
+	query := `
+		WITH snapshot AS (
+			SELECT as_of, balance
+			FROM balance_snapshots
+			WHERE account_id = $1 AND as_of <= $2
+			ORDER BY as_of DESC
+			LIMIT 1
+		)
+		SELECT COALESCE((SELECT balance FROM snapshot), 0) + COALESCE((
+			SELECT SUM(amount)
+			FROM postings
+			WHERE account_id = $1
+				AND created_at <= $2
+				AND created_at > COALESCE((SELECT as_of FROM snapshot), '-infinity')
+		), 0)
+	`
+
+	var balance int64
+	if err := r.db.QueryRowContext(ctx, query, accountID, asOf).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("failed to compute balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// ListPostings returns one page of an account's postings between
+// filter.From and filter.To, ordered by (created_at, id).
+func (r *JournalRepository) ListPostings(ctx context.Context, filter *models.PostingFilter) ([]models.StatementEntry, error) {
+	args := []any{filter.AccountID, filter.From, filter.To}
+	conditions := []string{"p.account_id = $1", "p.created_at >= $2", "p.created_at <= $3"}
+
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.PostedAt, filter.Cursor.PostingID)
+		conditions = append(conditions, fmt.Sprintf("(p.created_at, p.id) > ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	query := `
+		SELECT p.id, p.entry_id, e.transaction_id, e.kind, p.amount, p.created_at
+		FROM postings p
+		JOIN journal_entries e ON e.id = p.entry_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY p.created_at, p.id
+		LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list postings: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.StatementEntry{}
+	for rows.Next() {
+		var entry models.StatementEntry
+		err := rows.Scan(
+			&entry.PostingID,
+			&entry.EntryID,
+			&entry.TransactionID,
+			&entry.Kind,
+			&entry.Amount,
+			&entry.PostedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan posting: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list postings: %w", err)
+	}
+
+	return entries, nil
+}
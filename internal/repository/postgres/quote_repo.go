@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type QuoteRepository struct {
+	db *sql.DB
+}
+
+var _ repository.QuoteRepository = (*QuoteRepository)(nil)
+
+func NewQuoteRepository(db *sql.DB) *QuoteRepository {
+	return &QuoteRepository{db: db}
+}
+
+func (r *QuoteRepository) Create(ctx context.Context, tx repository.Tx, quote *models.FXQuote) error {
+	query := `
+		INSERT INTO fx_quotes (id, source_currency, destination_currency, rate, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING created_at
+	`
+
+	err := sqlTx(tx).QueryRowContext(
+		ctx,
+		query,
+		quote.ID,
+		quote.SourceCurrency,
+		quote.DestinationCurrency,
+		string(quote.Rate),
+		quote.ExpiresAt,
+	).Scan(&quote.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create fx quote: %w", err)
+	}
+
+	return nil
+}
+
+func (r *QuoteRepository) GetByID(ctx context.Context, id string) (*models.FXQuote, error) {
+	query := `
+		SELECT id, source_currency, destination_currency, rate, expires_at, created_at
+		FROM fx_quotes
+		WHERE id = $1
+	`
+
+	var quote models.FXQuote
+	var rate string
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&quote.ID,
+		&quote.SourceCurrency,
+		&quote.DestinationCurrency,
+		&rate,
+		&quote.ExpiresAt,
+		&quote.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrQuoteNotFound
+		}
+		return nil, fmt.Errorf("failed to get fx quote: %w", err)
+	}
+
+	quote.Rate = trimNumeric(rate)
+	return &quote, nil
+}
+
+// trimNumeric drops the trailing zeros Postgres pads NUMERIC(p, s) values
+// with, so "151.250000000000" reads back as "151.25".
+func trimNumeric(value string) models.Decimal {
+	if strings.Contains(value, ".") {
+		value = strings.TrimRight(value, "0")
+		value = strings.TrimSuffix(value, ".")
+	}
+	return models.Decimal(value)
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// ReconciliationRepository recomputes the ledger from its journal. Every
+// method scans whole tables; run them in one read-only transaction so they
+// see the same snapshot.
+type ReconciliationRepository struct {
+	db *sql.DB
+}
+
+var _ repository.ReconciliationRepository = (*ReconciliationRepository)(nil)
+
+func NewReconciliationRepository(db *sql.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+func (r *ReconciliationRepository) CountAccounts(ctx context.Context, tx repository.Tx) (int, error) {
+	var count int
+	if err := sqlTx(tx).QueryRowContext(ctx, `SELECT COUNT(*) FROM accounts`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count accounts: %w", err)
+	}
+	return count, nil
+}
+
+// AccountDrift returns the accounts whose balance, counting pending credits,
+// is not the sum of their postings or whose held balance is not the sum of
+// their pending holds.
+func (r *ReconciliationRepository) AccountDrift(ctx context.Context, tx repository.Tx) ([]models.AccountDrift, error) {
+	query := `
+		SELECT a.id, a.currency, a.balance + COALESCE(c.total, 0), COALESCE(p.total, 0), a.held_balance, COALESCE(h.total, 0)
+		FROM accounts a
+		LEFT JOIN (
+			SELECT account_id, SUM(amount) AS total
+			FROM postings
+			WHERE account_id IS NOT NULL
+			GROUP BY account_id
+		) p ON p.account_id = a.id
+		LEFT JOIN (
+			SELECT source_account_id, SUM(authorized_amount) AS total
+			FROM transactions
+			WHERE status = 'PENDING'
+			GROUP BY source_account_id
+		) h ON h.source_account_id = a.id
+		LEFT JOIN (
+			SELECT account_id, SUM(amount) AS total
+			FROM pending_credits
+			GROUP BY account_id
+		) c ON c.account_id = a.id
+		WHERE a.balance + COALESCE(c.total, 0) != COALESCE(p.total, 0) OR a.held_balance != COALESCE(h.total, 0)
+		ORDER BY a.id
+	`
+
+	rows, err := sqlTx(tx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare account balances: %w", err)
+	}
+	defer rows.Close()
+
+	var drift []models.AccountDrift
+	for rows.Next() {
+		var d models.AccountDrift
+		if err := rows.Scan(&d.AccountID, &d.Currency, &d.Balance, &d.ExpectedBalance, &d.HeldBalance, &d.ExpectedHeldBalance); err != nil {
+			return nil, fmt.Errorf("failed to scan account drift: %w", err)
+		}
+		drift = append(drift, d)
+	}
+
+	return drift, rows.Err()
+}
+
+// UnbalancedEntries returns the journal entries whose postings do not sum to
+// zero in some currency.
+func (r *ReconciliationRepository) UnbalancedEntries(ctx context.Context, tx repository.Tx) ([]models.UnbalancedEntry, error) {
+	query := `
+		SELECT entry_id, currency, SUM(amount)
+		FROM postings
+		GROUP BY entry_id, currency
+		HAVING SUM(amount) != 0
+		ORDER BY entry_id, currency
+	`
+
+	rows, err := sqlTx(tx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check journal entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.UnbalancedEntry
+	for rows.Next() {
+		var e models.UnbalancedEntry
+		if err := rows.Scan(&e.EntryID, &e.Currency, &e.Sum); err != nil {
+			return nil, fmt.Errorf("failed to scan unbalanced entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// TransactionMismatches returns the transactions that moved money without a
+// journal entry, have more than one, or have one while holding funds that
+// were never captured.
+func (r *ReconciliationRepository) TransactionMismatches(ctx context.Context, tx repository.Tx) ([]models.TransactionMismatch, error) {
+	query := `
+		SELECT t.id, t.status, COUNT(e.id)
+		FROM transactions t
+		LEFT JOIN journal_entries e ON e.transaction_id = t.id
+		GROUP BY t.id, t.status
+		HAVING COUNT(e.id) != CASE WHEN t.status IN ('PENDING', 'VOIDED', 'EXPIRED') THEN 0 ELSE 1 END
+		ORDER BY t.id
+	`
+
+	rows, err := sqlTx(tx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []models.TransactionMismatch
+	for rows.Next() {
+		var m models.TransactionMismatch
+		if err := rows.Scan(&m.TransactionID, &m.Status, &m.Entries); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction mismatch: %w", err)
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	return mismatches, rows.Err()
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type SnapshotRepository struct {
+	db *sql.DB
+}
+
+var _ repository.SnapshotRepository = (*SnapshotRepository)(nil)
+
+func NewSnapshotRepository(db *sql.DB) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+// CreateAll writes a snapshot at asOf for every account created by then,
+// adding the postings since each account's previous snapshot to its balance.
+// Existing snapshots are left alone, so concurrent or repeated runs are
+// harmless. It returns the number of snapshots written.
+func (r *SnapshotRepository) CreateAll(ctx context.Context, tx repository.Tx, asOf time.Time) (int64, error) {
+	query := `
+		INSERT INTO balance_snapshots (account_id, as_of, balance)
+		SELECT a.id, $1, COALESCE(prev.balance, 0) + COALESCE((
+			SELECT SUM(p.amount)
+			FROM postings p
+			WHERE p.account_id = a.id
+				AND p.created_at <= $1
+				AND p.created_at > COALESCE(prev.as_of, '-infinity')
+		), 0)
+		FROM accounts a
+		LEFT JOIN LATERAL (
+			SELECT s.as_of, s.balance
+			FROM balance_snapshots s
+			WHERE s.account_id = a.id AND s.as_of < $1
+			ORDER BY s.as_of DESC
+			LIMIT 1
+		) prev ON true
+		WHERE a.created_at <= $1
+		ON CONFLICT (account_id, as_of) DO NOTHING
+	`
+
+	result, err := sqlTx(tx).ExecContext(ctx, query, asOf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create balance snapshots: %w", err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return created, nil
+}
+
+// LatestAsOf returns the time of the most recent snapshots, or nil if none
+// have been taken.
+func (r *SnapshotRepository) LatestAsOf(ctx context.Context) (*time.Time, error) {
+	var asOf sql.NullTime
+	if err := r.db.QueryRowContext(ctx, `SELECT MAX(as_of) FROM balance_snapshots`).Scan(&asOf); err != nil {
+		return nil, fmt.Errorf("failed to get latest snapshot: %w", err)
+	}
+
+	if !asOf.Valid {
+		return nil, nil
+	}
+	return &asOf.Time, nil
+}
+
+// FirstPostingAt returns the time of the oldest account posting, or nil if
+// there are none.
+func (r *SnapshotRepository) FirstPostingAt(ctx context.Context) (*time.Time, error) {
+	query := `SELECT MIN(created_at) FROM postings WHERE account_id IS NOT NULL`
+
+	var createdAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query).Scan(&createdAt); err != nil {
+		return nil, fmt.Errorf("failed to get first posting: %w", err)
+	}
+
+	if !createdAt.Valid {
+		return nil, nil
+	}
+	return &createdAt.Time, nil
+}
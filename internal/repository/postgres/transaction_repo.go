@@ -0,0 +1,525 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type TransactionRepository struct {
+	db *sql.DB
+}
+
+var _ repository.TransactionRepository = (*TransactionRepository)(nil)
+
+func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// Create inserts the transaction record. created_at is taken when the row is
+// written, after the caller has locked the accounts, so it follows commit
+// order for every account involved.
+func (r *TransactionRepository) Create(ctx context.Context, tx repository.Tx, transaction *models.Transaction) error {
+	query := `
+		INSERT INTO transactions (
+			id, source_account_id, destination_account_id, amount, currency,
+			destination_amount, destination_currency, fx_rate, fx_quote_id,
+			status, reversal_of, authorized_amount, hold_expires_at, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, clock_timestamp())
+		RETURNING created_at
+	`
+
+	var sourceAccountID, destinationAccountID *int64
+	if !transaction.IsMultiLeg() {
+		sourceAccountID = &transaction.SourceAccountID
+		destinationAccountID = &transaction.DestinationAccountID
+	}
+
+	var authorizedAmount *int64
+	if transaction.AuthorizedAmount > 0 {
+		authorizedAmount = &transaction.AuthorizedAmount
+	}
+
+	var fxRate *string
+	if transaction.FXRate != nil {
+		rate := string(*transaction.FXRate)
+		fxRate = &rate
+	}
+
+	err := sqlTx(tx).QueryRowContext(
+		ctx,
+		query,
+		transaction.ID,
+		sourceAccountID,
+		destinationAccountID,
+		transaction.Amount,
+		transaction.Currency,
+		transaction.DestinationAmount,
+		transaction.DestinationCurrency,
+		fxRate,
+		transaction.FXQuoteID,
+		transaction.Status,
+		transaction.ReversalOf,
+		authorizedAmount,
+		transaction.HoldExpiresAt,
+	).Scan(&transaction.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	return nil
+}
+
+// transactionColumns lists the columns scanTransaction expects, in order.
+// Multi-leg transactions report zero source and destination IDs.
+const transactionColumns = `
+	t.id, COALESCE(t.source_account_id, 0), COALESCE(t.destination_account_id, 0), t.amount, t.currency,
+	COALESCE(t.destination_amount, t.amount), COALESCE(t.destination_currency, t.currency),
+	t.fx_rate, t.fx_quote_id, t.status,
+	t.reversal_of, t.reversed_amount,
+	COALESCE(t.authorized_amount, 0), t.hold_expires_at,
+	COALESCE(t.chain_account_id, 0), COALESCE(t.chain_seq, 0), COALESCE(t.prev_hash, ''), COALESCE(t.hash, ''),
+	COALESCE(t.settlement_hash, ''), t.created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func scanTransaction(row rowScanner, transaction *models.Transaction, extra ...any) error {
+	var fxRate, fxQuoteID, reversalOf sql.NullString
+	var holdExpiresAt sql.NullTime
+
+	dest := []any{
+		&transaction.ID,
+		&transaction.SourceAccountID,
+		&transaction.DestinationAccountID,
+		&transaction.Amount,
+		&transaction.Currency,
+		&transaction.DestinationAmount,
+		&transaction.DestinationCurrency,
+		&fxRate,
+		&fxQuoteID,
+		&transaction.Status,
+		&reversalOf,
+		&transaction.ReversedAmount,
+		&transaction.AuthorizedAmount,
+		&holdExpiresAt,
+		&transaction.ChainAccountID,
+		&transaction.ChainSeq,
+		&transaction.PrevHash,
+		&transaction.Hash,
+		&transaction.SettlementHash,
+		&transaction.CreatedAt,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	if fxRate.Valid {
+		rate := trimNumeric(fxRate.String)
+		transaction.FXRate = &rate
+	}
+	if fxQuoteID.Valid {
+		transaction.FXQuoteID = &fxQuoteID.String
+	}
+	if reversalOf.Valid {
+		transaction.ReversalOf = &reversalOf.String
+	}
+	if holdExpiresAt.Valid {
+		transaction.HoldExpiresAt = &holdExpiresAt.Time
+	}
+
+	return nil
+}
+
+func (r *TransactionRepository) GetByID(ctx context.Context, id string) (*models.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions t
+		WHERE t.id = $1
+	`
+
+	return r.getOne(ctx, r.db, query, id)
+}
+
+// GetForUpdate loads the transaction and locks its row until tx ends.
+func (r *TransactionRepository) GetForUpdate(ctx context.Context, tx repository.Tx, id string) (*models.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions t
+		WHERE t.id = $1
+		FOR UPDATE
+	`
+
+	return r.getOne(ctx, sqlTx(tx), query, id)
+}
+
+func (r *TransactionRepository) getOne(ctx context.Context, q queryer, query string, args ...any) (*models.Transaction, error) {
+	var transaction models.Transaction
+
+	err := scanTransaction(q.QueryRowContext(ctx, query, args...), &transaction)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if transaction.SourceAccountID == 0 {
+		if err := r.loadLegs(ctx, q, &transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	return &transaction, nil
+}
+
+// Update stores the parts of a transaction that change after it is created:
+// its amounts once captured, its status, how much has been reversed and, for
+// a hold that has ended, its settlement hash.
+func (r *TransactionRepository) Update(ctx context.Context, tx repository.Tx, transaction *models.Transaction) error {
+	query := `
+		UPDATE transactions
+		SET amount = $2, destination_amount = $3, status = $4, reversed_amount = $5, settlement_hash = NULLIF($6, '')
+		WHERE id = $1
+	`
+
+	_, err := sqlTx(tx).ExecContext(
+		ctx,
+		query,
+		transaction.ID,
+		transaction.Amount,
+		transaction.DestinationAmount,
+		transaction.Status,
+		transaction.ReversedAmount,
+		transaction.SettlementHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ChainHead returns the sequence number and hash of the last transaction in
+// the account's chain, or 0 and the genesis hash if it has none. The account
+// must be locked by the caller.
+func (r *TransactionRepository) ChainHead(ctx context.Context, tx repository.Tx, accountID int64) (int64, string, error) {
+	query := `
+		SELECT chain_seq, hash
+		FROM transactions
+		WHERE chain_account_id = $1
+		ORDER BY chain_seq DESC
+		LIMIT 1
+	`
+
+	var seq int64
+	var hash string
+	err := sqlTx(tx).QueryRowContext(ctx, query, accountID).Scan(&seq, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, models.GenesisHash, nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get chain head: %w", err)
+	}
+
+	return seq, hash, nil
+}
+
+// Seal stores the chain position and hash of a newly created transaction.
+func (r *TransactionRepository) Seal(ctx context.Context, tx repository.Tx, transaction *models.Transaction) error {
+	query := `
+		UPDATE transactions
+		SET chain_account_id = $2, chain_seq = $3, prev_hash = $4, hash = $5
+		WHERE id = $1 AND hash IS NULL
+	`
+
+	result, err := sqlTx(tx).ExecContext(
+		ctx,
+		query,
+		transaction.ID,
+		transaction.ChainAccountID,
+		transaction.ChainSeq,
+		transaction.PrevHash,
+		transaction.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to seal transaction: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return models.ErrTransactionNotFound
+	}
+
+	return nil
+}
+
+// ListChainAccounts returns the IDs of the accounts that have a chain.
+func (r *TransactionRepository) ListChainAccounts(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT chain_account_id
+		FROM transactions
+		WHERE chain_account_id IS NOT NULL
+		ORDER BY chain_account_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chains: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan chain account: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list chains: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListChain returns up to limit transactions of the account's chain after
+// sequence number afterSeq, in chain order.
+func (r *TransactionRepository) ListChain(ctx context.Context, accountID, afterSeq int64, limit int) ([]models.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions t
+		WHERE t.chain_account_id = $1 AND t.chain_seq > $2
+		ORDER BY t.chain_seq
+		LIMIT $3
+	`
+
+	return r.list(ctx, query, accountID, afterSeq, limit)
+}
+
+// ListUnsealed returns the transactions without a hash created after the
+// given time.
+func (r *TransactionRepository) ListUnsealed(ctx context.Context, after time.Time) ([]models.Transaction, error) {
+	query := `SELECT ` + transactionColumns + `
+		FROM transactions t
+		WHERE t.hash IS NULL AND t.created_at > $1
+		ORDER BY t.created_at, t.id
+	`
+
+	return r.list(ctx, query, after)
+}
+
+// FirstSealedAt returns when the oldest sealed transaction was created, or
+// nil if none is.
+func (r *TransactionRepository) FirstSealedAt(ctx context.Context) (*time.Time, error) {
+	var createdAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT MIN(created_at) FROM transactions WHERE hash IS NOT NULL`).Scan(&createdAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get first sealed transaction: %w", err)
+	}
+
+	if !createdAt.Valid {
+		return nil, nil
+	}
+	return &createdAt.Time, nil
+}
+
+func (r *TransactionRepository) list(ctx context.Context, query string, args ...any) ([]models.Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		var transaction models.Transaction
+		if err := scanTransaction(rows, &transaction); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	rows.Close()
+
+	for i := range transactions {
+		if transactions[i].SourceAccountID == 0 {
+			if err := r.loadLegs(ctx, r.db, &transactions[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return transactions, nil
+}
+
+// ListExpiredHolds returns the IDs of up to limit pending transfers whose
+// hold expired by now, oldest first.
+func (r *TransactionRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	query := `
+		SELECT id
+		FROM transactions
+		WHERE status = $1 AND hold_expires_at <= $2
+		ORDER BY hold_expires_at
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, models.StatusPending, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired holds: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan expired hold: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListByAccount returns one page of an account's transactions, newest first.
+// Simple transfers are found through the source and destination indexes;
+// multi-leg transactions through the account's postings.
+func (r *TransactionRepository) ListByAccount(ctx context.Context, filter *models.TransactionFilter) ([]models.AccountTransaction, error) {
+	args := []any{filter.AccountID}
+	conditions := []string{}
+
+	if filter.Direction != "" {
+		args = append(args, filter.Direction)
+		conditions = append(conditions, fmt.Sprintf("h.direction = $%d", len(args)))
+	}
+	if filter.MinAmount != nil {
+		args = append(args, *filter.MinAmount)
+		conditions = append(conditions, fmt.Sprintf("h.account_amount >= $%d", len(args)))
+	}
+	if filter.MaxAmount != nil {
+		args = append(args, *filter.MaxAmount)
+		conditions = append(conditions, fmt.Sprintf("h.account_amount <= $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("t.created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("t.created_at < $%d", len(args)))
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(t.created_at, t.id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+	query := `
+		SELECT ` + transactionColumns + `, h.direction, h.account_amount
+		FROM (
+			SELECT id AS transaction_id, 'debit' AS direction, amount AS account_amount
+			FROM transactions
+			WHERE source_account_id = $1
+			UNION ALL
+			SELECT id, 'credit', COALESCE(destination_amount, amount)
+			FROM transactions
+			WHERE destination_account_id = $1
+			UNION ALL
+			SELECT e.transaction_id, CASE WHEN p.amount < 0 THEN 'debit' ELSE 'credit' END, ABS(p.amount)
+			FROM postings p
+			JOIN journal_entries e ON e.id = p.entry_id
+			JOIN transactions mt ON mt.id = e.transaction_id
+			WHERE p.account_id = $1 AND e.kind = 'TRANSFER' AND mt.source_account_id IS NULL
+		) h
+		JOIN transactions t ON t.id = h.transaction_id
+		` + where + `
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []models.AccountTransaction{}
+	for rows.Next() {
+		var item models.AccountTransaction
+		if err := scanTransaction(rows, &item.Transaction, &item.Direction, &item.AccountAmount); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// loadLegs fills in the legs of a multi-leg transaction from the postings of
+// its journal entry.
+func (r *TransactionRepository) loadLegs(ctx context.Context, q queryer, transaction *models.Transaction) error {
+	query := `
+		SELECT p.account_id, p.amount
+		FROM postings p
+		JOIN journal_entries e ON e.id = p.entry_id
+		WHERE e.transaction_id = $1 AND e.kind = $2 AND p.account_id IS NOT NULL
+		ORDER BY p.id
+	`
+
+	rows, err := q.QueryContext(ctx, query, transaction.ID, models.EntryKindTransfer)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction legs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var leg models.Leg
+		if err := rows.Scan(&leg.AccountID, &leg.Amount); err != nil {
+			return fmt.Errorf("failed to scan transaction leg: %w", err)
+		}
+		if leg.Amount < 0 {
+			leg.Amount = -leg.Amount
+			transaction.Sources = append(transaction.Sources, leg)
+		} else {
+			transaction.Destinations = append(transaction.Destinations, leg)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get transaction legs: %w", err)
+	}
+
+	return nil
+}
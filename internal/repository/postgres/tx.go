@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/lib/pq"
+)
+
+// UnitOfWork begins database transactions for the Postgres repositories.
+type UnitOfWork struct {
+	db *sql.DB
+}
+
+var _ repository.UnitOfWork = (*UnitOfWork)(nil)
+
+func NewUnitOfWork(db *sql.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Begin starts a transaction. A read-only one runs at REPEATABLE READ, so
+// every query in it sees the same snapshot; others run at READ COMMITTED
+// unless they ask for SERIALIZABLE.
+func (u *UnitOfWork) Begin(ctx context.Context, opts repository.TxOptions) (repository.Tx, error) {
+	txOptions := &sql.TxOptions{ReadOnly: opts.ReadOnly}
+	switch {
+	case opts.Serializable:
+		txOptions.Isolation = sql.LevelSerializable
+	case opts.ReadOnly:
+		txOptions.Isolation = sql.LevelRepeatableRead
+	}
+
+	tx, err := u.db.BeginTx(ctx, txOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{tx: tx}, nil
+}
+
+// Retryable reports serialization failures (40001) and deadlocks (40P01):
+// Postgres aborts one of the transactions involved, which can be retried.
+func (u *UnitOfWork) Retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
+
+// Tx is a database transaction.
+type Tx struct {
+	tx *sql.Tx
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	return nil
+}
+
+func (t *Tx) RollbackToSavepoint(ctx context.Context, name string) error {
+	if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to roll back to savepoint: %w", err)
+	}
+	return nil
+}
+
+// sqlTx returns the database transaction behind tx, which must have been
+// begun by a UnitOfWork.
+func sqlTx(tx repository.Tx) *sql.Tx {
+	return tx.(*Tx).tx
+}
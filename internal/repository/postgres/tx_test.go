@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitOfWork_Retryable(t *testing.T) {
+	serializationFailure := &pq.Error{Code: "40001"}
+	deadlock := &pq.Error{Code: "40P01"}
+	uniqueViolation := &pq.Error{Code: "23505"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", serializationFailure, true},
+		{"deadlock", deadlock, true},
+		{"wrapped serialization failure", fmt.Errorf("failed to post journal entry: %w", serializationFailure), true},
+		{"wrapped deadlock", fmt.Errorf("failed to commit transaction: %w", deadlock), true},
+		{"unique violation", uniqueViolation, false},
+		{"wrapped unique violation", fmt.Errorf("failed to create transaction: %w", uniqueViolation), false},
+		{"not a Postgres error", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+
+	uow := NewUnitOfWork(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, uow.Retryable(tt.err))
+		})
+	}
+}
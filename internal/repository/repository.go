@@ -0,0 +1,187 @@
+// Package repository defines the storage the services are written against.
+// The postgres package implements it on a PostgreSQL server and the sqlite
+// package on a SQLite file, for single-node deployments; the memory package
+// keeps the ledger in process, with no durability.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+)
+
+// Tx is a unit of work: what is written through it is committed or rolled
+// back as a whole. Rollback after Commit is a no-op, so it can be deferred.
+type Tx interface {
+	Commit() error
+	Rollback() error
+	// Savepoint marks a point that RollbackToSavepoint can undo back to
+	// without ending the transaction.
+	Savepoint(ctx context.Context, name string) error
+	RollbackToSavepoint(ctx context.Context, name string) error
+}
+
+type TxOptions struct {
+	// ReadOnly transactions see a single consistent snapshot of the ledger
+	// for their whole duration.
+	ReadOnly bool
+	// Serializable transactions run as if no other ran at the same time,
+	// which the store may enforce by aborting one of two that conflict.
+	// Stores that run write transactions one at a time already do.
+	Serializable bool
+}
+
+// UnitOfWork begins transactions for the repositories of the same store.
+type UnitOfWork interface {
+	Begin(ctx context.Context, opts TxOptions) (Tx, error)
+	// Retryable reports whether err means the transaction was aborted
+	// because of a conflict with a concurrent one, such as a serialization
+	// failure or a deadlock, so running it again from the start may succeed.
+	Retryable(err error) bool
+}
+
+// Methods that take a Tx read and write through it. Those that lock ("for
+// update") hold the lock until the transaction ends; the others read what
+// has been committed.
+
+type AccountRepository interface {
+	// Create inserts an account with a zero balance. Funds only ever enter
+	// an account through journal postings.
+	Create(ctx context.Context, tx Tx, account *models.Account) error
+	// Import creates those of the accounts that do not exist yet, with zero
+	// balances, and returns the IDs of the ones it created. Existing
+	// accounts are left unchanged.
+	Import(ctx context.Context, tx Tx, accounts []models.Account) ([]int64, error)
+	// ListOpenings returns those of the accounts with the given IDs that
+	// exist, each with the sum of its opening balance postings, for an
+	// import to compare its rows with.
+	ListOpenings(ctx context.Context, tx Tx, ids []int64) ([]models.AccountOpening, error)
+	GetByID(ctx context.Context, id int64) (*models.Account, error)
+	// GetForUpdate locks the account. It does not read its pending
+	// credits: SettleCredits folds them into the balance instead.
+	GetForUpdate(ctx context.Context, tx Tx, id int64) (*models.Account, error)
+	// GetForCredit locks the account shared, for a transaction that only
+	// credits it: such transactions do not wait for each other, only for
+	// and against one that locks the account for update. Like
+	// GetForUpdate, it does not read pending credits.
+	GetForCredit(ctx context.Context, tx Tx, id int64) (*models.Account, error)
+	// AdjustHold adds delta to the funds held on the account; a negative
+	// delta releases a hold. The caller must hold the account's lock.
+	AdjustHold(ctx context.Context, tx Tx, id int64, delta int64) error
+	UpdateStatus(ctx context.Context, tx Tx, account *models.Account) error
+	UpdateOverdraftLimit(ctx context.Context, tx Tx, account *models.Account) error
+	UpdateHot(ctx context.Context, tx Tx, account *models.Account) error
+	// SettleCredits adds the account's pending credits to its balance and
+	// returns their sum. The caller must hold the account's lock for
+	// update, so no transaction crediting it is still running.
+	SettleCredits(ctx context.Context, tx Tx, id int64) (int64, error)
+	// ListUnsettled returns the IDs of up to limit accounts after afterID
+	// that have pending credits, in ascending order.
+	ListUnsettled(ctx context.Context, afterID int64, limit int) ([]int64, error)
+	CreateStatusChange(ctx context.Context, tx Tx, change *models.AccountStatusChange) error
+	// ListStatusChanges returns the account's status changes, oldest first.
+	ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error)
+}
+
+type TransactionRepository interface {
+	// Create inserts the transaction record and sets its CreatedAt, taken
+	// after the caller has locked the accounts.
+	Create(ctx context.Context, tx Tx, transaction *models.Transaction) error
+	GetByID(ctx context.Context, id string) (*models.Transaction, error)
+	GetForUpdate(ctx context.Context, tx Tx, id string) (*models.Transaction, error)
+	// Update stores the parts of a transaction that change after it is
+	// created: its amounts once captured, its status, how much has been
+	// reversed and, for a hold that has ended, its settlement hash.
+	Update(ctx context.Context, tx Tx, transaction *models.Transaction) error
+	// ListByAccount returns one page of an account's transactions, newest
+	// first.
+	ListByAccount(ctx context.Context, filter *models.TransactionFilter) ([]models.AccountTransaction, error)
+	// ListExpiredHolds returns the IDs of up to limit pending transfers
+	// whose hold expired by now, oldest first.
+	ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]string, error)
+
+	// ChainHead returns the sequence number and hash of the last
+	// transaction in the account's chain, or 0 and the genesis hash if it
+	// has none. The account must be locked by the caller.
+	ChainHead(ctx context.Context, tx Tx, accountID int64) (int64, string, error)
+	// Seal stores the chain position and hash of a newly created
+	// transaction.
+	Seal(ctx context.Context, tx Tx, transaction *models.Transaction) error
+	// ListChainAccounts returns the IDs of the accounts that have a chain.
+	ListChainAccounts(ctx context.Context) ([]int64, error)
+	// ListChain returns up to limit transactions of the account's chain
+	// after sequence number afterSeq, in chain order.
+	ListChain(ctx context.Context, accountID, afterSeq int64, limit int) ([]models.Transaction, error)
+	// ListUnsealed returns the transactions without a hash created after
+	// the given time.
+	ListUnsealed(ctx context.Context, after time.Time) ([]models.Transaction, error)
+	// FirstSealedAt returns when the oldest sealed transaction was created,
+	// or nil if none is.
+	FirstSealedAt(ctx context.Context) (*time.Time, error)
+}
+
+type JournalRepository interface {
+	// Post writes a balanced journal entry and folds each account posting
+	// into the account's cached balance, or records it as a pending credit
+	// if it is deferred. The accounts must already be locked by the caller,
+	// those with deferred postings at least shared. The entry and its
+	// postings share one timestamp: entry.CreatedAt if set, or the current
+	// time.
+	Post(ctx context.Context, tx Tx, entry *models.JournalEntry) error
+	// BalanceAt returns the account's balance after the postings up to and
+	// including asOf.
+	BalanceAt(ctx context.Context, accountID int64, asOf time.Time) (int64, error)
+	// ListPostings returns one page of an account's postings with their
+	// entries, oldest first, in the order BalanceAt sums them.
+	ListPostings(ctx context.Context, filter *models.PostingFilter) ([]models.StatementEntry, error)
+}
+
+type QuoteRepository interface {
+	Create(ctx context.Context, tx Tx, quote *models.FXQuote) error
+	GetByID(ctx context.Context, id string) (*models.FXQuote, error)
+}
+
+type IdempotencyRepository interface {
+	// Claim reserves key for the work done in tx. If another transaction
+	// holds the key, Claim waits until it ends: it then returns the key as
+	// committed, or claims it in turn. A nil key means tx now holds it; it
+	// must be completed before tx commits.
+	Claim(ctx context.Context, tx Tx, key models.RequestKey, fingerprint string) (*models.IdempotencyKey, error)
+	// Complete records the outcome of the request that claimed the key in
+	// tx.
+	Complete(ctx context.Context, tx Tx, outcome *models.IdempotencyKey) error
+	// PurgeBefore deletes up to limit keys created before cutoff and
+	// returns how many it deleted.
+	PurgeBefore(ctx context.Context, tx Tx, cutoff time.Time, limit int) (int, error)
+}
+
+type SnapshotRepository interface {
+	// CreateAll writes a snapshot at asOf for every account created by
+	// then, leaving existing snapshots alone. It returns the number of
+	// snapshots written.
+	CreateAll(ctx context.Context, tx Tx, asOf time.Time) (int64, error)
+	// LatestAsOf returns the time of the most recent snapshots, or nil if
+	// none have been taken.
+	LatestAsOf(ctx context.Context) (*time.Time, error)
+	// FirstPostingAt returns the time of the oldest account posting, or nil
+	// if there are none.
+	FirstPostingAt(ctx context.Context) (*time.Time, error)
+}
+
+// ReconciliationRepository recomputes the ledger from its journal. Run its
+// methods in one read-only transaction so they see the same snapshot.
+type ReconciliationRepository interface {
+	CountAccounts(ctx context.Context, tx Tx) (int, error)
+	// AccountDrift returns the accounts whose balance is not the sum of
+	// their postings or whose held balance is not the sum of their pending
+	// holds.
+	AccountDrift(ctx context.Context, tx Tx) ([]models.AccountDrift, error)
+	// UnbalancedEntries returns the journal entries whose postings do not
+	// sum to zero in some currency.
+	UnbalancedEntries(ctx context.Context, tx Tx) ([]models.UnbalancedEntry, error)
+	// TransactionMismatches returns the transactions that moved money
+	// without a journal entry, have more than one, or have one while
+	// holding funds that were never captured.
+	TransactionMismatches(ctx context.Context, tx Tx) ([]models.TransactionMismatch, error)
+}
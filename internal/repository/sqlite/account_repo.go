@@ -0,0 +1,357 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type AccountRepository struct {
+	store *Store
+}
+
+var _ repository.AccountRepository = (*AccountRepository)(nil)
+
+func NewAccountRepository(store *Store) *AccountRepository {
+	return &AccountRepository{store: store}
+}
+
+func (r *AccountRepository) Create(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		INSERT INTO accounts (id, balance, currency, overdraft_limit, hot, created_at)
+		VALUES (?, 0, ?, ?, ?, ?)
+	`
+
+	_, err := sqlTx(tx).ExecContext(ctx, query, account.ID, account.Currency, account.OverdraftLimit, account.Hot, micros(now()))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrAccountExists
+		}
+		return fmt.Errorf("failed to create account: %w", err)
+	}
+
+	return nil
+}
+
+// Import inserts the accounts one at a time; SQLite has no COPY, and with
+// the database's write lock held the inserts are cheap.
+func (r *AccountRepository) Import(ctx context.Context, tx repository.Tx, accounts []models.Account) ([]int64, error) {
+	query := `
+		INSERT INTO accounts (id, balance, currency, overdraft_limit, hot, created_at)
+		VALUES (?, 0, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	stmt, err := sqlTx(tx).PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare account import: %w", err)
+	}
+	defer stmt.Close()
+
+	createdAt := micros(now())
+	var created []int64
+	for _, account := range accounts {
+		result, err := stmt.ExecContext(ctx, account.ID, account.Currency, account.OverdraftLimit, account.Hot, createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import account: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected > 0 {
+			created = append(created, account.ID)
+		}
+	}
+
+	return created, nil
+}
+
+// ListOpenings reads the accounts one at a time, like Import writes them.
+func (r *AccountRepository) ListOpenings(ctx context.Context, tx repository.Tx, ids []int64) ([]models.AccountOpening, error) {
+	query := `
+		SELECT a.id, a.currency, a.overdraft_limit, a.hot,
+			(SELECT COALESCE(SUM(p.amount), 0)
+			 FROM postings p
+			 JOIN journal_entries e ON e.id = p.entry_id
+			 WHERE p.account_id = a.id AND e.kind = ?)
+		FROM accounts a
+		WHERE a.id = ?
+	`
+
+	stmt, err := sqlTx(tx).PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare account openings: %w", err)
+	}
+	defer stmt.Close()
+
+	var openings []models.AccountOpening
+	for _, id := range ids {
+		var opening models.AccountOpening
+		account := &opening.Account
+		err := stmt.QueryRowContext(ctx, models.EntryKindOpeningBalance, id).
+			Scan(&account.ID, &account.Currency, &account.OverdraftLimit, &account.Hot, &opening.Balance)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account opening: %w", err)
+		}
+		openings = append(openings, opening)
+	}
+
+	return openings, nil
+}
+
+const accountColumns = `id, balance, held_balance, overdraft_limit, currency, status, hot, created_at, updated_at`
+
+// scanAccount scans a row of accountColumns and, if pending is set, the sum
+// of the account's pending credits after them.
+func scanAccount(row *sql.Row, pending bool) (*models.Account, error) {
+	var account models.Account
+	dest := []any{
+		&account.ID,
+		&account.Balance,
+		&account.HeldBalance,
+		&account.OverdraftLimit,
+		&account.Currency,
+		&account.Status,
+		&account.Hot,
+		scanTime(&account.CreatedAt),
+		scanNullTime(&account.UpdatedAt),
+	}
+	if pending {
+		dest = append(dest, &account.PendingCredits)
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *AccountRepository) GetByID(ctx context.Context, id int64) (*models.Account, error) {
+	query := `
+		SELECT ` + accountColumns + `,
+			(SELECT COALESCE(SUM(amount), 0) FROM pending_credits WHERE account_id = accounts.id)
+		FROM accounts
+		WHERE id = ?
+	`
+
+	account, err := scanAccount(r.store.db.QueryRowContext(ctx, query, id), true)
+	if err != nil && !errors.Is(err, models.ErrAccountNotFound) {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return account, err
+}
+
+// GetForUpdate reads the account in tx. The transaction already holds the
+// database's write lock, so no other can change the account until it ends.
+func (r *AccountRepository) GetForUpdate(ctx context.Context, tx repository.Tx, id int64) (*models.Account, error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = ?`
+
+	account, err := scanAccount(sqlTx(tx).QueryRowContext(ctx, query, id), false)
+	if err != nil && !errors.Is(err, models.ErrAccountNotFound) {
+		return nil, fmt.Errorf("failed to get account for update: %w", err)
+	}
+	return account, err
+}
+
+// GetForCredit is GetForUpdate: with one transaction at a time there is no
+// shared lock to take.
+func (r *AccountRepository) GetForCredit(ctx context.Context, tx repository.Tx, id int64) (*models.Account, error) {
+	return r.GetForUpdate(ctx, tx, id)
+}
+
+func (r *AccountRepository) SettleCredits(ctx context.Context, tx repository.Tx, id int64) (int64, error) {
+	var settled int64
+	err := sqlTx(tx).QueryRowContext(ctx, `SELECT COALESCE(SUM(amount), 0) FROM pending_credits WHERE account_id = ?`, id).Scan(&settled)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum pending credits: %w", err)
+	}
+
+	if settled == 0 {
+		return 0, nil
+	}
+
+	if _, err := sqlTx(tx).ExecContext(ctx, `DELETE FROM pending_credits WHERE account_id = ?`, id); err != nil {
+		return 0, fmt.Errorf("failed to settle pending credits: %w", err)
+	}
+
+	query := `
+		UPDATE accounts
+		SET balance = balance + ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, settled, micros(now()), id); err != nil {
+		return 0, fmt.Errorf("failed to settle pending credits: %w", err)
+	}
+
+	return settled, nil
+}
+
+func (r *AccountRepository) ListUnsettled(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	query := `
+		SELECT account_id
+		FROM pending_credits
+		WHERE account_id > ?
+		GROUP BY account_id
+		ORDER BY account_id
+		LIMIT ?
+	`
+
+	rows, err := r.store.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unsettled accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan account ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list unsettled accounts: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (r *AccountRepository) AdjustHold(ctx context.Context, tx repository.Tx, id int64, delta int64) error {
+	query := `
+		UPDATE accounts
+		SET held_balance = held_balance + ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, delta, micros(now()), id); err != nil {
+		return fmt.Errorf("failed to update held balance: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) UpdateStatus(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		UPDATE accounts
+		SET status = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, account.Status, micros(now()), account.ID); err != nil {
+		return fmt.Errorf("failed to update account status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) UpdateOverdraftLimit(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		UPDATE accounts
+		SET overdraft_limit = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, account.OverdraftLimit, micros(now()), account.ID); err != nil {
+		return fmt.Errorf("failed to update overdraft limit: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) UpdateHot(ctx context.Context, tx repository.Tx, account *models.Account) error {
+	query := `
+		UPDATE accounts
+		SET hot = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	if _, err := sqlTx(tx).ExecContext(ctx, query, account.Hot, micros(now()), account.ID); err != nil {
+		return fmt.Errorf("failed to update hot mode: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountRepository) CreateStatusChange(ctx context.Context, tx repository.Tx, change *models.AccountStatusChange) error {
+	query := `
+		INSERT INTO account_status_changes (account_id, from_status, to_status, reason, actor, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	createdAt := now()
+	result, err := sqlTx(tx).ExecContext(
+		ctx,
+		query,
+		change.AccountID,
+		change.FromStatus,
+		change.ToStatus,
+		change.Reason,
+		change.Actor,
+		micros(createdAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
+
+	if change.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to get status change ID: %w", err)
+	}
+	change.CreatedAt = createdAt
+
+	return nil
+}
+
+func (r *AccountRepository) ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChange, error) {
+	query := `
+		SELECT id, account_id, from_status, to_status, reason, actor, created_at
+		FROM account_status_changes
+		WHERE account_id = ?
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.store.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.AccountStatusChange{}
+	for rows.Next() {
+		var change models.AccountStatusChange
+		err := rows.Scan(
+			&change.ID,
+			&change.AccountID,
+			&change.FromStatus,
+			&change.ToStatus,
+			&change.Reason,
+			&change.Actor,
+			scanTime(&change.CreatedAt),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status change: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list status changes: %w", err)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,35 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// isUniqueViolation reports whether err is SQLite's counterpart of Postgres
+// error 23505.
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey || sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique)
+}
+
+// isCheckViolation reports whether err is a violation of the named CHECK
+// constraint. SQLite only reports the name in the message.
+func isCheckViolation(err error, constraint string) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintCheck &&
+		strings.Contains(sqliteErr.Error(), constraint)
+}
+
+// isBusy reports whether err is SQLite giving up on a lock another
+// connection held for longer than the busy timeout.
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
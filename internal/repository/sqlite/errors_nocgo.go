@@ -0,0 +1,18 @@
+//go:build !cgo
+
+package sqlite
+
+// Without cgo the SQLite driver can not open a database, so there are no
+// errors to classify; these only keep the package building.
+
+func isUniqueViolation(err error) bool {
+	return false
+}
+
+func isCheckViolation(err error, constraint string) bool {
+	return false
+}
+
+func isBusy(err error) bool {
+	return false
+}
@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type IdempotencyRepository struct {
+	store *Store
+}
+
+var _ repository.IdempotencyRepository = (*IdempotencyRepository)(nil)
+
+func NewIdempotencyRepository(store *Store) *IdempotencyRepository {
+	return &IdempotencyRepository{store: store}
+}
+
+// Claim never has to wait: transactions are serialized, so the one that
+// claimed the key before, if any, has already ended.
+func (r *IdempotencyRepository) Claim(ctx context.Context, tx repository.Tx, key models.RequestKey, fingerprint string) (*models.IdempotencyKey, error) {
+	insertQuery := `
+		INSERT INTO idempotency_keys (client_id, key, request_fingerprint, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (client_id, key) DO NOTHING
+	`
+
+	result, err := sqlTx(tx).ExecContext(ctx, insertQuery, key.ClientID, key.Key, fingerprint, micros(now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if claimed == 1 {
+		return nil, nil
+	}
+
+	selectQuery := `
+		SELECT client_id, key, COALESCE(request_fingerprint, ''), transaction_id, account_id,
+			COALESCE(error_code, ''), created_at
+		FROM idempotency_keys
+		WHERE client_id = ? AND key = ?
+	`
+
+	var existing models.IdempotencyKey
+	var transactionID sql.NullString
+	var accountID sql.NullInt64
+	err = sqlTx(tx).QueryRowContext(ctx, selectQuery, key.ClientID, key.Key).Scan(
+		&existing.ClientID,
+		&existing.Key,
+		&existing.RequestFingerprint,
+		&transactionID,
+		&accountID,
+		&existing.ErrorCode,
+		scanTime(&existing.CreatedAt),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	if transactionID.Valid {
+		existing.TransactionID = &transactionID.String
+	}
+	if accountID.Valid {
+		existing.AccountID = &accountID.Int64
+	}
+	return &existing, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, tx repository.Tx, outcome *models.IdempotencyKey) error {
+	query := `
+		UPDATE idempotency_keys
+		SET transaction_id = ?, account_id = ?, error_code = NULLIF(?, '')
+		WHERE client_id = ? AND key = ?
+	`
+
+	_, err := sqlTx(tx).ExecContext(ctx, query,
+		outcome.TransactionID,
+		outcome.AccountID,
+		outcome.ErrorCode,
+		outcome.ClientID,
+		outcome.Key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) PurgeBefore(ctx context.Context, tx repository.Tx, cutoff time.Time, limit int) (int, error) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE rowid IN (
+			SELECT rowid FROM idempotency_keys
+			WHERE created_at < ?
+			LIMIT ?
+		)
+	`
+
+	result, err := sqlTx(tx).ExecContext(ctx, query, micros(cutoff), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(purged), nil
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type QuoteRepository struct {
+	store *Store
+}
+
+var _ repository.QuoteRepository = (*QuoteRepository)(nil)
+
+func NewQuoteRepository(store *Store) *QuoteRepository {
+	return &QuoteRepository{store: store}
+}
+
+func (r *QuoteRepository) Create(ctx context.Context, tx repository.Tx, quote *models.FXQuote) error {
+	query := `
+		INSERT INTO fx_quotes (id, source_currency, destination_currency, rate, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	createdAt := now()
+	_, err := sqlTx(tx).ExecContext(
+		ctx,
+		query,
+		quote.ID,
+		quote.SourceCurrency,
+		quote.DestinationCurrency,
+		string(quote.Rate),
+		micros(quote.ExpiresAt),
+		micros(createdAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create fx quote: %w", err)
+	}
+
+	quote.CreatedAt = createdAt
+	return nil
+}
+
+func (r *QuoteRepository) GetByID(ctx context.Context, id string) (*models.FXQuote, error) {
+	query := `
+		SELECT id, source_currency, destination_currency, rate, expires_at, created_at
+		FROM fx_quotes
+		WHERE id = ?
+	`
+
+	var quote models.FXQuote
+	err := r.store.db.QueryRowContext(ctx, query, id).Scan(
+		&quote.ID,
+		&quote.SourceCurrency,
+		&quote.DestinationCurrency,
+		&quote.Rate,
+		scanTime(&quote.ExpiresAt),
+		scanTime(&quote.CreatedAt),
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrQuoteNotFound
+		}
+		return nil, fmt.Errorf("failed to get fx quote: %w", err)
+	}
+
+	return &quote, nil
+}
@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type SnapshotRepository struct {
+	store *Store
+}
+
+var _ repository.SnapshotRepository = (*SnapshotRepository)(nil)
+
+func NewSnapshotRepository(store *Store) *SnapshotRepository {
+	return &SnapshotRepository{store: store}
+}
+
+func (r *SnapshotRepository) CreateAll(ctx context.Context, tx repository.Tx, asOf time.Time) (int64, error) {
+	query := `
+		INSERT INTO balance_snapshots (account_id, as_of, balance, created_at)
+		SELECT a.id, ?1, COALESCE(prev.balance, 0) + COALESCE((
+			SELECT SUM(p.amount)
+			FROM postings p
+			WHERE p.account_id = a.id
+				AND p.created_at <= ?1
+				AND p.created_at > COALESCE(prev.as_of, -9223372036854775808)
+		), 0), ?2
+		FROM accounts a
+		LEFT JOIN balance_snapshots prev ON prev.account_id = a.id AND prev.as_of = (
+			SELECT MAX(s.as_of)
+			FROM balance_snapshots s
+			WHERE s.account_id = a.id AND s.as_of < ?1
+		)
+		WHERE a.created_at <= ?1
+		ON CONFLICT (account_id, as_of) DO NOTHING
+	`
+
+	result, err := sqlTx(tx).ExecContext(ctx, query, micros(asOf), micros(now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create balance snapshots: %w", err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *SnapshotRepository) LatestAsOf(ctx context.Context) (*time.Time, error) {
+	var asOf *time.Time
+	if err := r.store.db.QueryRowContext(ctx, `SELECT MAX(as_of) FROM balance_snapshots`).Scan(scanNullTime(&asOf)); err != nil {
+		return nil, fmt.Errorf("failed to get latest snapshot: %w", err)
+	}
+
+	return asOf, nil
+}
+
+func (r *SnapshotRepository) FirstPostingAt(ctx context.Context) (*time.Time, error) {
+	query := `SELECT MIN(created_at) FROM postings WHERE account_id IS NOT NULL`
+
+	var createdAt *time.Time
+	if err := r.store.db.QueryRowContext(ctx, query).Scan(scanNullTime(&createdAt)); err != nil {
+		return nil, fmt.Errorf("failed to get first posting: %w", err)
+	}
+
+	return createdAt, nil
+}
@@ -0,0 +1,159 @@
+// Package sqlite implements the repositories on a SQLite database, for
+// embedded and single-node deployments. Open the database with
+// database.NewSQLiteDB and create its schema with database.NewSQLiteMigrator.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// Store begins transactions on a SQLite database and is shared by its
+// repositories.
+//
+// SQLite has no row locks: a transaction that writes holds the lock on the
+// whole database. Transactions are therefore serialized here, in the order
+// they begin, which is what the services' "for update" reads rely on. Reads
+// outside a transaction see the last commit and never wait.
+type Store struct {
+	db *sql.DB
+	// writer is held by the one open transaction.
+	writer chan struct{}
+}
+
+var _ repository.UnitOfWork = (*Store)(nil)
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db, writer: make(chan struct{}, 1)}
+}
+
+// Begin starts a transaction once the one before it has ended, or fails when
+// ctx is done first. Read-only transactions are serialized too, which is how
+// they see a single consistent state of the ledger.
+func (s *Store) Begin(ctx context.Context, opts repository.TxOptions) (repository.Tx, error) {
+	select {
+	case s.writer <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		<-s.writer
+		return nil, err
+	}
+
+	return &Tx{tx: tx, writer: s.writer}, nil
+}
+
+// Retryable reports whether err is the database staying locked by another
+// process, such as a migrator, for longer than the busy timeout.
+// Transactions of this Store never conflict with each other.
+func (s *Store) Retryable(err error) bool {
+	return isBusy(err)
+}
+
+// Tx is a database transaction.
+type Tx struct {
+	tx       *sql.Tx
+	writer   chan struct{}
+	released sync.Once
+}
+
+func (t *Tx) Commit() error {
+	defer t.release()
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	defer t.release()
+	return t.tx.Rollback()
+}
+
+func (t *Tx) release() {
+	t.released.Do(func() { <-t.writer })
+}
+
+func (t *Tx) Savepoint(ctx context.Context, name string) error {
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	return nil
+}
+
+func (t *Tx) RollbackToSavepoint(ctx context.Context, name string) error {
+	if _, err := t.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to roll back to savepoint: %w", err)
+	}
+	return nil
+}
+
+// sqlTx returns the database transaction behind tx, which must have been
+// begun by a Store.
+func sqlTx(tx repository.Tx) *sql.Tx {
+	return tx.(*Tx).tx
+}
+
+// Timestamps are stored as microseconds since the Unix epoch.
+
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+func micros(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+func nullMicros(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+	us := t.UnixMicro()
+	return &us
+}
+
+// timeScanner scans a stored timestamp into dest.
+type timeScanner struct {
+	dest *time.Time
+}
+
+func scanTime(dest *time.Time) sql.Scanner {
+	return timeScanner{dest: dest}
+}
+
+func (s timeScanner) Scan(value any) error {
+	us, ok := value.(int64)
+	if !ok {
+		return fmt.Errorf("unexpected timestamp %v", value)
+	}
+	*s.dest = time.UnixMicro(us).UTC()
+	return nil
+}
+
+// nullTimeScanner scans a stored timestamp that may be NULL into dest.
+type nullTimeScanner struct {
+	dest **time.Time
+}
+
+func scanNullTime(dest **time.Time) sql.Scanner {
+	return nullTimeScanner{dest: dest}
+}
+
+func (s nullTimeScanner) Scan(value any) error {
+	if value == nil {
+		*s.dest = nil
+		return nil
+	}
+
+	var t time.Time
+	if err := scanTime(&t).Scan(value); err != nil {
+		return err
+	}
+	*s.dest = &t
+	return nil
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T) *Store {
+	db, err := database.NewSQLiteDB(filepath.Join(t.TempDir(), "ledger.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, database.NewSQLiteMigrator(db).Up(context.Background()))
+	// Migrations can run again.
+	require.NoError(t, database.NewSQLiteMigrator(db).Up(context.Background()))
+
+	return NewStore(db)
+}
+
+func TestAccountRepository_Create(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t)
+	accounts := NewAccountRepository(store)
+
+	tx, err := store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: 1, Currency: "USD"}))
+	assert.ErrorIs(t, accounts.Create(ctx, tx, &models.Account{ID: 1, Currency: "EUR"}), models.ErrAccountExists)
+
+	account, err := accounts.GetForUpdate(ctx, tx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", account.Currency)
+	assert.Equal(t, models.AccountStatusActive, account.Status)
+}
+
+func TestJournalRepository_OverdraftConstraint(t *testing.T) {
+	ctx := context.Background()
+	store := openTestStore(t)
+	accounts := NewAccountRepository(store)
+	journal := NewJournalRepository(store)
+
+	tx, err := store.Begin(ctx, repository.TxOptions{})
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	limit := int64(0)
+	require.NoError(t, accounts.Create(ctx, tx, &models.Account{ID: 1, Currency: "USD", OverdraftLimit: &limit}))
+
+	entry := &models.JournalEntry{
+		ID:   "entry-1",
+		Kind: models.EntryKindTransfer,
+		Postings: []models.Posting{
+			models.AccountPosting(1, -1, "USD"),
+			models.SystemPosting(models.SystemAccountOpeningBalances, 1, "USD"),
+		},
+	}
+	assert.ErrorIs(t, journal.Post(ctx, tx, entry), models.ErrInsufficientFunds)
+}
@@ -1,39 +1,261 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/filipe/financial-ledger-project/internal/models"
 	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/google/uuid"
 )
 
 type AccountService struct {
-	accountRepo *repository.AccountRepository
+	transactor  transactor
+	accountRepo repository.AccountRepository
+	journalRepo repository.JournalRepository
+	idempotency idempotency
 }
 
-func NewAccountService(accountRepo *repository.AccountRepository) *AccountService {
+// settleCreditsBatchSize bounds how many accounts with pending credits are
+// loaded at a time.
+const settleCreditsBatchSize = 100
+
+// importBatchSize bounds how many accounts are imported in one transaction.
+const importBatchSize = 1000
+
+// statementPageSize bounds how many postings a statement reads at a time.
+const statementPageSize = 500
+
+func NewAccountService(
+	uow repository.UnitOfWork,
+	accountRepo repository.AccountRepository,
+	journalRepo repository.JournalRepository,
+	idempotencyRepo repository.IdempotencyRepository,
+) *AccountService {
+	transactor := newTransactor(uow)
 	return &AccountService{
+		transactor:  transactor,
 		accountRepo: accountRepo,
+		journalRepo: journalRepo,
+		idempotency: idempotency{transactor: transactor, repo: idempotencyRepo},
 	}
 }
 
-func (s *AccountService) CreateAccount(ctx context.Context, req models.CreateAccountRequest) error {
-	if err := req.Validate(); err != nil {
+// CreateAccount opens an account and posts its opening balance. With an
+// idempotency key, a retry of a request that opened the account succeeds
+// again rather than failing because the account exists.
+func (s *AccountService) CreateAccount(ctx context.Context, req models.CreateAccountRequest, key models.RequestKey) error {
+	account, balance, err := newAccount(req)
+	if err != nil {
 		return err
 	}
 
-	balanceInCents := models.FloatToCents(req.InitialBalance)
+	_, err = s.idempotency.run(ctx, "create_account", repository.TxOptions{}, key, req.Fingerprint(), func(tx repository.Tx, outcome *models.IdempotencyKey) error {
+		if err := s.accountRepo.Create(ctx, tx, account); err != nil {
+			return fmt.Errorf("failed to create account: %w", err)
+		}
+
+		if balance > 0 {
+			entry := models.NewOpeningBalanceEntry(uuid.New().String(), account, balance)
+			if err := s.journalRepo.Post(ctx, tx, entry); err != nil {
+				return fmt.Errorf("failed to post opening balance: %w", err)
+			}
+		}
+
+		outcome.AccountID = &account.ID
+		return nil
+	})
+	return err
+}
+
+// newAccount validates the request and returns the account it opens and its
+// opening balance.
+func newAccount(req models.CreateAccountRequest) (*models.Account, int64, error) {
+	if err := req.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	currency, err := req.CurrencyCode()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	balance, err := req.InitialBalanceInMinorUnits()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	overdraftLimit, err := req.LimitIn(currency)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	account := &models.Account{
-		ID:      req.AccountID,
-		Balance: balanceInCents,
+		ID:             req.AccountID,
+		Currency:       currency,
+		OverdraftLimit: overdraftLimit,
+		Hot:            req.Hot,
 	}
+	return account, balance, nil
+}
+
+// ImportAccounts opens the accounts of an import file, posting their opening
+// balances, and reports the rows it rejected. Rows are validated like
+// CreateAccount requests and imported in batches, each in a transaction of
+// its own, so a file of any size is read once without holding it in memory.
+// Rows whose account already exists with the same currency, overdraft limit,
+// hot flag and opening balance are skipped, which makes an import safe to run
+// again: an import that failed part way resumes, and one with rejected rows
+// can be fixed and resubmitted whole. Rows that differ from their existing
+// account are rejected.
+func (s *AccountService) ImportAccounts(ctx context.Context, file *models.AccountImportReader) (*models.AccountImportReport, error) {
+	report := &models.AccountImportReport{Rejected: []models.AccountImportReject{}}
+	reject := func(row *models.AccountImportRow, err error) {
+		report.Rejected = append(report.Rejected, models.AccountImportReject{Line: row.Line, Record: row.Record, Error: err.Error()})
+	}
+
+	// seen holds the IDs of the file's valid rows, to reject an account
+	// that appears twice.
+	seen := map[int64]int{}
+	batch := make([]importedAccount, 0, importBatchSize)
+
+	for {
+		row, err := file.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to read import file: %w", err)
+		}
+		report.Rows++
+
+		if row.Err != nil {
+			reject(row, row.Err)
+			continue
+		}
+
+		account, balance, err := newAccount(row.Request)
+		if err != nil {
+			reject(row, err)
+			continue
+		}
+
+		if line, ok := seen[account.ID]; ok {
+			reject(row, fmt.Errorf("account %d already appears on line %d", account.ID, line))
+			continue
+		}
+		seen[account.ID] = row.Line
+
+		batch = append(batch, importedAccount{row: row, account: *account, balance: balance})
+		if len(batch) == importBatchSize {
+			if err := s.importBatch(ctx, batch, report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := s.importBatch(ctx, batch, report); err != nil {
+			return report, err
+		}
+	}
+
+	// A batch rejects its conflicting rows after the file's later invalid
+	// ones have been read.
+	slices.SortStableFunc(report.Rejected, func(a, b models.AccountImportReject) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+
+	return report, nil
+}
+
+type importedAccount struct {
+	row     *models.AccountImportRow
+	account models.Account
+	balance int64
+}
+
+// importBatch creates the batch's accounts that do not exist yet and posts
+// their opening balances. Rows whose account exists are counted as existing
+// if they match it and rejected if they conflict with it.
+func (s *AccountService) importBatch(ctx context.Context, batch []importedAccount, report *models.AccountImportReport) error {
+	accounts := make([]models.Account, len(batch))
+	balances := make(map[int64]int64, len(batch))
+	for i, imported := range batch {
+		accounts[i] = imported.account
+		balances[imported.account.ID] = imported.balance
+	}
+
+	var created []int64
+	var existing []models.AccountOpening
+	err := s.transactor.run(ctx, "import_accounts", repository.TxOptions{}, func(tx repository.Tx) error {
+		var err error
+		if created, err = s.accountRepo.Import(ctx, tx, accounts); err != nil {
+			return err
+		}
+
+		existing = nil
+		if len(created) < len(accounts) {
+			isCreated := make(map[int64]bool, len(created))
+			for _, id := range created {
+				isCreated[id] = true
+			}
+			skipped := make([]int64, 0, len(accounts)-len(created))
+			for i := range accounts {
+				if !isCreated[accounts[i].ID] {
+					skipped = append(skipped, accounts[i].ID)
+				}
+			}
+			if existing, err = s.accountRepo.ListOpenings(ctx, tx, skipped); err != nil {
+				return err
+			}
+		}
 
-	if err := s.accountRepo.Create(ctx, account); err != nil {
-		return fmt.Errorf("failed to create account: %w", err)
+		byID := make(map[int64]*models.Account, len(accounts))
+		for i := range accounts {
+			byID[accounts[i].ID] = &accounts[i]
+		}
+
+		for _, id := range created {
+			if balances[id] == 0 {
+				continue
+			}
+			entry := models.NewOpeningBalanceEntry(uuid.New().String(), byID[id], balances[id])
+			if err := s.journalRepo.Post(ctx, tx, entry); err != nil {
+				return fmt.Errorf("failed to post opening balance of account %d: %w", id, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to import accounts: %w", err)
 	}
 
+	report.Created += len(created)
+
+	rows := make(map[int64]*importedAccount, len(batch))
+	for i := range batch {
+		rows[batch[i].account.ID] = &batch[i]
+	}
+	for _, opening := range existing {
+		imported := rows[opening.Account.ID]
+		if err := opening.Conflict(&imported.account, imported.balance); err != nil {
+			report.Rejected = append(report.Rejected, models.AccountImportReject{
+				Line:   imported.row.Line,
+				Record: imported.row.Record,
+				Error:  err.Error(),
+			})
+			continue
+		}
+		report.Existing++
+	}
 	return nil
 }
 
@@ -50,3 +272,309 @@ func (s *AccountService) GetAccountBalance(ctx context.Context, accountID int64)
 	response := account.ToResponse()
 	return &response, nil
 }
+
+// GetBalanceAt returns the account's balance as it stood at asOf, rebuilt
+// from its journal postings rather than the cached current balance.
+func (s *AccountService) GetBalanceAt(ctx context.Context, accountID int64, asOf time.Time) (*models.BalanceResponse, error) {
+	if accountID <= 0 {
+		return nil, models.ErrInvalidAccountID
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.journalRepo.BalanceAt(ctx, account.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BalanceResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		Balance:   models.MinorUnitsToDecimal(balance, account.Currency),
+		AsOf:      asOf,
+	}, nil
+}
+
+// WriteStatement writes the account's statement for period to w: its
+// balance just before period.From, each posting in the period with the
+// balance after it, and its balance at period.To. Postings are read a page
+// at a time, so the statement is never held in memory. The closing balance
+// is the opening balance plus the postings written, so the statement adds
+// up even if a posting commits while it is being read. Nothing is written
+// if the account can not be found.
+func (s *AccountService) WriteStatement(
+	ctx context.Context,
+	accountID int64,
+	period models.StatementPeriod,
+	w models.StatementWriter,
+) error {
+	if accountID <= 0 {
+		return models.ErrInvalidAccountID
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	statement := &models.Statement{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		From:      period.From,
+		To:        period.To,
+	}
+
+	if period.From.IsZero() {
+		// The statement starts when the account was opened, before which
+		// it had no postings.
+		statement.From = account.CreatedAt
+	} else {
+		// Postings are stored to the microsecond, so this is the balance
+		// just before From.
+		statement.OpeningBalance, err = s.journalRepo.BalanceAt(ctx, account.ID, period.From.Add(-time.Microsecond))
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := w.Begin(statement); err != nil {
+		return err
+	}
+
+	balance := statement.OpeningBalance
+	filter := &models.PostingFilter{
+		AccountID: account.ID,
+		From:      period.From,
+		To:        period.To,
+		Limit:     statementPageSize,
+	}
+
+	for {
+		entries, err := s.journalRepo.ListPostings(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for i := range entries {
+			balance += entries[i].Amount
+			if err := w.Entry(&entries[i], balance); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < filter.Limit {
+			break
+		}
+		last := entries[len(entries)-1]
+		filter.Cursor = &models.PostingCursor{PostedAt: last.PostedAt, PostingID: last.PostingID}
+	}
+
+	return w.End(balance)
+}
+
+// SetOverdraftLimit changes how far below zero the account may go. A limit
+// the account's available balance is already past is refused, as it would
+// leave the balance out of bounds or its holds unable to be captured.
+func (s *AccountService) SetOverdraftLimit(
+	ctx context.Context,
+	accountID int64,
+	req models.OverdraftLimitRequest,
+) (*models.AccountResponse, error) {
+	if accountID <= 0 {
+		return nil, models.ErrInvalidAccountID
+	}
+
+	var account *models.Account
+	err := s.transactor.run(ctx, "set_overdraft_limit", repository.TxOptions{}, func(tx repository.Tx) error {
+		var err error
+		if account, err = s.lockAccount(ctx, tx, accountID); err != nil {
+			return err
+		}
+		if err := account.CanReceive(); err != nil {
+			return err
+		}
+
+		limit, err := req.LimitIn(account.Currency)
+		if err != nil {
+			return err
+		}
+		if limit != nil && account.Available() < -*limit {
+			return models.ErrOverdraftLimitExceeded
+		}
+
+		account.OverdraftLimit = limit
+		return s.accountRepo.UpdateOverdraftLimit(ctx, tx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := account.ToResponse()
+	return &response, nil
+}
+
+// SetHotMode turns hot mode on or off. Credits to a hot account that is not
+// being debited at the same time do not wait for each other: they are kept
+// as pending until the account is next locked for update, or until
+// SettleCredits runs. Turning it off settles them.
+func (s *AccountService) SetHotMode(ctx context.Context, accountID int64, req models.HotModeRequest) (*models.AccountResponse, error) {
+	if accountID <= 0 {
+		return nil, models.ErrInvalidAccountID
+	}
+
+	var account *models.Account
+	err := s.transactor.run(ctx, "set_hot_mode", repository.TxOptions{}, func(tx repository.Tx) error {
+		var err error
+		if account, err = s.lockAccount(ctx, tx, accountID); err != nil {
+			return err
+		}
+		if err := account.CanReceive(); err != nil {
+			return err
+		}
+
+		account.Hot = req.Hot
+		return s.accountRepo.UpdateHot(ctx, tx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := account.ToResponse()
+	return &response, nil
+}
+
+// SettleCredits folds the pending credits of every account that has some
+// into its balance and returns how many accounts it settled. Transfers
+// settle a hot account whenever they debit it; this bounds how long credits
+// stay pending on one that is rarely debited.
+func (s *AccountService) SettleCredits(ctx context.Context) (int, error) {
+	settled := 0
+	var afterID int64
+	for {
+		ids, err := s.accountRepo.ListUnsettled(ctx, afterID, settleCreditsBatchSize)
+		if err != nil {
+			return settled, err
+		}
+
+		for _, id := range ids {
+			err := s.transactor.run(ctx, "settle_credits", repository.TxOptions{}, func(tx repository.Tx) error {
+				_, err := s.lockAccount(ctx, tx, id)
+				return err
+			})
+			if err != nil {
+				return settled, fmt.Errorf("failed to settle account %d: %w", id, err)
+			}
+			settled++
+			afterID = id
+		}
+
+		if len(ids) < settleCreditsBatchSize {
+			return settled, nil
+		}
+	}
+}
+
+// lockAccount locks the account for update and settles its pending credits,
+// so its balance is complete.
+func (s *AccountService) lockAccount(ctx context.Context, tx repository.Tx, accountID int64) (*models.Account, error) {
+	account, err := s.accountRepo.GetForUpdate(ctx, tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	settled, err := s.accountRepo.SettleCredits(ctx, tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	account.Balance += settled
+	return account, nil
+}
+
+// FreezeAccount stops the account from sending money. It can still receive.
+func (s *AccountService) FreezeAccount(ctx context.Context, accountID int64, req models.ChangeAccountStatusRequest) (*models.AccountResponse, error) {
+	return s.changeStatus(ctx, accountID, models.AccountStatusFrozen, req)
+}
+
+func (s *AccountService) UnfreezeAccount(ctx context.Context, accountID int64, req models.ChangeAccountStatusRequest) (*models.AccountResponse, error) {
+	return s.changeStatus(ctx, accountID, models.AccountStatusActive, req)
+}
+
+// CloseAccount closes an account that holds no funds. Closed accounts can
+// neither send nor receive, and can not be reopened.
+func (s *AccountService) CloseAccount(ctx context.Context, accountID int64, req models.ChangeAccountStatusRequest) (*models.AccountResponse, error) {
+	return s.changeStatus(ctx, accountID, models.AccountStatusClosed, req)
+}
+
+func (s *AccountService) ListStatusChanges(ctx context.Context, accountID int64) ([]models.AccountStatusChangeResponse, error) {
+	if accountID <= 0 {
+		return nil, models.ErrInvalidAccountID
+	}
+
+	if _, err := s.accountRepo.GetByID(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.accountRepo.ListStatusChanges(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.AccountStatusChangeResponse, 0, len(changes))
+	for i := range changes {
+		responses = append(responses, changes[i].ToResponse())
+	}
+
+	return responses, nil
+}
+
+// changeStatus moves the account to a new status and records who did it and
+// why. The account row is locked so the balance checked when closing can not
+// change underneath.
+func (s *AccountService) changeStatus(
+	ctx context.Context,
+	accountID int64,
+	status string,
+	req models.ChangeAccountStatusRequest,
+) (*models.AccountResponse, error) {
+	if accountID <= 0 {
+		return nil, models.ErrInvalidAccountID
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var account *models.Account
+	err := s.transactor.run(ctx, "change_account_status", repository.TxOptions{}, func(tx repository.Tx) error {
+		var err error
+		if account, err = s.lockAccount(ctx, tx, accountID); err != nil {
+			return err
+		}
+
+		if err := account.Transition(status); err != nil {
+			return err
+		}
+
+		change := &models.AccountStatusChange{
+			AccountID:  account.ID,
+			FromStatus: account.Status,
+			ToStatus:   status,
+			Reason:     strings.TrimSpace(req.Reason),
+			Actor:      strings.TrimSpace(req.Actor),
+		}
+
+		account.Status = status
+		if err := s.accountRepo.UpdateStatus(ctx, tx, account); err != nil {
+			return err
+		}
+		return s.accountRepo.CreateStatusChange(ctx, tx, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := account.ToResponse()
+	return &response, nil
+}
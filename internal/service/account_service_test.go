@@ -0,0 +1,45 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOverdraftLimit_CountsHolds(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		require.NoError(t, l.accounts.CreateAccount(ctx, models.CreateAccountRequest{
+			AccountID:             1,
+			InitialBalance:        "0",
+			OverdraftLimitRequest: models.OverdraftLimitRequest{OverdraftLimit: "100.00"},
+		}, models.RequestKey{}))
+		l.createAccount(t, 2, "0")
+
+		hold, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               "50.00",
+			Pending:              true,
+		}, models.RequestKey{})
+		require.NoError(t, err)
+
+		// The balance is still zero, but the hold already spends 50.00 of
+		// the limit.
+		_, err = l.accounts.SetOverdraftLimit(ctx, 1, models.OverdraftLimitRequest{OverdraftLimit: "10.00"})
+		assert.ErrorIs(t, err, models.ErrOverdraftLimitExceeded)
+
+		account, err := l.accounts.SetOverdraftLimit(ctx, 1, models.OverdraftLimitRequest{OverdraftLimit: "50.00"})
+		require.NoError(t, err)
+		assert.Equal(t, models.Decimal("50.00"), *account.OverdraftLimit)
+
+		_, err = l.transfers.Capture(ctx, hold.TransactionID, models.CaptureRequest{})
+		require.NoError(t, err)
+		balance, _ := l.balance(t, 1)
+		assert.Equal(t, models.Decimal("-50.00"), balance)
+		l.assertReconciled(t)
+	})
+}
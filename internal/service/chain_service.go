@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// chainBatchSize bounds how many transactions of a chain are loaded at a time.
+const chainBatchSize = 500
+
+// ChainService verifies the hash chains that transactions are sealed into
+// when they are created.
+type ChainService struct {
+	txnRepo repository.TransactionRepository
+}
+
+func NewChainService(txnRepo repository.TransactionRepository) *ChainService {
+	return &ChainService{txnRepo: txnRepo}
+}
+
+// Verify walks every account's chain from the start and reports where each
+// first breaks, along with any transaction created since chaining began that
+// was never sealed. Deleting the newest records of a chain can not be told
+// apart from them never having been written.
+func (s *ChainService) Verify(ctx context.Context) (*models.ChainReport, error) {
+	accounts, err := s.txnRepo.ListChainAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ChainReport{}
+	for _, accountID := range accounts {
+		checked, chainBreak, err := s.verifyChain(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		report.AccountsChecked++
+		report.TransactionsChecked += checked
+		if chainBreak != nil {
+			report.Breaks = append(report.Breaks, *chainBreak)
+		}
+	}
+
+	firstSealedAt, err := s.txnRepo.FirstSealedAt(ctx)
+	if err != nil || firstSealedAt == nil {
+		return report, err
+	}
+
+	unsealed, err := s.txnRepo.ListUnsealed(ctx, *firstSealedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range unsealed {
+		report.Breaks = append(report.Breaks, models.ChainBreak{
+			AccountID:     transaction.ChainAccount(),
+			TransactionID: transaction.ID,
+			Reason:        models.ChainBreakUnsealed,
+		})
+	}
+
+	return report, nil
+}
+
+// verifyChain checks one account's chain up to its first break. A broken
+// link means the previous record was rewritten after the next one was
+// sealed, so the previous record is the one reported.
+func (s *ChainService) verifyChain(ctx context.Context, accountID int64) (int, *models.ChainBreak, error) {
+	var prev models.Transaction
+	prev.Hash = models.GenesisHash
+	checked := 0
+
+	for {
+		batch, err := s.txnRepo.ListChain(ctx, accountID, prev.ChainSeq, chainBatchSize)
+		if err != nil {
+			return checked, nil, err
+		}
+
+		for _, transaction := range batch {
+			checked++
+
+			switch reason := transaction.VerifyChainLink(prev.ChainSeq, prev.Hash); reason {
+			case "":
+				prev = transaction
+			case models.ChainBreakMissing:
+				return checked, &models.ChainBreak{AccountID: accountID, Seq: prev.ChainSeq + 1, Reason: reason}, nil
+			case models.ChainBreakLink:
+				return checked, &models.ChainBreak{AccountID: accountID, Seq: prev.ChainSeq, TransactionID: prev.ID, Reason: reason}, nil
+			default:
+				return checked, &models.ChainBreak{AccountID: accountID, Seq: transaction.ChainSeq, TransactionID: transaction.ID, Reason: reason}, nil
+			}
+		}
+
+		if len(batch) < chainBatchSize {
+			return checked, nil, nil
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/fx"
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/google/uuid"
+)
+
+type FXService struct {
+	transactor transactor
+	quoteRepo  repository.QuoteRepository
+	rates      fx.RateProvider
+	quoteTTL   time.Duration
+}
+
+func NewFXService(
+	uow repository.UnitOfWork,
+	quoteRepo repository.QuoteRepository,
+	rates fx.RateProvider,
+	quoteTTL time.Duration,
+) *FXService {
+	return &FXService{
+		transactor: newTransactor(uow),
+		quoteRepo:  quoteRepo,
+		rates:      rates,
+		quoteTTL:   quoteTTL,
+	}
+}
+
+// CreateQuote fetches the current rate for a currency pair and locks it for
+// the configured TTL.
+func (s *FXService) CreateQuote(ctx context.Context, req models.CreateFXQuoteRequest) (*models.FXQuoteResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	sourceCurrency, _ := models.NormalizeCurrency(req.SourceCurrency)
+	destinationCurrency, _ := models.NormalizeCurrency(req.DestinationCurrency)
+
+	rate, err := s.rates.Rate(ctx, sourceCurrency, destinationCurrency)
+	if err != nil {
+		return nil, err
+	}
+	if err := fx.ValidateRate(rate); err != nil {
+		return nil, fmt.Errorf("rate provider returned %q: %w", rate, err)
+	}
+
+	quote := &models.FXQuote{
+		ID:                  uuid.New().String(),
+		SourceCurrency:      sourceCurrency,
+		DestinationCurrency: destinationCurrency,
+		Rate:                rate,
+		ExpiresAt:           time.Now().Add(s.quoteTTL),
+	}
+
+	err = s.transactor.run(ctx, "create_quote", repository.TxOptions{}, func(tx repository.Tx) error {
+		return s.quoteRepo.Create(ctx, tx, quote)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fx quote: %w", err)
+	}
+
+	response := quote.ToResponse()
+	return &response, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// idempotentWork names the savepoint taken once an idempotency key is
+// claimed, so a failure can be undone without releasing the key.
+const idempotentWork = "idempotent_work"
+
+// idempotency runs requests that may carry an Idempotency-Key.
+type idempotency struct {
+	transactor transactor
+	repo       repository.IdempotencyRepository
+}
+
+// run calls work in a new database transaction, in which work records what it
+// created in outcome. With a key, the key is claimed in that same transaction
+// first: a concurrent request with the same key waits until this one commits
+// and then gets its outcome. Failures that follow from the ledger's state are
+// undone and stored with the key, so retries get the same error; any other
+// failure releases the key. A transaction aborted by a conflict is run again
+// from the claim on; op names the operation in the retry metrics.
+//
+// If the key already has an outcome, work is not called: run returns the
+// stored error, or the stored key for the caller to load what it created.
+func (i *idempotency) run(
+	ctx context.Context,
+	op string,
+	opts repository.TxOptions,
+	key models.RequestKey,
+	fingerprint string,
+	work func(tx repository.Tx, outcome *models.IdempotencyKey) error,
+) (*models.IdempotencyKey, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+
+	var replayed *models.IdempotencyKey
+	var workErr error
+	err := i.transactor.run(ctx, op, opts, func(tx repository.Tx) error {
+		var err error
+		replayed, workErr, err = i.claim(ctx, tx, key, fingerprint, work)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replayed, workErr
+}
+
+// claim does what run does within tx, which the caller commits unless claim
+// returns err. The failure of work is returned as workErr if it was stored
+// with the key, and as err otherwise.
+func (i *idempotency) claim(
+	ctx context.Context,
+	tx repository.Tx,
+	key models.RequestKey,
+	fingerprint string,
+	work func(tx repository.Tx, outcome *models.IdempotencyKey) error,
+) (replayed *models.IdempotencyKey, workErr, err error) {
+	idempotent := key.Key != ""
+
+	if idempotent {
+		existing, err := i.repo.Claim(ctx, tx, key, fingerprint)
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing != nil {
+			replayed, err = replay(existing, fingerprint)
+			return replayed, nil, err
+		}
+		if err := tx.Savepoint(ctx, idempotentWork); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	outcome := &models.IdempotencyKey{ClientID: key.ClientID, Key: key.Key}
+
+	if err := work(tx, outcome); err != nil {
+		code, replayable := models.OutcomeCode(err)
+		if !idempotent || !replayable {
+			return nil, nil, err
+		}
+		if err := tx.RollbackToSavepoint(ctx, idempotentWork); err != nil {
+			return nil, nil, err
+		}
+		workErr = err
+		outcome = &models.IdempotencyKey{ClientID: key.ClientID, Key: key.Key, ErrorCode: code}
+	}
+
+	if idempotent {
+		if err := i.repo.Complete(ctx, tx, outcome); err != nil {
+			return nil, nil, err
+		}
+	}
+	return nil, workErr, nil
+}
+
+// replay returns a completed key, or its stored error, unless it was used
+// for a request with another fingerprint.
+func replay(key *models.IdempotencyKey, fingerprint string) (*models.IdempotencyKey, error) {
+	if !key.MatchesFingerprint(fingerprint) {
+		return nil, models.ErrIdempotencyKeyReused
+	}
+	if err := key.Outcome(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// purgeBatchSize bounds how many idempotency keys are deleted at a time.
+const purgeBatchSize = 1000
+
+type IdempotencyService struct {
+	transactor      transactor
+	idempotencyRepo repository.IdempotencyRepository
+	retention       time.Duration
+}
+
+// NewIdempotencyService creates a service that forgets idempotency keys once
+// they are older than retention. A retry after that runs as a new request.
+func NewIdempotencyService(uow repository.UnitOfWork, idempotencyRepo repository.IdempotencyRepository, retention time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		transactor:      newTransactor(uow),
+		idempotencyRepo: idempotencyRepo,
+		retention:       retention,
+	}
+}
+
+// PurgeExpired deletes the keys that were past the retention window by now
+// and returns how many it deleted.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.Add(-s.retention)
+
+	purged := 0
+	for {
+		var n int
+		err := s.transactor.run(ctx, "purge_idempotency_keys", repository.TxOptions{}, func(tx repository.Tx) error {
+			var err error
+			n, err = s.idempotencyRepo.PurgeBefore(ctx, tx, cutoff, purgeBatchSize)
+			return err
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged += n
+		if n < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
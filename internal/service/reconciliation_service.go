@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// ReconciliationService checks that the cached balances and transactions
+// agree with the journal, i.e. that no money was created or lost.
+type ReconciliationService struct {
+	uow                repository.UnitOfWork
+	reconciliationRepo repository.ReconciliationRepository
+}
+
+func NewReconciliationService(uow repository.UnitOfWork, reconciliationRepo repository.ReconciliationRepository) *ReconciliationService {
+	return &ReconciliationService{
+		uow:                uow,
+		reconciliationRepo: reconciliationRepo,
+	}
+}
+
+// Reconcile checks the whole ledger. All checks read the same snapshot, so
+// transfers committing meanwhile can not show up as drift.
+func (s *ReconciliationService) Reconcile(ctx context.Context) (*models.ReconciliationReport, error) {
+	tx, err := s.uow.Begin(ctx, repository.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	report := &models.ReconciliationReport{CheckedAt: time.Now().UTC()}
+
+	if report.AccountsChecked, err = s.reconciliationRepo.CountAccounts(ctx, tx); err != nil {
+		return nil, err
+	}
+	if report.Accounts, err = s.reconciliationRepo.AccountDrift(ctx, tx); err != nil {
+		return nil, err
+	}
+	if report.Entries, err = s.reconciliationRepo.UnbalancedEntries(ctx, tx); err != nil {
+		return nil, err
+	}
+	if report.Transactions, err = s.reconciliationRepo.TransactionMismatches(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// RetryPolicy bounds how often a transaction aborted by a conflict with a
+// concurrent one is run again, and how long to wait in between.
+type RetryPolicy struct {
+	// MaxAttempts counts the first run; 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the longest wait before the first retry. It doubles on
+	// every retry after that, up to MaxDelay; the actual wait is a random
+	// share of it, so the transactions that conflicted do not retry in step.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// Retries of each operation, published at /debug/vars.
+var (
+	txRetries          = expvar.NewMap("tx_retries")
+	txRetriesExhausted = expvar.NewMap("tx_retries_exhausted")
+)
+
+// transactor runs units of work in transactions, retrying those the store
+// aborted because of a conflict.
+type transactor struct {
+	uow    repository.UnitOfWork
+	policy RetryPolicy
+}
+
+func newTransactor(uow repository.UnitOfWork) transactor {
+	return transactor{uow: uow, policy: DefaultRetryPolicy}
+}
+
+// run calls fn in a new transaction and commits it unless fn fails. If the
+// store aborts the transaction because of a conflict, fn is called again in a
+// new one: it must not keep anything from a failed attempt. op names the
+// operation in the retry metrics.
+func (t transactor) run(ctx context.Context, op string, opts repository.TxOptions, fn func(tx repository.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := t.attempt(ctx, opts, fn)
+		if err == nil || !t.uow.Retryable(err) {
+			return err
+		}
+
+		if attempt >= t.policy.MaxAttempts {
+			txRetriesExhausted.Add(op, 1)
+			return err
+		}
+		txRetries.Add(op, 1)
+
+		timer := time.NewTimer(t.policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func (t transactor) attempt(ctx context.Context, opts repository.TxOptions, fn func(tx repository.Tx) error) error {
+	tx, err := t.uow.Begin(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// backoff returns how long to wait before retrying after the given attempt:
+// a random duration up to BaseDelay doubled for each earlier retry, capped
+// at MaxDelay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay
+	for i := 1; i < attempt && ceiling < p.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, p.MaxDelay)
+
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+type SnapshotService struct {
+	transactor   transactor
+	snapshotRepo repository.SnapshotRepository
+	settleDelay  time.Duration
+}
+
+// NewSnapshotService creates a service that snapshots days once settleDelay
+// has passed since they ended. The delay must be longer than any transfer
+// can take to commit.
+func NewSnapshotService(uow repository.UnitOfWork, snapshotRepo repository.SnapshotRepository, settleDelay time.Duration) *SnapshotService {
+	return &SnapshotService{
+		transactor:   newTransactor(uow),
+		snapshotRepo: snapshotRepo,
+		settleDelay:  settleDelay,
+	}
+}
+
+// TakeSnapshots writes end-of-day snapshots for every settled day after the
+// latest snapshot, or after the first posting if there are none, and returns
+// the number of days snapshotted. Each day builds on the one before it.
+func (s *SnapshotService) TakeSnapshots(ctx context.Context, now time.Time) (int, error) {
+	next, err := s.nextDayEnd(ctx)
+	if err != nil || next == nil {
+		return 0, err
+	}
+
+	settled := models.LastSettledDayEnd(now, s.settleDelay)
+
+	days := 0
+	for day := *next; !day.After(settled); day = day.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return days, err
+		}
+		err := s.transactor.run(ctx, "take_snapshots", repository.TxOptions{}, func(tx repository.Tx) error {
+			_, err := s.snapshotRepo.CreateAll(ctx, tx, day)
+			return err
+		})
+		if err != nil {
+			return days, err
+		}
+		days++
+	}
+
+	return days, nil
+}
+
+func (s *SnapshotService) nextDayEnd(ctx context.Context) (*time.Time, error) {
+	latest, err := s.snapshotRepo.LatestAsOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		next := models.DayEnd(*latest).AddDate(0, 0, 1)
+		return &next, nil
+	}
+
+	first, err := s.snapshotRepo.FirstPostingAt(ctx)
+	if err != nil || first == nil {
+		return nil, err
+	}
+
+	next := models.DayEnd(*first)
+	return &next, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/google/uuid"
+)
+
+// TransactionService serves read access to recorded transactions.
+type TransactionService struct {
+	accountRepo repository.AccountRepository
+	txnRepo     repository.TransactionRepository
+}
+
+func NewTransactionService(
+	accountRepo repository.AccountRepository,
+	txnRepo repository.TransactionRepository,
+) *TransactionService {
+	return &TransactionService{
+		accountRepo: accountRepo,
+		txnRepo:     txnRepo,
+	}
+}
+
+func (s *TransactionService) GetTransaction(ctx context.Context, id string) (*models.TransactionResponse, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, models.ErrTransactionNotFound
+	}
+
+	transaction, err := s.txnRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := transaction.ToResponse()
+	return &response, nil
+}
+
+// ListAccountTransactions returns one page of an account's history, newest
+// first. Amount filters are read in the account's currency.
+func (s *TransactionService) ListAccountTransactions(
+	ctx context.Context,
+	accountID int64,
+	req models.ListTransactionsRequest,
+) (*models.TransactionPageResponse, error) {
+	if accountID <= 0 {
+		return nil, models.ErrInvalidAccountID
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := req.Filter(account.ID, account.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.txnRepo.ListByAccount(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.TransactionPageResponse{
+		Transactions: make([]models.AccountTransactionResponse, 0, len(transactions)),
+	}
+	for i := range transactions {
+		page.Transactions = append(page.Transactions, transactions[i].ToResponse(account.Currency))
+	}
+
+	if len(transactions) == filter.Limit {
+		last := transactions[len(transactions)-1]
+		page.NextCursor = models.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return page, nil
+}
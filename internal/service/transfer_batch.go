@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+)
+
+// TransferBatch runs the transfers of a batch in order. Each runs as Transfer
+// would with its own idempotency key, scoped to clientID. In best effort mode
+// every transfer commits or fails on its own; in atomic mode they run in one
+// database transaction that commits only if all of them succeed.
+func (s *TransferService) TransferBatch(
+	ctx context.Context,
+	req models.BatchTransferRequest,
+	clientID string,
+) (*models.BatchTransferResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []models.BatchTransferResult
+	var err error
+	if req.Mode == models.BatchModeAtomic {
+		results, err = s.transferAtomic(ctx, req.Transfers, clientID)
+	} else {
+		results = s.transferBestEffort(ctx, req.Transfers, clientID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.BatchTransferResponse{Mode: req.Mode, Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case models.BatchItemCompleted:
+			response.Succeeded++
+		case models.BatchItemFailed:
+			response.Failed++
+		}
+	}
+
+	return response, nil
+}
+
+func (s *TransferService) transferBestEffort(
+	ctx context.Context,
+	items []models.BatchTransferItem,
+	clientID string,
+) []models.BatchTransferResult {
+	results := newBatchResults(items)
+	for i, item := range items {
+		key := models.RequestKey{ClientID: clientID, Key: item.IdempotencyKey}
+		transaction, err := s.Transfer(ctx, item.CreateTransactionRequest, key)
+		if err != nil {
+			results[i].Status = models.BatchItemFailed
+			results[i].Err = err
+			continue
+		}
+		results[i].Status = models.BatchItemCompleted
+		results[i].Transaction = transaction
+	}
+	return results
+}
+
+// transferAtomic runs the transfers in one transaction. Every account of the
+// batch is locked first, in ascending ID order like a single transfer locks
+// its own, so that batches can not deadlock with each other or with single
+// transfers. A transfer that fails rolls the whole batch back, including
+// what its idempotency keys would have recorded. The error it returns is
+// only for a batch that failed as a whole.
+func (s *TransferService) transferAtomic(
+	ctx context.Context,
+	items []models.BatchTransferItem,
+	clientID string,
+) ([]models.BatchTransferResult, error) {
+	for i := range items {
+		if err := items[i].Validate(); err != nil {
+			return failBatch(items, i, err), nil
+		}
+	}
+
+	var created []*models.Transaction
+	var replayed []*models.IdempotencyKey
+	failed := -1
+
+	err := s.transactor.run(ctx, "transfer_batch", s.txOptions, func(tx repository.Tx) error {
+		created = make([]*models.Transaction, len(items))
+		replayed = make([]*models.IdempotencyKey, len(items))
+		failed = -1
+
+		if index, err := s.lockBatch(ctx, tx, items); err != nil {
+			failed = index
+			return err
+		}
+
+		for i, item := range items {
+			key := models.RequestKey{ClientID: clientID, Key: item.IdempotencyKey}
+			if err := key.Validate(); err != nil {
+				failed = i
+				return err
+			}
+
+			work := func(tx repository.Tx, outcome *models.IdempotencyKey) error {
+				var err error
+				if created[i], err = s.transfer(ctx, tx, item.CreateTransactionRequest); err != nil {
+					return err
+				}
+				outcome.TransactionID = &created[i].ID
+				return nil
+			}
+
+			var workErr, err error
+			replayed[i], workErr, err = s.idempotency.claim(ctx, tx, key, item.Fingerprint(), work)
+			if err == nil {
+				err = workErr
+			}
+			if err == nil && replayed[i] != nil && replayed[i].TransactionID == nil {
+				// The key was used to open an account.
+				err = models.ErrIdempotencyKeyReused
+			}
+			if err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if failed < 0 || s.transactor.uow.Retryable(err) {
+			return nil, err
+		}
+		return failBatch(items, failed, err), nil
+	}
+
+	results := newBatchResults(items)
+	for i := range items {
+		transaction, err := s.idempotentResponse(ctx, created[i], replayed[i])
+		if err != nil {
+			return nil, err
+		}
+		results[i].Status = models.BatchItemCompleted
+		results[i].Transaction = transaction
+	}
+
+	return results, nil
+}
+
+// lockBatch locks every account the batch touches in ascending ID order: for
+// update if any transfer debits it, else as lockAccounts would for a credit.
+// On failure it returns the index of the first transfer that touches the
+// account it could not lock.
+func (s *TransferService) lockBatch(ctx context.Context, tx repository.Tx, items []models.BatchTransferItem) (int, error) {
+	debited := map[int64]bool{}
+	firstUse := map[int64]int{}
+	for i, item := range items {
+		sources, destinations := transferAccounts(item.CreateTransactionRequest)
+		for _, id := range sources {
+			debited[id] = true
+		}
+		for _, id := range slices.Concat(sources, destinations) {
+			if _, ok := firstUse[id]; !ok {
+				firstUse[id] = i
+			}
+		}
+	}
+
+	ids := make([]int64, 0, len(firstUse))
+	for id := range firstUse {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		var err error
+		if debited[id] {
+			_, _, err = s.lockAccounts(ctx, tx, []int64{id}, nil)
+		} else {
+			_, _, err = s.lockAccounts(ctx, tx, nil, []int64{id})
+		}
+		if err != nil {
+			return firstUse[id], err
+		}
+	}
+
+	return -1, nil
+}
+
+// transferAccounts returns the accounts a transfer debits and credits.
+func transferAccounts(req models.CreateTransactionRequest) (sources, destinations []int64) {
+	if !req.IsMultiLeg() {
+		return []int64{req.SourceAccountID}, []int64{req.DestinationAccountID}
+	}
+	for _, leg := range req.Sources {
+		sources = append(sources, leg.AccountID)
+	}
+	for _, leg := range req.Destinations {
+		destinations = append(destinations, leg.AccountID)
+	}
+	return sources, destinations
+}
+
+func newBatchResults(items []models.BatchTransferItem) []models.BatchTransferResult {
+	results := make([]models.BatchTransferResult, len(items))
+	for i, item := range items {
+		results[i] = models.BatchTransferResult{Index: i, IdempotencyKey: item.IdempotencyKey}
+	}
+	return results
+}
+
+// failBatch reports an atomic batch in which the transfer at index failed:
+// those before it were rolled back and those after it never ran.
+func failBatch(items []models.BatchTransferItem, index int, err error) []models.BatchTransferResult {
+	results := newBatchResults(items)
+	for i := range results {
+		switch {
+		case i < index:
+			results[i].Status = models.BatchItemRolledBack
+		case i == index:
+			results[i].Status = models.BatchItemFailed
+			results[i].Err = err
+		default:
+			results[i].Status = models.BatchItemSkipped
+		}
+	}
+	return results
+}
@@ -2,120 +2,716 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/filipe/financial-ledger-project/internal/fx"
 	"github.com/filipe/financial-ledger-project/internal/models"
 	"github.com/filipe/financial-ledger-project/internal/repository"
 	"github.com/google/uuid"
 )
 
 type TransferService struct {
-	db          *sql.DB
-	accountRepo *repository.AccountRepository
-	txnRepo     *repository.TransactionRepository
+	transactor  transactor
+	txOptions   repository.TxOptions
+	accountRepo repository.AccountRepository
+	txnRepo     repository.TransactionRepository
+	journalRepo repository.JournalRepository
+	quoteRepo   repository.QuoteRepository
+	idempotency idempotency
+	holdTTL     time.Duration
 }
 
+// expireHoldsBatchSize bounds how many expired holds are loaded at a time.
+const expireHoldsBatchSize = 100
+
 func NewTransferService(
-	db *sql.DB,
-	accountRepo *repository.AccountRepository,
-	txnRepo *repository.TransactionRepository,
+	uow repository.UnitOfWork,
+	accountRepo repository.AccountRepository,
+	txnRepo repository.TransactionRepository,
+	journalRepo repository.JournalRepository,
+	quoteRepo repository.QuoteRepository,
+	idempotencyRepo repository.IdempotencyRepository,
+	holdTTL time.Duration,
 ) *TransferService {
+	transactor := newTransactor(uow)
 	return &TransferService{
-		db:          db,
+		transactor:  transactor,
 		accountRepo: accountRepo,
 		txnRepo:     txnRepo,
+		journalRepo: journalRepo,
+		quoteRepo:   quoteRepo,
+		idempotency: idempotency{transactor: transactor, repo: idempotencyRepo},
+		holdTTL:     holdTTL,
 	}
 }
 
+// UseSerializable runs the transactions that move money at SERIALIZABLE
+// isolation rather than READ COMMITTED. The row locks taken in lock order
+// already keep balances consistent; this also guards reads that take no
+// lock, at the cost of more transactions aborted and retried.
+func (s *TransferService) UseSerializable(enabled bool) {
+	s.txOptions.Serializable = enabled
+}
+
 func (s *TransferService) Transfer(
 	ctx context.Context,
 	req models.CreateTransactionRequest,
-	idempotencyKey string,
+	key models.RequestKey,
 ) (*models.TransactionResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
 
-	if idempotencyKey != "" {
-		if existingTxn, err := s.txnRepo.GetByIdempotencyKey(ctx, idempotencyKey); err == nil {
-			response := existingTxn.ToResponse()
-			return &response, nil
-		} else if !errors.Is(err, models.ErrTransactionNotFound) {
-			return nil, fmt.Errorf("failed to check idempotency: %w", err)
+	var transaction *models.Transaction
+	replayed, err := s.idempotency.run(ctx, "transfer", s.txOptions, key, req.Fingerprint(), func(tx repository.Tx, outcome *models.IdempotencyKey) error {
+		var err error
+		if transaction, err = s.transfer(ctx, tx, req); err != nil {
+			return err
 		}
+		outcome.TransactionID = &transaction.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	amountInCents := models.FloatToCents(req.Amount)
-	if amountInCents <= 0 {
-		return nil, models.ErrInvalidAmount
+	return s.idempotentResponse(ctx, transaction, replayed)
+}
+
+func (s *TransferService) transfer(
+	ctx context.Context,
+	tx repository.Tx,
+	req models.CreateTransactionRequest,
+) (*models.Transaction, error) {
+	var transaction *models.Transaction
+	var shared map[int64]bool
+	var err error
+	if req.IsMultiLeg() {
+		transaction, shared, err = s.prepareMultiLeg(ctx, tx, req)
+	} else {
+		transaction, shared, err = s.prepareTransfer(ctx, tx, req)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	tx, err := s.db.BeginTx(ctx, nil)
+	transaction.ID = uuid.New().String()
+	transaction.Status = models.StatusCompleted
+
+	if req.Pending {
+		expiresAt := time.Now().Add(s.holdTTL)
+		transaction.Status = models.StatusPending
+		transaction.AuthorizedAmount = transaction.Amount
+		transaction.HoldExpiresAt = &expiresAt
+	}
+
+	if err := s.txnRepo.Create(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction record: %w", err)
+	}
+	if err := s.seal(ctx, tx, transaction); err != nil {
+		return nil, err
+	}
+
+	// A pending transfer only reserves the funds; nothing reaches the
+	// journal until it is captured.
+	if req.Pending {
+		if err := s.accountRepo.AdjustHold(ctx, tx, transaction.SourceAccountID, transaction.Amount); err != nil {
+			return nil, err
+		}
+	} else {
+		entry := models.NewTransferEntry(uuid.New().String(), transaction)
+		entry.CreatedAt = transaction.CreatedAt
+		entry.DeferCredits(shared)
+		if err := s.journalRepo.Post(ctx, tx, entry); err != nil {
+			return nil, fmt.Errorf("failed to post journal entry: %w", err)
+		}
+	}
+
+	return transaction, nil
+}
+
+// Capture settles a pending transfer for all or part of its authorized
+// amount. The whole hold is released and the captured amount is posted to
+// the journal; a cross-currency transfer is settled at its authorized rate.
+func (s *TransferService) Capture(
+	ctx context.Context,
+	transactionID string,
+	req models.CaptureRequest,
+) (*models.TransactionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(transactionID); err != nil {
+		return nil, models.ErrTransactionNotFound
+	}
+
+	var transaction *models.Transaction
+	err := s.transactor.run(ctx, "capture", s.txOptions, func(tx repository.Tx) error {
+		var err error
+		transaction, err = s.capture(ctx, tx, transactionID, req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, err
 	}
-	defer tx.Rollback()
 
-	var sourceAccount, destAccount *models.Account
-	if req.SourceAccountID < req.DestinationAccountID {
-		sourceAccount, err = s.accountRepo.GetForUpdate(ctx, tx, req.SourceAccountID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get source account: %w", err)
+	response := transaction.ToResponse()
+	return &response, nil
+}
+
+func (s *TransferService) capture(
+	ctx context.Context,
+	tx repository.Tx,
+	transactionID string,
+	req models.CaptureRequest,
+) (*models.Transaction, error) {
+	transaction, err := s.txnRepo.GetForUpdate(ctx, tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := transaction.CaptureAmount(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, shared, err := s.lockAccounts(ctx, tx, []int64{transaction.SourceAccountID}, []int64{transaction.DestinationAccountID})
+	if err != nil {
+		return nil, err
+	}
+
+	destAmount := amount
+	if transaction.FXRate != nil {
+		destAmount = transaction.DestinationAmount
+		if amount != transaction.AuthorizedAmount {
+			destAmount, err = fx.Convert(amount, transaction.Currency, transaction.DestinationCurrency, *transaction.FXRate)
+			if err != nil {
+				return nil, err
+			}
 		}
-		destAccount, err = s.accountRepo.GetForUpdate(ctx, tx, req.DestinationAccountID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get destination account: %w", err)
+		if destAmount <= 0 {
+			return nil, models.ErrInvalidAmount
 		}
-	} else {
-		destAccount, err = s.accountRepo.GetForUpdate(ctx, tx, req.DestinationAccountID)
+	}
+
+	// The hold was taken when the transfer was authorized, but a capture
+	// still moves money and so still follows the accounts' statuses.
+	if err := accounts[transaction.SourceAccountID].CanSend(); err != nil {
+		return nil, err
+	}
+	if err := accounts[transaction.DestinationAccountID].CanReceive(); err != nil {
+		return nil, err
+	}
+
+	if !accounts[transaction.SourceAccountID].CanCover(amount - transaction.AuthorizedAmount) {
+		return nil, models.ErrInsufficientFunds
+	}
+
+	if err := s.accountRepo.AdjustHold(ctx, tx, transaction.SourceAccountID, -transaction.AuthorizedAmount); err != nil {
+		return nil, err
+	}
+
+	transaction.Amount = amount
+	transaction.DestinationAmount = destAmount
+	transaction.Status = models.StatusCompleted
+	transaction.Settle()
+	if err := s.txnRepo.Update(ctx, tx, transaction); err != nil {
+		return nil, err
+	}
+
+	entry := models.NewTransferEntry(uuid.New().String(), transaction)
+	entry.DeferCredits(shared)
+	if err := s.journalRepo.Post(ctx, tx, entry); err != nil {
+		return nil, fmt.Errorf("failed to post journal entry: %w", err)
+	}
+
+	return transaction, nil
+}
+
+// Void cancels a pending transfer and releases its hold.
+func (s *TransferService) Void(ctx context.Context, transactionID string) (*models.TransactionResponse, error) {
+	if _, err := uuid.Parse(transactionID); err != nil {
+		return nil, models.ErrTransactionNotFound
+	}
+	return s.releaseHold(ctx, transactionID, models.StatusVoided)
+}
+
+// ExpireHolds releases the holds of pending transfers that expired by now and
+// returns how many it released.
+func (s *TransferService) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	expired := 0
+	for {
+		ids, err := s.txnRepo.ListExpiredHolds(ctx, now, expireHoldsBatchSize)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get destination account: %w", err)
+			return expired, err
+		}
+
+		for _, id := range ids {
+			_, err := s.releaseHold(ctx, id, models.StatusExpired)
+			if errors.Is(err, models.ErrNotPending) {
+				// Captured or voided since it was listed.
+				continue
+			}
+			if err != nil {
+				return expired, fmt.Errorf("failed to expire hold %s: %w", id, err)
+			}
+			expired++
 		}
-		sourceAccount, err = s.accountRepo.GetForUpdate(ctx, tx, req.SourceAccountID)
+
+		if len(ids) < expireHoldsBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// releaseHold ends a pending transfer with the given status without moving
+// any money.
+func (s *TransferService) releaseHold(ctx context.Context, transactionID, status string) (*models.TransactionResponse, error) {
+	var transaction *models.Transaction
+	err := s.transactor.run(ctx, "release_hold", s.txOptions, func(tx repository.Tx) error {
+		var err error
+		if transaction, err = s.txnRepo.GetForUpdate(ctx, tx, transactionID); err != nil {
+			return err
+		}
+		if transaction.Status != models.StatusPending {
+			return models.ErrNotPending
+		}
+
+		if _, _, err := s.lockAccounts(ctx, tx, []int64{transaction.SourceAccountID}, nil); err != nil {
+			return err
+		}
+		if err := s.accountRepo.AdjustHold(ctx, tx, transaction.SourceAccountID, -transaction.AuthorizedAmount); err != nil {
+			return err
+		}
+
+		transaction.Status = status
+		transaction.Settle()
+		return s.txnRepo.Update(ctx, tx, transaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := transaction.ToResponse()
+	return &response, nil
+}
+
+// Reverse returns all or part of a completed transaction to its source by
+// recording a linked compensating transaction. The original is locked so
+// concurrent reversals can not together exceed its amount.
+func (s *TransferService) Reverse(
+	ctx context.Context,
+	transactionID string,
+	req models.CreateReversalRequest,
+	key models.RequestKey,
+) (*models.TransactionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(transactionID); err != nil {
+		return nil, models.ErrTransactionNotFound
+	}
+
+	var reversal *models.Transaction
+	replayed, err := s.idempotency.run(ctx, "reverse", s.txOptions, key, req.Fingerprint(transactionID), func(tx repository.Tx, outcome *models.IdempotencyKey) error {
+		var err error
+		if reversal, err = s.reverse(ctx, tx, transactionID, req); err != nil {
+			return err
+		}
+		outcome.TransactionID = &reversal.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.idempotentResponse(ctx, reversal, replayed)
+}
+
+func (s *TransferService) reverse(
+	ctx context.Context,
+	tx repository.Tx,
+	transactionID string,
+	req models.CreateReversalRequest,
+) (*models.Transaction, error) {
+	original, err := s.txnRepo.GetForUpdate(ctx, tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := original.ReversalAmount(req)
+	if err != nil {
+		return nil, err
+	}
+
+	destAmount, err := reversalDestinationAmount(original, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	reversal := original.NewReversal(amount, destAmount)
+	shared, err := s.checkReversal(ctx, tx, reversal)
+	if err != nil {
+		return nil, err
+	}
+
+	reversal.ID = uuid.New().String()
+	reversal.Status = models.StatusCompleted
+
+	if err := s.txnRepo.Create(ctx, tx, reversal); err != nil {
+		return nil, fmt.Errorf("failed to create reversal record: %w", err)
+	}
+	if err := s.seal(ctx, tx, reversal); err != nil {
+		return nil, err
+	}
+
+	entry := models.NewTransferEntry(uuid.New().String(), reversal)
+	entry.CreatedAt = reversal.CreatedAt
+	entry.DeferCredits(shared)
+	if err := s.journalRepo.Post(ctx, tx, entry); err != nil {
+		return nil, fmt.Errorf("failed to post journal entry: %w", err)
+	}
+
+	original.ApplyReversal(amount)
+	if err := s.txnRepo.Update(ctx, tx, original); err != nil {
+		return nil, err
+	}
+
+	return reversal, nil
+}
+
+// seal appends a newly created transaction to the hash chain of its chain
+// account, which the caller must have locked.
+func (s *TransferService) seal(ctx context.Context, tx repository.Tx, transaction *models.Transaction) error {
+	seq, hash, err := s.txnRepo.ChainHead(ctx, tx, transaction.ChainAccount())
+	if err != nil {
+		return err
+	}
+
+	transaction.Seal(seq, hash)
+	return s.txnRepo.Seal(ctx, tx, transaction)
+}
+
+// idempotentResponse returns the transaction a request created, or for a
+// replayed key the one created by the request that first used it.
+func (s *TransferService) idempotentResponse(
+	ctx context.Context,
+	created *models.Transaction,
+	replayed *models.IdempotencyKey,
+) (*models.TransactionResponse, error) {
+	if replayed != nil {
+		if replayed.TransactionID == nil {
+			return nil, models.ErrIdempotencyKeyReused
+		}
+
+		var err error
+		created, err = s.txnRepo.GetByID(ctx, *replayed.TransactionID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get source account: %w", err)
+			return nil, fmt.Errorf("failed to get idempotent transaction: %w", err)
 		}
 	}
 
-	if sourceAccount.Balance < amountInCents {
-		return nil, models.ErrInsufficientFunds
+	response := created.ToResponse()
+	return &response, nil
+}
+
+// reversalDestinationAmount is the share of the original's destination
+// amount that reversing amount more of it takes back. Cross-currency shares
+// use the original rate and are taken as the difference between the
+// converted running totals, so that a transfer reversed in several parts
+// takes back exactly its destination amount.
+func reversalDestinationAmount(original *models.Transaction, amount int64) (int64, error) {
+	if original.FXRate == nil {
+		return amount, nil
 	}
 
-	newSourceBalance := sourceAccount.Balance - amountInCents
-	newDestBalance := destAccount.Balance + amountInCents
+	convert := func(total int64) (int64, error) {
+		if total == original.Amount {
+			return original.DestinationAmount, nil
+		}
+		return fx.Convert(total, original.Currency, original.DestinationCurrency, *original.FXRate)
+	}
 
-	if err := s.accountRepo.UpdateBalance(ctx, tx, sourceAccount.ID, newSourceBalance); err != nil {
-		return nil, fmt.Errorf("failed to update source balance: %w", err)
+	before, err := convert(original.ReversedAmount)
+	if err != nil {
+		return 0, err
+	}
+	after, err := convert(original.ReversedAmount + amount)
+	if err != nil {
+		return 0, err
 	}
 
-	if err := s.accountRepo.UpdateBalance(ctx, tx, destAccount.ID, newDestBalance); err != nil {
-		return nil, fmt.Errorf("failed to update destination balance: %w", err)
+	if after-before <= 0 {
+		return 0, models.ErrInvalidAmount
+	}
+	return after - before, nil
+}
+
+// checkReversal locks the accounts of a reversal and checks that the
+// accounts it debits, the original's destinations, can send and cover the
+// amount, and that the accounts it credits can receive. It returns the
+// accounts locked shared, as lockAccounts does.
+func (s *TransferService) checkReversal(ctx context.Context, tx repository.Tx, reversal *models.Transaction) (map[int64]bool, error) {
+	debits, credits := reversal.Sources, reversal.Destinations
+	if !reversal.IsMultiLeg() {
+		debits = []models.Leg{{AccountID: reversal.SourceAccountID, Amount: reversal.Amount}}
+		credits = []models.Leg{{AccountID: reversal.DestinationAccountID, Amount: reversal.DestinationAmount}}
+	}
+
+	accounts, shared, err := s.lockAccounts(ctx, tx, legAccounts(debits), legAccounts(credits))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leg := range debits {
+		account := accounts[leg.AccountID]
+		if err := account.CanSend(); err != nil {
+			return nil, err
+		}
+		if !account.CanCover(leg.Amount) {
+			return nil, models.ErrInsufficientFunds
+		}
+	}
+	for _, leg := range credits {
+		if err := accounts[leg.AccountID].CanReceive(); err != nil {
+			return nil, err
+		}
+	}
+
+	return shared, nil
+}
+
+// prepareTransfer locks the two accounts of a simple transfer and checks
+// their statuses and balance allow it, converting the amount through an FX
+// quote if the currencies differ. It returns the accounts locked shared, as
+// lockAccounts does.
+func (s *TransferService) prepareTransfer(
+	ctx context.Context,
+	tx repository.Tx,
+	req models.CreateTransactionRequest,
+) (*models.Transaction, map[int64]bool, error) {
+	accounts, shared, err := s.lockAccounts(ctx, tx, []int64{req.SourceAccountID}, []int64{req.DestinationAccountID})
+	if err != nil {
+		return nil, nil, err
+	}
+	sourceAccount := accounts[req.SourceAccountID]
+	destAccount := accounts[req.DestinationAccountID]
+
+	if err := sourceAccount.CanSend(); err != nil {
+		return nil, nil, err
+	}
+	if err := destAccount.CanReceive(); err != nil {
+		return nil, nil, err
+	}
+
+	amount, err := req.AmountIn(sourceAccount.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	destAmount := amount
+	var quote *models.FXQuote
+	if sourceAccount.Currency != destAccount.Currency || req.QuoteID != "" {
+		quote, err = s.getQuote(ctx, req.QuoteID, sourceAccount, destAccount)
+		if err != nil {
+			return nil, nil, err
+		}
+		destAmount, err = fx.Convert(amount, quote.SourceCurrency, quote.DestinationCurrency, quote.Rate)
+		if err != nil {
+			return nil, nil, err
+		}
+		if destAmount <= 0 {
+			return nil, nil, models.ErrInvalidAmount
+		}
 	}
 
-	var idempotencyKeyPtr *string
-	if idempotencyKey != "" {
-		idempotencyKeyPtr = &idempotencyKey
+	if !sourceAccount.CanCover(amount) {
+		return nil, nil, models.ErrInsufficientFunds
 	}
 
 	transaction := &models.Transaction{
-		ID:                   uuid.New().String(),
 		SourceAccountID:      req.SourceAccountID,
 		DestinationAccountID: req.DestinationAccountID,
-		Amount:               amountInCents,
-		Status:               "COMPLETED",
-		IdempotencyKey:       idempotencyKeyPtr,
+		Amount:               amount,
+		Currency:             sourceAccount.Currency,
+		DestinationAmount:    destAmount,
+		DestinationCurrency:  destAccount.Currency,
 	}
 
-	if err := s.txnRepo.Create(ctx, tx, transaction); err != nil {
-		return nil, fmt.Errorf("failed to create transaction record: %w", err)
+	if quote != nil {
+		transaction.FXRate = &quote.Rate
+		transaction.FXQuoteID = &quote.ID
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	return transaction, shared, nil
+}
+
+// prepareMultiLeg locks every account of a multi-leg transaction and checks
+// that they share a currency, that their statuses allow the legs and that
+// each source can cover its leg within its overdraft limit. It returns the
+// accounts locked shared, as lockAccounts does.
+func (s *TransferService) prepareMultiLeg(
+	ctx context.Context,
+	tx repository.Tx,
+	req models.CreateTransactionRequest,
+) (*models.Transaction, map[int64]bool, error) {
+	sources, destinations := transferAccounts(req)
+	accounts, shared, err := s.lockAccounts(ctx, tx, sources, destinations)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	response := transaction.ToResponse()
-	return &response, nil
+	currency := accounts[req.Sources[0].AccountID].Currency
+	transaction := &models.Transaction{
+		Currency:            currency,
+		DestinationCurrency: currency,
+	}
+
+	for _, legReq := range req.Sources {
+		account := accounts[legReq.AccountID]
+		if err := account.CanSend(); err != nil {
+			return nil, nil, err
+		}
+		if account.Currency != currency {
+			return nil, nil, &models.CurrencyMismatchError{SourceCurrency: currency, DestinationCurrency: account.Currency}
+		}
+		amount, err := legReq.AmountIn(currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !account.CanCover(amount) {
+			return nil, nil, models.ErrInsufficientFunds
+		}
+		transaction.Sources = append(transaction.Sources, models.Leg{AccountID: account.ID, Amount: amount})
+		transaction.Amount += amount
+	}
+
+	for _, legReq := range req.Destinations {
+		account := accounts[legReq.AccountID]
+		if err := account.CanReceive(); err != nil {
+			return nil, nil, err
+		}
+		if account.Currency != currency {
+			return nil, nil, &models.CurrencyMismatchError{SourceCurrency: currency, DestinationCurrency: account.Currency}
+		}
+		amount, err := legReq.AmountIn(currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		transaction.Destinations = append(transaction.Destinations, models.Leg{AccountID: account.ID, Amount: amount})
+		transaction.DestinationAmount += amount
+	}
+
+	return transaction, shared, nil
+}
+
+// lockAccounts takes row locks on the accounts a transaction debits and
+// credits in ascending ID order, so that any two transactions touching
+// overlapping accounts lock them in the same order and can not deadlock.
+//
+// A hot account that is only credited is locked shared, so credits to it do
+// not wait for each other; lockAccounts returns the set of those accounts,
+// whose credits must be deferred. Any other hot account is locked for update
+// and has its pending credits settled first, so its balance is complete.
+// Whether an account is hot is read before it is locked: should that change
+// in between, the credit is still deferred if the lock was shared, and
+// settled later.
+func (s *TransferService) lockAccounts(
+	ctx context.Context,
+	tx repository.Tx,
+	debited, credited []int64,
+) (map[int64]*models.Account, map[int64]bool, error) {
+	shared := map[int64]bool{}
+	for _, id := range credited {
+		if slices.Contains(debited, id) {
+			continue
+		}
+		account, err := s.accountRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get account %d: %w", id, err)
+		}
+		if account.Hot {
+			shared[id] = true
+		}
+	}
+
+	sorted := slices.Concat(debited, credited)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	accounts := make(map[int64]*models.Account, len(sorted))
+	for _, id := range sorted {
+		var account *models.Account
+		var err error
+		if shared[id] {
+			account, err = s.accountRepo.GetForCredit(ctx, tx, id)
+		} else {
+			account, err = s.lockForUpdate(ctx, tx, id)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get account %d: %w", id, err)
+		}
+		accounts[id] = account
+	}
+
+	return accounts, shared, nil
+}
+
+// lockForUpdate locks the account for update and, if it is hot, settles its
+// pending credits.
+func (s *TransferService) lockForUpdate(ctx context.Context, tx repository.Tx, id int64) (*models.Account, error) {
+	account, err := s.accountRepo.GetForUpdate(ctx, tx, id)
+	if err != nil || !account.Hot {
+		return account, err
+	}
+
+	settled, err := s.accountRepo.SettleCredits(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	account.Balance += settled
+	return account, nil
+}
+
+func legAccounts(legs []models.Leg) []int64 {
+	ids := make([]int64, 0, len(legs))
+	for _, leg := range legs {
+		ids = append(ids, leg.AccountID)
+	}
+	return ids
+}
+
+// getQuote loads the FX quote referenced by a transfer between accounts of
+// different currencies and checks it is still valid for that pair.
+func (s *TransferService) getQuote(
+	ctx context.Context,
+	quoteID string,
+	sourceAccount, destAccount *models.Account,
+) (*models.FXQuote, error) {
+	if quoteID == "" {
+		return nil, &models.CurrencyMismatchError{
+			SourceCurrency:      sourceAccount.Currency,
+			DestinationCurrency: destAccount.Currency,
+		}
+	}
+	if _, err := uuid.Parse(quoteID); err != nil {
+		return nil, models.ErrQuoteNotFound
+	}
+
+	quote, err := s.quoteRepo.GetByID(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.SourceCurrency != sourceAccount.Currency || quote.DestinationCurrency != destAccount.Currency {
+		return nil, models.ErrQuoteMismatch
+	}
+	if quote.Expired(time.Now()) {
+		return nil, models.ErrQuoteExpired
+	}
+
+	return quote, nil
 }
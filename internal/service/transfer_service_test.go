@@ -0,0 +1,707 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/filipe/financial-ledger-project/internal/repository/memory"
+	"github.com/filipe/financial-ledger-project/internal/repository/sqlite"
+	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLedger struct {
+	accounts       *service.AccountService
+	transfers      *service.TransferService
+	transactions   *service.TransactionService
+	reconciliation *service.ReconciliationService
+	chains         *service.ChainService
+}
+
+// testStores open an empty ledger on each storage backend that needs no
+// server.
+var testStores = map[string]func(t *testing.T) *testLedger{
+	"memory": func(t *testing.T) *testLedger {
+		store := memory.NewStore()
+		return newTestLedger(
+			store,
+			memory.NewAccountRepository(store),
+			memory.NewTransactionRepository(store),
+			memory.NewJournalRepository(store),
+			memory.NewQuoteRepository(store),
+			memory.NewIdempotencyRepository(store),
+			memory.NewReconciliationRepository(store),
+		)
+	},
+	"sqlite": func(t *testing.T) *testLedger {
+		db, err := database.NewSQLiteDB(filepath.Join(t.TempDir(), "ledger.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		require.NoError(t, database.NewSQLiteMigrator(db).Up(context.Background()))
+
+		store := sqlite.NewStore(db)
+		return newTestLedger(
+			store,
+			sqlite.NewAccountRepository(store),
+			sqlite.NewTransactionRepository(store),
+			sqlite.NewJournalRepository(store),
+			sqlite.NewQuoteRepository(store),
+			sqlite.NewIdempotencyRepository(store),
+			sqlite.NewReconciliationRepository(store),
+		)
+	},
+}
+
+func newTestLedger(
+	uow repository.UnitOfWork,
+	accountRepo repository.AccountRepository,
+	transactionRepo repository.TransactionRepository,
+	journalRepo repository.JournalRepository,
+	quoteRepo repository.QuoteRepository,
+	idempotencyRepo repository.IdempotencyRepository,
+	reconciliationRepo repository.ReconciliationRepository,
+) *testLedger {
+	return &testLedger{
+		accounts:       service.NewAccountService(uow, accountRepo, journalRepo, idempotencyRepo),
+		transfers:      service.NewTransferService(uow, accountRepo, transactionRepo, journalRepo, quoteRepo, idempotencyRepo, time.Hour),
+		transactions:   service.NewTransactionService(accountRepo, transactionRepo),
+		reconciliation: service.NewReconciliationService(uow, reconciliationRepo),
+		chains:         service.NewChainService(transactionRepo),
+	}
+}
+
+// forEachStore runs test against an empty ledger on every test store.
+func forEachStore(t *testing.T, test func(t *testing.T, l *testLedger)) {
+	for name, open := range testStores {
+		t.Run(name, func(t *testing.T) {
+			test(t, open(t))
+		})
+	}
+}
+
+func (l *testLedger) createAccount(t *testing.T, id int64, balance models.Decimal) {
+	t.Helper()
+	req := models.CreateAccountRequest{AccountID: id, InitialBalance: balance}
+	require.NoError(t, l.accounts.CreateAccount(context.Background(), req, models.RequestKey{}))
+}
+
+func (l *testLedger) balance(t *testing.T, id int64) (models.Decimal, models.Decimal) {
+	t.Helper()
+	account, err := l.accounts.GetAccountBalance(context.Background(), id)
+	require.NoError(t, err)
+	return account.Balance, account.AvailableBalance
+}
+
+// assertReconciled checks that the ledger still agrees with its journal and
+// that every hash chain is intact.
+func (l *testLedger) assertReconciled(t *testing.T) {
+	t.Helper()
+	report, err := l.reconciliation.Reconcile(context.Background())
+	require.NoError(t, err)
+	assert.True(t, report.Balanced(), "ledger does not reconcile: %+v", report)
+
+	chains, err := l.chains.Verify(context.Background())
+	require.NoError(t, err)
+	assert.True(t, chains.Intact(), "hash chains are broken: %+v", chains.Breaks)
+}
+
+func TestTransfer(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "100.00")
+		l.createAccount(t, 2, "0")
+
+		response, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               "30.25",
+		}, models.RequestKey{})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCompleted, response.Status)
+
+		balance, _ := l.balance(t, 1)
+		assert.Equal(t, models.Decimal("69.75"), balance)
+		balance, _ = l.balance(t, 2)
+		assert.Equal(t, models.Decimal("30.25"), balance)
+
+		_, err = l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               "70.00",
+		}, models.RequestKey{})
+		assert.ErrorIs(t, err, models.ErrInsufficientFunds)
+
+		balance, _ = l.balance(t, 1)
+		assert.Equal(t, models.Decimal("69.75"), balance)
+		l.assertReconciled(t)
+	})
+}
+
+func TestTransfer_IdempotencyReplaysOutcome(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "10.00")
+		l.createAccount(t, 2, "0")
+
+		key := models.RequestKey{ClientID: "client", Key: "transfer-1"}
+		req := models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "6.00"}
+
+		first, err := l.transfers.Transfer(ctx, req, key)
+		require.NoError(t, err)
+		second, err := l.transfers.Transfer(ctx, req, key)
+		require.NoError(t, err)
+		assert.Equal(t, first.TransactionID, second.TransactionID)
+
+		balance, _ := l.balance(t, 1)
+		assert.Equal(t, models.Decimal("4.00"), balance)
+
+		// The failure is stored with the key and replayed, even once the
+		// source could cover the transfer.
+		failing := models.RequestKey{ClientID: "client", Key: "transfer-2"}
+		_, err = l.transfers.Transfer(ctx, req, failing)
+		assert.ErrorIs(t, err, models.ErrInsufficientFunds)
+
+		_, err = l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID: 2, DestinationAccountID: 1, Amount: "6.00",
+		}, models.RequestKey{})
+		require.NoError(t, err)
+
+		_, err = l.transfers.Transfer(ctx, req, failing)
+		assert.ErrorIs(t, err, models.ErrInsufficientFunds)
+
+		req.Amount = "1.00"
+		_, err = l.transfers.Transfer(ctx, req, key)
+		assert.ErrorIs(t, err, models.ErrIdempotencyKeyReused)
+
+		l.assertReconciled(t)
+	})
+}
+
+func TestTransfer_HoldCaptureAndReverse(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "100.00")
+		l.createAccount(t, 2, "0")
+
+		hold, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               "40.00",
+			Pending:              true,
+		}, models.RequestKey{})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusPending, hold.Status)
+
+		balance, available := l.balance(t, 1)
+		assert.Equal(t, models.Decimal("100.00"), balance)
+		assert.Equal(t, models.Decimal("60.00"), available)
+		l.assertReconciled(t)
+
+		captured, err := l.transfers.Capture(ctx, hold.TransactionID, models.CaptureRequest{Amount: "25.00"})
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusCompleted, captured.Status)
+
+		balance, available = l.balance(t, 1)
+		assert.Equal(t, models.Decimal("75.00"), balance)
+		assert.Equal(t, models.Decimal("75.00"), available)
+
+		reversal, err := l.transfers.Reverse(ctx, hold.TransactionID, models.CreateReversalRequest{Amount: "10.00"}, models.RequestKey{})
+		require.NoError(t, err)
+		assert.Equal(t, hold.TransactionID, reversal.ReversalOf)
+
+		_, err = l.transfers.Reverse(ctx, hold.TransactionID, models.CreateReversalRequest{Amount: "20.00"}, models.RequestKey{})
+		assert.ErrorIs(t, err, models.ErrReversalExceedsTotal)
+
+		balance, _ = l.balance(t, 1)
+		assert.Equal(t, models.Decimal("85.00"), balance)
+		balance, _ = l.balance(t, 2)
+		assert.Equal(t, models.Decimal("15.00"), balance)
+		l.assertReconciled(t)
+	})
+}
+
+func TestTransfer_Concurrent(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "10.00")
+		l.createAccount(t, 2, "10.00")
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded := 0
+		for i := range 40 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				source, destination := int64(1), int64(2)
+				if i%2 == 1 {
+					source, destination = destination, source
+				}
+				_, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+					SourceAccountID:      source,
+					DestinationAccountID: destination,
+					Amount:               "1.50",
+				}, models.RequestKey{})
+				if err == nil {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+					return
+				}
+				assert.ErrorIs(t, err, models.ErrInsufficientFunds)
+			}()
+		}
+		wg.Wait()
+
+		assert.Positive(t, succeeded)
+		one, _ := l.balance(t, 1)
+		two, _ := l.balance(t, 2)
+		total, err := models.DecimalToMinorUnits(one, "USD")
+		require.NoError(t, err)
+		other, err := models.DecimalToMinorUnits(two, "USD")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2000), total+other)
+		l.assertReconciled(t)
+	})
+}
+
+func TestTransfer_HotAccount(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "100.00")
+		l.createAccount(t, 3, "0")
+		require.NoError(t, l.accounts.CreateAccount(ctx, models.CreateAccountRequest{AccountID: 2, Hot: true}, models.RequestKey{}))
+
+		credit := models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "30.00"}
+		for range 2 {
+			_, err := l.transfers.Transfer(ctx, credit, models.RequestKey{})
+			require.NoError(t, err)
+		}
+
+		// Pending credits count towards the balance and reconcile.
+		balance, available := l.balance(t, 2)
+		assert.Equal(t, models.Decimal("60.00"), balance)
+		assert.Equal(t, models.Decimal("60.00"), available)
+		l.assertReconciled(t)
+
+		// A debit settles them first and can spend them, but no more.
+		_, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID: 2, DestinationAccountID: 3, Amount: "70.00",
+		}, models.RequestKey{})
+		assert.ErrorIs(t, err, models.ErrInsufficientFunds)
+
+		_, err = l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID: 2, DestinationAccountID: 3, Amount: "50.00",
+		}, models.RequestKey{})
+		require.NoError(t, err)
+
+		settled, err := l.accounts.SettleCredits(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, settled)
+
+		credit.Amount = "5.00"
+		_, err = l.transfers.Transfer(ctx, credit, models.RequestKey{})
+		require.NoError(t, err)
+
+		settled, err = l.accounts.SettleCredits(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, settled)
+
+		account, err := l.accounts.SetHotMode(ctx, 2, models.HotModeRequest{Hot: false})
+		require.NoError(t, err)
+		assert.False(t, account.Hot)
+		assert.Equal(t, models.Decimal("15.00"), account.Balance)
+		l.assertReconciled(t)
+	})
+}
+
+func TestTransferBatch_BestEffort(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "10.00")
+		l.createAccount(t, 2, "0")
+
+		batch, err := l.transfers.TransferBatch(ctx, models.BatchTransferRequest{
+			Mode: models.BatchModeBestEffort,
+			Transfers: []models.BatchTransferItem{
+				{IdempotencyKey: "a", CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "6.00"}},
+				{IdempotencyKey: "b", CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "6.00"}},
+				{IdempotencyKey: "c", CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "4.00"}},
+			},
+		}, "client")
+		require.NoError(t, err)
+		assert.Equal(t, 2, batch.Succeeded)
+		assert.Equal(t, 1, batch.Failed)
+		assert.Equal(t, models.BatchItemCompleted, batch.Results[0].Status)
+		assert.Equal(t, models.BatchItemFailed, batch.Results[1].Status)
+		assert.ErrorIs(t, batch.Results[1].Err, models.ErrInsufficientFunds)
+		assert.Equal(t, models.BatchItemCompleted, batch.Results[2].Status)
+
+		// Each key is the one a single transfer would have used.
+		replayed, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID: 1, DestinationAccountID: 2, Amount: "4.00",
+		}, models.RequestKey{ClientID: "client", Key: "c"})
+		require.NoError(t, err)
+		assert.Equal(t, batch.Results[2].Transaction.TransactionID, replayed.TransactionID)
+
+		balance, _ := l.balance(t, 2)
+		assert.Equal(t, models.Decimal("10.00"), balance)
+		l.assertReconciled(t)
+	})
+}
+
+func TestTransferBatch_Atomic(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "10.00")
+		l.createAccount(t, 2, "0")
+		l.createAccount(t, 3, "0")
+
+		req := models.BatchTransferRequest{
+			Mode: models.BatchModeAtomic,
+			Transfers: []models.BatchTransferItem{
+				{IdempotencyKey: "a", CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "6.00"}},
+				{IdempotencyKey: "b", CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 2, DestinationAccountID: 3, Amount: "2.00"}},
+				{IdempotencyKey: "c", CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 3, Amount: "6.00"}},
+			},
+		}
+
+		// One transfer failing rolls back the others.
+		batch, err := l.transfers.TransferBatch(ctx, req, "client")
+		require.NoError(t, err)
+		assert.Zero(t, batch.Succeeded)
+		assert.Equal(t, models.BatchItemRolledBack, batch.Results[0].Status)
+		assert.Equal(t, models.BatchItemRolledBack, batch.Results[1].Status)
+		assert.Equal(t, models.BatchItemFailed, batch.Results[2].Status)
+		assert.ErrorIs(t, batch.Results[2].Err, models.ErrInsufficientFunds)
+
+		balance, _ := l.balance(t, 1)
+		assert.Equal(t, models.Decimal("10.00"), balance)
+
+		// Nothing was recorded with the keys, so the corrected batch runs.
+		req.Transfers[2].Amount = "4.00"
+		batch, err = l.transfers.TransferBatch(ctx, req, "client")
+		require.NoError(t, err)
+		assert.Equal(t, 3, batch.Succeeded)
+
+		again, err := l.transfers.TransferBatch(ctx, req, "client")
+		require.NoError(t, err)
+		for i := range batch.Results {
+			assert.Equal(t, batch.Results[i].Transaction.TransactionID, again.Results[i].Transaction.TransactionID)
+		}
+
+		balance, _ = l.balance(t, 1)
+		assert.Equal(t, models.Decimal("0.00"), balance)
+		balance, _ = l.balance(t, 3)
+		assert.Equal(t, models.Decimal("6.00"), balance)
+
+		// Accounts are locked up front; a missing one fails the first
+		// transfer that uses it.
+		batch, err = l.transfers.TransferBatch(ctx, models.BatchTransferRequest{
+			Mode: models.BatchModeAtomic,
+			Transfers: []models.BatchTransferItem{
+				{CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 3, DestinationAccountID: 2, Amount: "1.00"}},
+				{CreateTransactionRequest: models.CreateTransactionRequest{SourceAccountID: 3, DestinationAccountID: 9, Amount: "1.00"}},
+			},
+		}, "client")
+		require.NoError(t, err)
+		assert.Equal(t, models.BatchItemRolledBack, batch.Results[0].Status)
+		assert.ErrorIs(t, batch.Results[1].Err, models.ErrAccountNotFound)
+
+		l.assertReconciled(t)
+	})
+}
+
+func TestImportAccounts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 3, "5.00")
+
+		file := "account_id,currency,initial_balance,overdraft_limit,hot\n" +
+			"1,USD,100.00,,\n" +
+			"2,JPY,1.5,,\n" +
+			"3,USD,50.00,,\n" +
+			"4,EUR,,10.00,true\n" +
+			"1,USD,1.00,,\n"
+
+		importFile := func() *models.AccountImportReport {
+			reader, err := models.NewAccountImportReader(strings.NewReader(file))
+			require.NoError(t, err)
+			report, err := l.accounts.ImportAccounts(ctx, reader)
+			require.NoError(t, err)
+			return report
+		}
+
+		report := importFile()
+		assert.Equal(t, 5, report.Rows)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 0, report.Existing)
+		require.Len(t, report.Rejected, 3)
+		assert.Equal(t, 3, report.Rejected[0].Line)
+		assert.Equal(t, []string{"2", "JPY", "1.5", "", ""}, report.Rejected[0].Record)
+		assert.Equal(t, 4, report.Rejected[1].Line)
+		assert.Equal(t, "account 3 conflicts with existing account: opening balance is 5.00, not 50.00", report.Rejected[1].Error)
+		assert.Equal(t, 6, report.Rejected[2].Line)
+		assert.Equal(t, "account 1 already appears on line 2", report.Rejected[2].Error)
+
+		account, err := l.accounts.GetAccountBalance(ctx, 4)
+		require.NoError(t, err)
+		assert.Equal(t, "EUR", account.Currency)
+		assert.True(t, account.Hot)
+
+		// Importing the file again changes nothing.
+		report = importFile()
+		assert.Equal(t, 0, report.Created)
+		assert.Equal(t, 2, report.Existing)
+		assert.Len(t, report.Rejected, 3)
+
+		// A row that repeats its account is skipped; one that changes any
+		// of its settings is rejected.
+		file = "account_id,currency,initial_balance,overdraft_limit,hot\n" +
+			"3,USD,5.00,,\n" +
+			"4,EUR,,10.00,true\n" +
+			"1,EUR,100.00,,\n" +
+			"4,EUR,,,false\n"
+		report = importFile()
+		assert.Equal(t, 2, report.Existing)
+		require.Len(t, report.Rejected, 2)
+		assert.Equal(t, "account 1 conflicts with existing account: currency is USD, not EUR", report.Rejected[0].Error)
+		assert.Equal(t, "account 4 already appears on line 3", report.Rejected[1].Error)
+
+		file = "account_id,currency,initial_balance,overdraft_limit,hot\n" +
+			"4,EUR,,,false\n"
+		report = importFile()
+		require.Len(t, report.Rejected, 1)
+		assert.Equal(t, "account 4 conflicts with existing account: overdraft limit is 10.00, not 0.00, hot is true, not false", report.Rejected[0].Error)
+		balance, _ := l.balance(t, 1)
+		assert.Equal(t, models.Decimal("100.00"), balance)
+		balance, _ = l.balance(t, 3)
+		assert.Equal(t, models.Decimal("5.00"), balance)
+		l.assertReconciled(t)
+	})
+}
+
+func TestListAccountTransactions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "100.00")
+		l.createAccount(t, 2, "0")
+		l.createAccount(t, 3, "0")
+
+		simple, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			SourceAccountID:      1,
+			DestinationAccountID: 2,
+			Amount:               "10.00",
+		}, models.RequestKey{})
+		require.NoError(t, err)
+
+		multiLeg, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+			Sources: []models.TransactionLegRequest{{AccountID: 1, Amount: "5.00"}},
+			Destinations: []models.TransactionLegRequest{
+				{AccountID: 2, Amount: "2.00"},
+				{AccountID: 3, Amount: "3.00"},
+			},
+		}, models.RequestKey{})
+		require.NoError(t, err)
+
+		page, err := l.transactions.ListAccountTransactions(ctx, 2, models.ListTransactionsRequest{Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, page.Transactions, 1)
+		assert.Equal(t, multiLeg.TransactionID, page.Transactions[0].TransactionID)
+		require.NotEmpty(t, page.NextCursor)
+
+		page, err = l.transactions.ListAccountTransactions(ctx, 2, models.ListTransactionsRequest{Limit: 1, Cursor: page.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, page.Transactions, 1)
+		assert.Equal(t, simple.TransactionID, page.Transactions[0].TransactionID)
+
+		page, err = l.transactions.ListAccountTransactions(ctx, 1, models.ListTransactionsRequest{
+			Direction: models.DirectionDebit,
+			MaxAmount: "5.00",
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Transactions, 1)
+		assert.Equal(t, multiLeg.TransactionID, page.Transactions[0].TransactionID)
+
+		transaction, err := l.transactions.GetTransaction(ctx, multiLeg.TransactionID)
+		require.NoError(t, err)
+		assert.Len(t, transaction.Destinations, 2)
+
+		balance, err := l.accounts.GetBalanceAt(ctx, 2, simple.CreatedAt)
+		require.NoError(t, err)
+		assert.Equal(t, models.Decimal("10.00"), balance.Balance)
+
+		balance, err = l.accounts.GetBalanceAt(ctx, 2, simple.CreatedAt.Add(-time.Microsecond))
+		require.NoError(t, err)
+		assert.Equal(t, models.Decimal("0.00"), balance.Balance)
+	})
+}
+
+// errConflict stands in for a serialization failure or deadlock.
+var errConflict = errors.New("could not serialize access due to concurrent update")
+
+// conflictingStore is a memory store whose transactions can be retried after
+// an errConflict.
+type conflictingStore struct {
+	*memory.Store
+}
+
+func (conflictingStore) Retryable(err error) bool {
+	return errors.Is(err, errConflict)
+}
+
+// conflictingJournal fails the next conflicts postings with errConflict, as
+// the last write of a transfer.
+type conflictingJournal struct {
+	repository.JournalRepository
+	conflicts int
+}
+
+func (j *conflictingJournal) Post(ctx context.Context, tx repository.Tx, entry *models.JournalEntry) error {
+	if j.conflicts > 0 {
+		j.conflicts--
+		return errConflict
+	}
+	return j.JournalRepository.Post(ctx, tx, entry)
+}
+
+func transferRetries() int64 {
+	return retryCount("tx_retries")
+}
+
+func transferRetriesExhausted() int64 {
+	return retryCount("tx_retries_exhausted")
+}
+
+func retryCount(name string) int64 {
+	count, _ := expvar.Get(name).(*expvar.Map).Get("transfer").(*expvar.Int)
+	if count == nil {
+		return 0
+	}
+	return count.Value()
+}
+
+// recordedStatement keeps what WriteStatement writes.
+type recordedStatement struct {
+	statement *models.Statement
+	amounts   []int64
+	balances  []int64
+	closing   *int64
+}
+
+func (r *recordedStatement) Begin(statement *models.Statement) error {
+	r.statement = statement
+	return nil
+}
+
+func (r *recordedStatement) Entry(entry *models.StatementEntry, balance int64) error {
+	r.amounts = append(r.amounts, entry.Amount)
+	r.balances = append(r.balances, balance)
+	return nil
+}
+
+func (r *recordedStatement) End(closingBalance int64) error {
+	r.closing = &closingBalance
+	return nil
+}
+
+func TestWriteStatement(t *testing.T) {
+	forEachStore(t, func(t *testing.T, l *testLedger) {
+		ctx := context.Background()
+		l.createAccount(t, 1, "100.00")
+		l.createAccount(t, 2, "0")
+
+		var created []time.Time
+		for _, amount := range []models.Decimal{"10.00", "20.00", "5.00"} {
+			response, err := l.transfers.Transfer(ctx, models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+				Amount:               amount,
+			}, models.RequestKey{})
+			require.NoError(t, err)
+			created = append(created, response.CreatedAt)
+		}
+
+		// The whole history, from the opening deposit on.
+		var all recordedStatement
+		require.NoError(t, l.accounts.WriteStatement(ctx, 1, models.StatementPeriod{To: created[2]}, &all))
+		assert.Equal(t, int64(0), all.statement.OpeningBalance)
+		assert.Equal(t, []int64{10000, -1000, -2000, -500}, all.amounts)
+		assert.Equal(t, []int64{10000, 9000, 7000, 6500}, all.balances)
+		require.NotNil(t, all.closing)
+		assert.Equal(t, int64(6500), *all.closing)
+
+		// Both ends of the period are inclusive.
+		var period recordedStatement
+		require.NoError(t, l.accounts.WriteStatement(ctx, 1, models.StatementPeriod{From: created[1], To: created[1]}, &period))
+		assert.Equal(t, int64(9000), period.statement.OpeningBalance)
+		assert.Equal(t, []int64{-2000}, period.amounts)
+		assert.Equal(t, int64(7000), *period.closing)
+
+		var missing recordedStatement
+		err := l.accounts.WriteStatement(ctx, 99, models.StatementPeriod{To: created[2]}, &missing)
+		assert.ErrorIs(t, err, models.ErrAccountNotFound)
+		assert.Nil(t, missing.statement)
+	})
+}
+
+func TestTransfer_RetriesConflicts(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	journal := &conflictingJournal{JournalRepository: memory.NewJournalRepository(store)}
+	l := newTestLedger(
+		conflictingStore{store},
+		memory.NewAccountRepository(store),
+		memory.NewTransactionRepository(store),
+		journal,
+		memory.NewQuoteRepository(store),
+		memory.NewIdempotencyRepository(store),
+		memory.NewReconciliationRepository(store),
+	)
+	l.createAccount(t, 1, "100.00")
+	l.createAccount(t, 2, "0")
+
+	req := models.CreateTransactionRequest{SourceAccountID: 1, DestinationAccountID: 2, Amount: "10.00"}
+	retriesBefore := transferRetries()
+
+	// The whole transaction, key claim included, runs again.
+	journal.conflicts = 2
+	first, err := l.transfers.Transfer(ctx, req, models.RequestKey{Key: "retried"})
+	require.NoError(t, err)
+	assert.Equal(t, retriesBefore+2, transferRetries())
+
+	replayed, err := l.transfers.Transfer(ctx, req, models.RequestKey{Key: "retried"})
+	require.NoError(t, err)
+	assert.Equal(t, first.TransactionID, replayed.TransactionID)
+
+	balance, _ := l.balance(t, 1)
+	assert.Equal(t, models.Decimal("90.00"), balance, "the transfer is applied once")
+
+	// Once the attempts run out the conflict is returned, without a further
+	// attempt, and the key is released with the rest of the transaction.
+	journal.conflicts = service.DefaultRetryPolicy.MaxAttempts + 1
+	exhaustedBefore := transferRetriesExhausted()
+	_, err = l.transfers.Transfer(ctx, req, models.RequestKey{Key: "exhausted"})
+	assert.ErrorIs(t, err, errConflict)
+	assert.Equal(t, 1, journal.conflicts, "the transfer is attempted MaxAttempts times")
+	assert.Equal(t, exhaustedBefore+1, transferRetriesExhausted())
+
+	journal.conflicts = 0
+	_, err = l.transfers.Transfer(ctx, req, models.RequestKey{Key: "exhausted"})
+	require.NoError(t, err)
+
+	balance, _ = l.balance(t, 1)
+	assert.Equal(t, models.Decimal("80.00"), balance)
+	l.assertReconciled(t)
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func changeStatus(router *chi.Mux, accountID int64, action, reason string) *httptest.ResponseRecorder {
+	body := fmt.Sprintf(`{"reason": %q}`, reason)
+	req := httptest.NewRequest("POST", fmt.Sprintf("/accounts/%d/%s", accountID, action), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Actor", "ops@example.com")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func tryTransfer(router *chi.Mux, source, destination int64, amount string) int {
+	body := fmt.Sprintf(`{"source_account_id": %d, "destination_account_id": %d, "amount": %q}`,
+		source, destination, amount)
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAPI_FreezeAccount(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "100.00"}`,
+	)
+
+	assert.Equal(t, models.AccountStatusActive, getAccount(t, router, 1).Status)
+
+	w := changeStatus(router, 1, "freeze", "Suspected fraud")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.AccountStatusFrozen, getAccount(t, router, 1).Status)
+
+	// Frozen accounts receive but do not send
+	assert.Equal(t, http.StatusUnprocessableEntity, tryTransfer(router, 1, 2, "10.00"))
+	assert.Equal(t, http.StatusCreated, tryTransfer(router, 2, 1, "10.00"))
+
+	w = changeStatus(router, 1, "freeze", "Again")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = changeStatus(router, 1, "unfreeze", "Cleared by review")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusCreated, tryTransfer(router, 1, 2, "10.00"))
+
+	req := httptest.NewRequest("GET", "/accounts/1/status-changes", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var changes []models.AccountStatusChangeResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&changes))
+	require.Len(t, changes, 2)
+	assert.Equal(t, models.AccountStatusActive, changes[0].FromStatus)
+	assert.Equal(t, models.AccountStatusFrozen, changes[0].ToStatus)
+	assert.Equal(t, "Suspected fraud", changes[0].Reason)
+	assert.Equal(t, "ops@example.com", changes[0].Actor)
+	assert.Equal(t, models.AccountStatusActive, changes[1].ToStatus)
+}
+
+func TestAPI_CloseAccount(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	w := changeStatus(router, 1, "close", "Customer request")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	require.Equal(t, http.StatusCreated, tryTransfer(router, 1, 2, "100.00"))
+
+	w = changeStatus(router, 1, "close", "Customer request")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, models.AccountStatusClosed, getAccount(t, router, 1).Status)
+
+	// Closed accounts neither send nor receive, and stay closed
+	assert.Equal(t, http.StatusUnprocessableEntity, tryTransfer(router, 2, 1, "1.00"))
+	w = changeStatus(router, 1, "unfreeze", "Reopen")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestAPI_ChangeStatusValidation(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router, `{"account_id": 1, "initial_balance": "0"}`)
+
+	w := changeStatus(router, 1, "freeze", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req := httptest.NewRequest("POST", "/accounts/1/freeze", bytes.NewBufferString(`{"reason": "No actor"}`))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = changeStatus(router, 99, "freeze", "Missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -2,23 +2,30 @@ package integration
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/filipe/financial-ledger-project/internal/database"
+	"github.com/filipe/financial-ledger-project/internal/fx"
 	"github.com/filipe/financial-ledger-project/internal/handler"
 	"github.com/filipe/financial-ledger-project/internal/models"
-	"github.com/filipe/financial-ledger-project/internal/repository"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
 	"github.com/filipe/financial-ledger-project/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// setupTestRouter creates a test router with all dependencies
-func setupTestRouter(t *testing.T) (*chi.Mux, func()) {
+// testHoldTTL is how long holds of pending transfers last in tests.
+const testHoldTTL = time.Hour
+
+// openTestDB connects to the test database.
+func openTestDB(t *testing.T) *sql.DB {
 	cfg := database.Config{
 		Host:     "localhost",
 		Port:     "5433",
@@ -30,23 +37,72 @@ func setupTestRouter(t *testing.T) (*chi.Mux, func()) {
 
 	db, err := database.NewPostgresDB(cfg)
 	require.NoError(t, err, "Failed to connect to test database")
+	return db
+}
 
-	_, err = db.Exec("TRUNCATE accounts, transactions CASCADE")
+// routerOptions configures the handlers of a test router.
+type routerOptions struct {
+	requireClientID bool
+}
+
+// setupTestRouter creates a test router with all dependencies
+func setupTestRouter(t *testing.T) (*chi.Mux, func()) {
+	return setupTestRouterWith(t, routerOptions{})
+}
+
+func setupTestRouterWith(t *testing.T, opts routerOptions) (*chi.Mux, func()) {
+	db := openTestDB(t)
+
+	_, err := db.Exec("TRUNCATE accounts, transactions, fx_quotes, idempotency_keys CASCADE")
 	require.NoError(t, err, "Failed to truncate tables")
 
-	accountRepo := repository.NewAccountRepository(db)
-	transactionRepo := repository.NewTransactionRepository(db)
+	rates, err := fx.NewStaticRateProvider(map[string]models.Decimal{
+		"USD/JPY": "150.5",
+		"JPY/USD": "0.0066",
+		"USD/KWD": "0.307",
+	})
+	require.NoError(t, err)
+
+	uow := postgres.NewUnitOfWork(db)
+	accountRepo := postgres.NewAccountRepository(db)
+	transactionRepo := postgres.NewTransactionRepository(db)
+	journalRepo := postgres.NewJournalRepository(db)
+	quoteRepo := postgres.NewQuoteRepository(db)
+	idempotencyRepo := postgres.NewIdempotencyRepository(db)
 
-	accountService := service.NewAccountService(accountRepo)
-	transferService := service.NewTransferService(db, accountRepo, transactionRepo)
+	accountService := service.NewAccountService(uow, accountRepo, journalRepo, idempotencyRepo)
+	transferService := service.NewTransferService(uow, accountRepo, transactionRepo, journalRepo, quoteRepo, idempotencyRepo, testHoldTTL)
+	transactionService := service.NewTransactionService(accountRepo, transactionRepo)
+	fxService := service.NewFXService(uow, quoteRepo, rates, time.Minute)
+	reconciliationService := service.NewReconciliationService(uow, postgres.NewReconciliationRepository(db))
 
 	accountHandler := handler.NewAccountHandler(accountService)
-	transactionHandler := handler.NewTransactionHandler(transferService)
+	accountHandler.RequireClientID(opts.requireClientID)
+	transactionHandler := handler.NewTransactionHandler(transferService, transactionService)
+	transactionHandler.RequireClientID(opts.requireClientID)
+	fxHandler := handler.NewFXHandler(fxService)
+	adminHandler := handler.NewAdminHandler(reconciliationService)
 
 	r := chi.NewRouter()
 	r.Post("/accounts", accountHandler.CreateAccount)
+	r.Post("/accounts/import", accountHandler.ImportAccounts)
 	r.Get("/accounts/{account_id}", accountHandler.GetAccount)
+	r.Get("/accounts/{account_id}/balance", accountHandler.GetBalance)
+	r.Get("/accounts/{account_id}/transactions", transactionHandler.ListAccountTransactions)
+	r.Put("/accounts/{account_id}/overdraft-limit", accountHandler.SetOverdraftLimit)
+	r.Put("/accounts/{account_id}/hot", accountHandler.SetHotMode)
+	r.Post("/accounts/{account_id}/freeze", accountHandler.FreezeAccount)
+	r.Post("/accounts/{account_id}/unfreeze", accountHandler.UnfreezeAccount)
+	r.Post("/accounts/{account_id}/close", accountHandler.CloseAccount)
+	r.Get("/accounts/{account_id}/status-changes", accountHandler.ListStatusChanges)
 	r.Post("/transactions", transactionHandler.CreateTransaction)
+	r.Post("/transactions/batch", transactionHandler.CreateBatch)
+	r.Get("/transactions/{transaction_id}", transactionHandler.GetTransaction)
+	r.Post("/transactions/{transaction_id}/reversals", transactionHandler.ReverseTransaction)
+	r.Post("/transactions/{transaction_id}/capture", transactionHandler.CaptureTransaction)
+	r.Post("/transactions/{transaction_id}/void", transactionHandler.VoidTransaction)
+	r.Post("/fx/quotes", fxHandler.CreateQuote)
+	r.Get("/admin/reconciliation", adminHandler.Reconcile)
 
 	cleanup := func() {
 		db.Close()
@@ -79,6 +135,21 @@ func TestAPI_CreateAccount(t *testing.T) {
 			body:           `{"account_id": 2, "initial_balance": -100}`,
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name:           "Decimal string balance",
+			body:           `{"account_id": 3, "initial_balance": "100.50"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Too many decimals",
+			body:           `{"account_id": 4, "initial_balance": "100.126"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Too many decimals in legacy number",
+			body:           `{"account_id": 5, "initial_balance": 100.126}`,
+			expectedStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,7 +187,91 @@ func TestAPI_GetAccount(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, int64(1), response.AccountID)
-	assert.Equal(t, 100.50, response.Balance)
+	assert.Equal(t, "USD", response.Currency)
+	assert.Equal(t, models.Decimal("100.50"), response.Balance)
+
+	// A client that has not moved to strings asks for numbers, and only its
+	// own response changes
+	getReq = httptest.NewRequest("GET", "/accounts/1", nil)
+	getReq.Header.Set("X-Amount-Format", "number")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, getReq)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"balance":100.50`)
+
+	getReq = httptest.NewRequest("GET", "/accounts/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, getReq)
+	assert.Contains(t, w.Body.String(), `"balance":"100.50"`)
+}
+
+func TestAPI_CurrencyMinorUnits(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	tests := []struct {
+		body            string
+		accountID       int64
+		expectedBalance models.Decimal
+	}{
+		{`{"account_id": 1, "currency": "JPY", "initial_balance": "1500"}`, 1, "1500"},
+		{`{"account_id": 2, "currency": "USD", "initial_balance": "15.5"}`, 2, "15.50"},
+		{`{"account_id": 3, "currency": "KWD", "initial_balance": "1.005"}`, 3, "1.005"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/accounts", bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		getReq := httptest.NewRequest("GET", fmt.Sprintf("/accounts/%d", tt.accountID), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, getReq)
+
+		var response models.AccountResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, tt.expectedBalance, response.Balance)
+	}
+
+	req := httptest.NewRequest("POST", "/accounts",
+		bytes.NewBufferString(`{"account_id": 4, "currency": "JPY", "initial_balance": "10.5"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPI_CurrencyMismatch(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	accounts := []string{
+		`{"account_id": 1, "currency": "USD", "initial_balance": "1000.00"}`,
+		`{"account_id": 2, "currency": "JPY", "initial_balance": "500"}`,
+	}
+
+	for _, acc := range accounts {
+		req := httptest.NewRequest("POST", "/accounts", bytes.NewBufferString(acc))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	transferBody := `{
+		"source_account_id": 1,
+		"destination_account_id": 2,
+		"amount": "100.00"
+	}`
+
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 }
 
 func TestAPI_Transfer(t *testing.T) {
@@ -156,7 +311,7 @@ func TestAPI_Transfer(t *testing.T) {
 	assert.NotEmpty(t, txnResponse.TransactionID)
 	assert.Equal(t, int64(1), txnResponse.SourceAccountID)
 	assert.Equal(t, int64(2), txnResponse.DestinationAccountID)
-	assert.Equal(t, 250.50, txnResponse.Amount)
+	assert.Equal(t, models.Decimal("250.50"), txnResponse.Amount)
 	assert.Equal(t, "COMPLETED", txnResponse.Status)
 
 	getReq := httptest.NewRequest("GET", "/accounts/1", nil)
@@ -164,14 +319,14 @@ func TestAPI_Transfer(t *testing.T) {
 	router.ServeHTTP(w, getReq)
 	var acc1 models.AccountResponse
 	json.NewDecoder(w.Body).Decode(&acc1)
-	assert.Equal(t, 749.50, acc1.Balance)
+	assert.Equal(t, models.Decimal("749.50"), acc1.Balance)
 
 	getReq = httptest.NewRequest("GET", "/accounts/2", nil)
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, getReq)
 	var acc2 models.AccountResponse
 	json.NewDecoder(w.Body).Decode(&acc2)
-	assert.Equal(t, 750.50, acc2.Balance)
+	assert.Equal(t, models.Decimal("750.50"), acc2.Balance)
 }
 
 func TestAPI_InsufficientFunds(t *testing.T) {
@@ -231,6 +386,7 @@ func TestAPI_Idempotency(t *testing.T) {
 	req1 := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
 	req1.Header.Set("Content-Type", "application/json")
 	req1.Header.Set("Idempotency-Key", idempotencyKey)
+	req1.Header.Set("X-Client-ID", "test-client")
 	w1 := httptest.NewRecorder()
 	router.ServeHTTP(w1, req1)
 
@@ -242,6 +398,7 @@ func TestAPI_Idempotency(t *testing.T) {
 	req2 := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
 	req2.Header.Set("Content-Type", "application/json")
 	req2.Header.Set("Idempotency-Key", idempotencyKey)
+	req2.Header.Set("X-Client-ID", "test-client")
 	w2 := httptest.NewRecorder()
 	router.ServeHTTP(w2, req2)
 
@@ -252,10 +409,258 @@ func TestAPI_Idempotency(t *testing.T) {
 
 	assert.Equal(t, txn1.TransactionID, txn2.TransactionID)
 
+	// The same payload formatted differently is still a retry
+	req3 := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(
+		`{"amount": 100.00, "destination_account_id": 2, "source_account_id": 1}`))
+	req3.Header.Set("Content-Type", "application/json")
+	req3.Header.Set("Idempotency-Key", idempotencyKey)
+	req3.Header.Set("X-Client-ID", "test-client")
+	w3 := httptest.NewRecorder()
+	router.ServeHTTP(w3, req3)
+	assert.Equal(t, http.StatusCreated, w3.Code)
+
+	// A different payload under the same key is rejected, for transfers and
+	// reversals alike
+	for path, body := range map[string]string{
+		"/transactions": `{"source_account_id": 1, "destination_account_id": 2, "amount": "999.99"}`,
+		"/transactions/" + txn1.TransactionID + "/reversals": `{}`,
+	} {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+		req.Header.Set("X-Client-ID", "test-client")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, path)
+		assert.Contains(t, w.Body.String(), "different request payload")
+	}
+
 	getReq := httptest.NewRequest("GET", "/accounts/1", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, getReq)
 	var acc models.AccountResponse
 	json.NewDecoder(w.Body).Decode(&acc)
-	assert.Equal(t, 900.00, acc.Balance)
+	assert.Equal(t, models.Decimal("900.00"), acc.Balance)
+}
+
+func TestAPI_IdempotencyReplaysFailures(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "10.00"}`,
+		`{"account_id": 2, "initial_balance": "500.00"}`,
+	)
+
+	post := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(
+			`{"source_account_id": 1, "destination_account_id": 2, "amount": "50.00"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		req.Header.Set("X-Client-ID", "test-client")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("failing-key")
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "Insufficient funds")
+
+	// Once account 1 can afford it, the key still answers with its first
+	// outcome
+	transfer(t, router, 2, 1, "100.00")
+
+	w = post("failing-key")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "Insufficient funds")
+	assert.Equal(t, models.Decimal("110.00"), getBalance(t, router, 1))
+
+	// A new key is a new request
+	w = post("retry-key")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, models.Decimal("60.00"), getBalance(t, router, 1))
+}
+
+func TestAPI_CrossCurrencyTransfer(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	accounts := []string{
+		`{"account_id": 1, "currency": "USD", "initial_balance": "1000.00"}`,
+		`{"account_id": 2, "currency": "JPY", "initial_balance": "500"}`,
+		`{"account_id": 3, "currency": "KWD", "initial_balance": "0"}`,
+	}
+
+	for _, acc := range accounts {
+		req := httptest.NewRequest("POST", "/accounts", bytes.NewBufferString(acc))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/fx/quotes",
+		bytes.NewBufferString(`{"source_currency": "USD", "destination_currency": "JPY"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var quote models.FXQuoteResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&quote))
+	assert.Equal(t, models.Decimal("150.5"), quote.Rate)
+
+	transferBody := fmt.Sprintf(`{
+		"source_account_id": 1,
+		"destination_account_id": 2,
+		"amount": "10.01",
+		"quote_id": %q
+	}`, quote.QuoteID)
+
+	req = httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var txn models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&txn))
+	assert.Equal(t, models.Decimal("10.01"), txn.Amount)
+	assert.Equal(t, "USD", txn.Currency)
+	assert.Equal(t, models.Decimal("1507"), txn.DestinationAmount)
+	assert.Equal(t, "JPY", txn.DestinationCurrency)
+	assert.Equal(t, models.Decimal("150.5"), txn.FXRate)
+
+	getReq := httptest.NewRequest("GET", "/accounts/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, getReq)
+	var acc1 models.AccountResponse
+	json.NewDecoder(w.Body).Decode(&acc1)
+	assert.Equal(t, models.Decimal("989.99"), acc1.Balance)
+
+	getReq = httptest.NewRequest("GET", "/accounts/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, getReq)
+	var acc2 models.AccountResponse
+	json.NewDecoder(w.Body).Decode(&acc2)
+	assert.Equal(t, models.Decimal("2007"), acc2.Balance)
+
+	// The USD/JPY quote can not be used for a USD -> KWD transfer
+	transferBody = fmt.Sprintf(`{
+		"source_account_id": 1,
+		"destination_account_id": 3,
+		"amount": "10.00",
+		"quote_id": %q
+	}`, quote.QuoteID)
+
+	req = httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	req = httptest.NewRequest("POST", "/fx/quotes",
+		bytes.NewBufferString(`{"source_currency": "KWD", "destination_currency": "JPY"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestAPI_MultiLegTransfer(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	accounts := []string{
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "50.00"}`,
+		`{"account_id": 3, "initial_balance": "0"}`,
+		`{"account_id": 4, "initial_balance": "0"}`,
+		`{"account_id": 5, "initial_balance": "0"}`,
+	}
+
+	for _, acc := range accounts {
+		req := httptest.NewRequest("POST", "/accounts", bytes.NewBufferString(acc))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+	}
+
+	transferBody := `{
+		"sources": [
+			{"account_id": 1, "amount": "80.00"},
+			{"account_id": 2, "amount": "20.00"}
+		],
+		"destinations": [
+			{"account_id": 3, "amount": "90.00"},
+			{"account_id": 4, "amount": "7.50"},
+			{"account_id": 5, "amount": "2.50"}
+		]
+	}`
+
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "multi-leg-1")
+	req.Header.Set("X-Client-ID", "test-client")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var txn models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&txn))
+	assert.NotEmpty(t, txn.TransactionID)
+	assert.Equal(t, models.Decimal("100.00"), txn.Amount)
+	assert.Len(t, txn.Sources, 2)
+	assert.Len(t, txn.Destinations, 3)
+
+	expected := map[int]models.Decimal{1: "20.00", 2: "30.00", 3: "90.00", 4: "7.50", 5: "2.50"}
+	for id, balance := range expected {
+		getReq := httptest.NewRequest("GET", fmt.Sprintf("/accounts/%d", id), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, getReq)
+		var acc models.AccountResponse
+		json.NewDecoder(w.Body).Decode(&acc)
+		assert.Equal(t, balance, acc.Balance, "account %d", id)
+	}
+
+	// Replaying the key returns the same transaction with its legs
+	req = httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "multi-leg-1")
+	req.Header.Set("X-Client-ID", "test-client")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var replayed models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&replayed))
+	assert.Equal(t, txn.TransactionID, replayed.TransactionID)
+	assert.ElementsMatch(t, txn.Sources, replayed.Sources)
+	assert.ElementsMatch(t, txn.Destinations, replayed.Destinations)
+
+	// One leg short of funds fails the whole transaction
+	transferBody = `{
+		"sources": [
+			{"account_id": 1, "amount": "10.00"},
+			{"account_id": 2, "amount": "40.00"}
+		],
+		"destinations": [
+			{"account_id": 3, "amount": "50.00"}
+		]
+	}`
+
+	req = httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(transferBody))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	getReq := httptest.NewRequest("GET", "/accounts/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, getReq)
+	var acc1 models.AccountResponse
+	json.NewDecoder(w.Body).Decode(&acc1)
+	assert.Equal(t, models.Decimal("20.00"), acc1.Balance)
 }
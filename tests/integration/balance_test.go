@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getBalanceAt(t *testing.T, router *chi.Mux, accountID int64, asOf time.Time) models.BalanceResponse {
+	query := url.Values{"as_of": {asOf.Format(time.RFC3339Nano)}}
+	req := httptest.NewRequest("GET", fmt.Sprintf("/accounts/%d/balance?%s", accountID, query.Encode()), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var balance models.BalanceResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&balance))
+	return balance
+}
+
+func TestAPI_BalanceAsOf(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	beforeCreation := time.Now()
+	time.Sleep(time.Millisecond)
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	first := transfer(t, router, 1, 2, "10.00")
+	second := transfer(t, router, 1, 2, "20.00")
+	third := transfer(t, router, 2, 1, "5.00")
+
+	assert.Equal(t, models.Decimal("0.00"), getBalanceAt(t, router, 1, beforeCreation).Balance)
+
+	// A transfer is part of the balance as of its own timestamp
+	assert.Equal(t, models.Decimal("90.00"), getBalanceAt(t, router, 1, first.CreatedAt).Balance)
+	assert.Equal(t, models.Decimal("100.00"), getBalanceAt(t, router, 1, first.CreatedAt.Add(-time.Microsecond)).Balance)
+	assert.Equal(t, models.Decimal("70.00"), getBalanceAt(t, router, 1, second.CreatedAt).Balance)
+	assert.Equal(t, models.Decimal("30.00"), getBalanceAt(t, router, 2, second.CreatedAt).Balance)
+	assert.Equal(t, models.Decimal("75.00"), getBalanceAt(t, router, 1, third.CreatedAt).Balance)
+
+	// Without as_of, the current balance
+	req := httptest.NewRequest("GET", "/accounts/1/balance", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var current models.BalanceResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&current))
+	assert.Equal(t, models.Decimal("75.00"), current.Balance)
+	assert.Equal(t, getBalance(t, router, 1), current.Balance)
+
+	// End of a past day, a future time and garbage
+	day := beforeCreation.UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	req = httptest.NewRequest("GET", "/accounts/1/balance?as_of="+day, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	for _, value := range []string{time.Now().Add(time.Hour).Format(time.RFC3339), "yesterday"} {
+		req = httptest.NewRequest("GET", "/accounts/1/balance?as_of="+url.QueryEscape(value), nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, value)
+	}
+
+	req = httptest.NewRequest("GET", "/accounts/99/balance", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSnapshots_TakeSnapshots(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+	transfer(t, router, 1, 2, "10.00")
+	transfer(t, router, 2, 1, "2.50")
+
+	db := openTestDB(t)
+	defer db.Close()
+
+	snapshotRepo := postgres.NewSnapshotRepository(db)
+	journalRepo := postgres.NewJournalRepository(db)
+	snapshotService := service.NewSnapshotService(postgres.NewUnitOfWork(db), snapshotRepo, 5*time.Minute)
+	ctx := context.Background()
+
+	// Today has not ended yet
+	days, err := snapshotService.TakeSnapshots(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, days)
+
+	// Two days later today and tomorrow are settled, and only once
+	days, err = snapshotService.TakeSnapshots(ctx, time.Now().AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, days)
+
+	days, err = snapshotService.TakeSnapshots(ctx, time.Now().AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 0, days)
+
+	today := models.DayEnd(time.Now())
+	tomorrow := today.AddDate(0, 0, 1)
+
+	var balance int64
+	require.NoError(t, db.QueryRow(
+		`SELECT balance FROM balance_snapshots WHERE account_id = 1 AND as_of = $1`, today,
+	).Scan(&balance))
+	assert.Equal(t, int64(9250), balance)
+
+	require.NoError(t, db.QueryRow(
+		`SELECT balance FROM balance_snapshots WHERE account_id = 2 AND as_of = $1`, tomorrow,
+	).Scan(&balance))
+	assert.Equal(t, int64(750), balance)
+
+	// Balances after a snapshot start from it rather than from the postings
+	_, err = db.Exec(`UPDATE balance_snapshots SET balance = balance + 1 WHERE account_id = 1 AND as_of = $1`, tomorrow)
+	require.NoError(t, err)
+
+	balance, err = journalRepo.BalanceAt(ctx, 1, today)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9250), balance)
+
+	balance, err = journalRepo.BalanceAt(ctx, 1, tomorrow.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(9251), balance)
+
+	// Times before the first snapshot still sum the postings
+	assert.Equal(t, models.Decimal("92.50"), getBalanceAt(t, router, 1, time.Now()).Balance)
+}
@@ -0,0 +1,159 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postBatch posts a transfer batch as the given client and returns the
+// response status and the decoded batch.
+func postBatch(t *testing.T, router *chi.Mux, clientID, body string) (int, models.BatchTransferResponse) {
+	req := httptest.NewRequest("POST", "/transactions/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Client-ID", clientID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var batch models.BatchTransferResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&batch), w.Body.String())
+	return w.Code, batch
+}
+
+func TestAPI_AtomicBatchRollsBack(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "10.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+		`{"account_id": 3, "initial_balance": "0"}`,
+	)
+
+	// The last transfer overdraws account 1, so none of them is committed
+	code, batch := postBatch(t, router, "payroll", `{"mode": "atomic", "transfers": [
+		{"idempotency_key": "run-1-a", "source_account_id": 1, "destination_account_id": 2, "amount": "6.00"},
+		{"idempotency_key": "run-1-b", "source_account_id": 2, "destination_account_id": 3, "amount": "2.00"},
+		{"idempotency_key": "run-1-c", "source_account_id": 1, "destination_account_id": 3, "amount": "6.00"}
+	]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Zero(t, batch.Succeeded)
+	require.Len(t, batch.Results, 3)
+	assert.Equal(t, models.BatchItemRolledBack, batch.Results[0].Status)
+	assert.Equal(t, models.BatchItemRolledBack, batch.Results[1].Status)
+	assert.Equal(t, models.BatchItemFailed, batch.Results[2].Status)
+	assert.Equal(t, http.StatusUnprocessableEntity, batch.Results[2].Code)
+	assert.Nil(t, batch.Results[0].Transaction)
+
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, 1))
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 2))
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 3))
+	assert.Empty(t, listTransactions(t, router, 2, url.Values{}).Transactions)
+
+	// Nothing was stored with the keys, so the corrected batch runs
+	code, batch = postBatch(t, router, "payroll", `{"mode": "atomic", "transfers": [
+		{"idempotency_key": "run-1-a", "source_account_id": 1, "destination_account_id": 2, "amount": "6.00"},
+		{"idempotency_key": "run-1-b", "source_account_id": 2, "destination_account_id": 3, "amount": "2.00"},
+		{"idempotency_key": "run-1-c", "source_account_id": 1, "destination_account_id": 3, "amount": "4.00"}
+	]}`)
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 3, batch.Succeeded)
+
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 1))
+	assert.Equal(t, models.Decimal("4.00"), getBalance(t, router, 2))
+	assert.Equal(t, models.Decimal("6.00"), getBalance(t, router, 3))
+	assert.True(t, reconcile(t, router).Balanced)
+}
+
+func TestAPI_BestEffortBatchResults(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "10.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	body := `{"mode": "best_effort", "transfers": [
+		{"idempotency_key": "run-2-a", "source_account_id": 1, "destination_account_id": 2, "amount": "6.00"},
+		{"idempotency_key": "run-2-b", "source_account_id": 1, "destination_account_id": 2, "amount": "6.00"},
+		{"idempotency_key": "run-2-c", "source_account_id": 1, "destination_account_id": 9, "amount": "1.00"},
+		{"idempotency_key": "run-2-d", "source_account_id": 1, "destination_account_id": 2, "amount": "4.00"}
+	]}`
+	code, batch := postBatch(t, router, "payroll", body)
+	assert.Equal(t, http.StatusMultiStatus, code)
+	assert.Equal(t, 2, batch.Succeeded)
+	assert.Equal(t, 2, batch.Failed)
+	require.Len(t, batch.Results, 4)
+
+	for i, want := range []struct {
+		status string
+		code   int
+	}{
+		{models.BatchItemCompleted, 0},
+		{models.BatchItemFailed, http.StatusUnprocessableEntity},
+		{models.BatchItemFailed, http.StatusNotFound},
+		{models.BatchItemCompleted, 0},
+	} {
+		result := batch.Results[i]
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, want.status, result.Status, "transfer %d", i)
+		assert.Equal(t, want.code, result.Code, "transfer %d", i)
+		if want.status == models.BatchItemCompleted {
+			require.NotNil(t, result.Transaction, "transfer %d", i)
+		} else {
+			assert.NotEmpty(t, result.Error, "transfer %d", i)
+		}
+	}
+
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 1))
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, 2))
+
+	// Each transfer's key is stored like a single transfer's: a retry of the
+	// batch replays every outcome, failures included
+	code, retry := postBatch(t, router, "payroll", body)
+	assert.Equal(t, http.StatusMultiStatus, code)
+	for i := range batch.Results {
+		assert.Equal(t, batch.Results[i].Status, retry.Results[i].Status, "transfer %d", i)
+		if batch.Results[i].Transaction != nil {
+			assert.Equal(t, batch.Results[i].Transaction.TransactionID, retry.Results[i].Transaction.TransactionID)
+		}
+	}
+
+	w := postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "4.00"}`, "payroll", "run-2-d")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var single models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&single))
+	assert.Equal(t, batch.Results[3].Transaction.TransactionID, single.TransactionID)
+
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, 2))
+	assert.True(t, reconcile(t, router).Balanced)
+}
+
+func TestAPI_BatchKeysNeedClientID(t *testing.T) {
+	router, cleanup := setupTestRouterWith(t, routerOptions{requireClientID: true})
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "10.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	req := httptest.NewRequest("POST", "/transactions/batch", bytes.NewBufferString(`{"mode": "atomic", "transfers": [
+		{"idempotency_key": "a", "source_account_id": 1, "destination_account_id": 2, "amount": "1.00"}
+	]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, 1))
+}
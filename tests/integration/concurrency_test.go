@@ -21,11 +21,11 @@ func TestConcurrentTransfers_BalanceConsistency(t *testing.T) {
 	router, cleanup := setupTestRouter(t)
 	defer cleanup()
 
-	initialBalance := 10000.00
-	totalSystemBalance := initialBalance * 3.0
+	initialBalance := "10000.00"
+	totalSystemBalance := models.Decimal("30000.00")
 
 	for i := 1; i <= 3; i++ {
-		body := fmt.Sprintf(`{"account_id": %d, "initial_balance": %.2f}`, i, initialBalance)
+		body := fmt.Sprintf(`{"account_id": %d, "initial_balance": %q}`, i, initialBalance)
 		req := httptest.NewRequest("POST", "/accounts", bytes.NewBufferString(body))
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
@@ -60,7 +60,7 @@ func TestConcurrentTransfers_BalanceConsistency(t *testing.T) {
 
 	wg.Wait()
 
-	totalBalance := 0.0
+	totalBalance := int64(0)
 	for i := 1; i <= 3; i++ {
 		req := httptest.NewRequest("GET", fmt.Sprintf("/accounts/%d", i), nil)
 		w := httptest.NewRecorder()
@@ -68,11 +68,14 @@ func TestConcurrentTransfers_BalanceConsistency(t *testing.T) {
 
 		var acc models.AccountResponse
 		json.NewDecoder(w.Body).Decode(&acc)
-		totalBalance += acc.Balance
+		balance, err := models.DecimalToMinorUnits(acc.Balance, acc.Currency)
+		require.NoError(t, err)
+		totalBalance += balance
 	}
 
-	assert.Equal(t, totalSystemBalance, totalBalance,
+	assert.Equal(t, totalSystemBalance, models.MinorUnitsToDecimal(totalBalance, models.DefaultCurrency),
 		"Money was created or lost due to race condition!")
+	assert.True(t, reconcile(t, router).Balanced, "Balances drifted from the journal!")
 }
 
 // TestConcurrentTransfers_FromSameAccount tests that concurrent transfers
@@ -131,14 +134,14 @@ func TestConcurrentTransfers_FromSameAccount(t *testing.T) {
 	router.ServeHTTP(w, req)
 	var acc1 models.AccountResponse
 	json.NewDecoder(w.Body).Decode(&acc1)
-	assert.Equal(t, 0.00, acc1.Balance, "Source account should be empty")
+	assert.Equal(t, models.Decimal("0.00"), acc1.Balance, "Source account should be empty")
 
 	req = httptest.NewRequest("GET", "/accounts/2", nil)
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	var acc2 models.AccountResponse
 	json.NewDecoder(w.Body).Decode(&acc2)
-	assert.Equal(t, 1000.00, acc2.Balance, "Destination should have all money")
+	assert.Equal(t, models.Decimal("1000.00"), acc2.Balance, "Destination should have all money")
 }
 
 // TestConcurrentTransfers_WithIdempotency tests that idempotency works
@@ -182,6 +185,7 @@ func TestConcurrentTransfers_WithIdempotency(t *testing.T) {
 			req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(body))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("Idempotency-Key", idempotencyKey)
+			req.Header.Set("X-Client-ID", "test-client")
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 
@@ -197,7 +201,9 @@ func TestConcurrentTransfers_WithIdempotency(t *testing.T) {
 
 	wg.Wait()
 
-	require.NotEmpty(t, transactionIDs, "Should have at least one transaction ID")
+	// Requests that arrive while the first is in flight wait for it and
+	// replay its outcome instead of failing
+	require.Len(t, transactionIDs, numGoroutines, "Every request should succeed")
 	firstID := transactionIDs[0]
 	for _, id := range transactionIDs {
 		assert.Equal(t, firstID, id, "All requests should return same transaction ID")
@@ -208,7 +214,7 @@ func TestConcurrentTransfers_WithIdempotency(t *testing.T) {
 	router.ServeHTTP(w, req)
 	var acc models.AccountResponse
 	json.NewDecoder(w.Body).Decode(&acc)
-	assert.Equal(t, 900.00, acc.Balance, "Balance should only be deducted once")
+	assert.Equal(t, models.Decimal("900.00"), acc.Balance, "Balance should only be deducted once")
 }
 
 // TestConcurrentCreates_DuplicateAccount tests that duplicate account
@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashChain_Verify(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "100.00"}`,
+		`{"account_id": 3, "initial_balance": "100.00"}`,
+	)
+
+	first := transfer(t, router, 1, 2, "10.00")
+	hold := authorize(t, router, 1, 3, "20.00")
+	require.Equal(t, http.StatusOK, postAction(router, hold.TransactionID, "capture", `{"amount": "5.00"}`).Code)
+	transfer(t, router, 1, 2, "1.00")
+
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(`{
+		"sources": [{"account_id": 3, "amount": "2.00"}, {"account_id": 2, "amount": "3.00"}],
+		"destinations": [{"account_id": 1, "amount": "5.00"}]
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = reverse(router, first.TransactionID, `{"amount": "5.00"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var reversal models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&reversal))
+
+	db := openTestDB(t)
+	defer db.Close()
+
+	// Account 1 chains its three transfers; account 2, as the lowest source,
+	// the multi-leg transaction and then the reversal it sent back
+	var seq int64
+	require.NoError(t, db.QueryRow(
+		`SELECT chain_seq FROM transactions WHERE id = $1 AND chain_account_id = 2`, reversal.TransactionID,
+	).Scan(&seq))
+	assert.Equal(t, int64(2), seq)
+
+	chainService := service.NewChainService(postgres.NewTransactionRepository(db))
+	ctx := context.Background()
+
+	report, err := chainService.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.Intact(), report.Breaks)
+	assert.Equal(t, 2, report.AccountsChecked)
+	assert.Equal(t, 5, report.TransactionsChecked)
+
+	// A changed amount is pinned to its record
+	_, err = db.Exec(`UPDATE transactions SET amount = amount + 1 WHERE id = $1`, first.TransactionID)
+	require.NoError(t, err)
+
+	report, err = chainService.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ChainBreak{
+		{AccountID: 1, Seq: 1, TransactionID: first.TransactionID, Reason: models.ChainBreakHash},
+	}, report.Breaks)
+
+	_, err = db.Exec(`UPDATE transactions SET amount = amount - 1 WHERE id = $1`, first.TransactionID)
+	require.NoError(t, err)
+
+	// So is a changed captured amount, although the hold's own hash only
+	// covers what was authorized
+	_, err = db.Exec(`UPDATE transactions SET amount = amount + 1, destination_amount = destination_amount + 1 WHERE id = $1`, hold.TransactionID)
+	require.NoError(t, err)
+
+	report, err = chainService.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ChainBreak{
+		{AccountID: 1, Seq: 2, TransactionID: hold.TransactionID, Reason: models.ChainBreakSettlement},
+	}, report.Breaks)
+
+	// And a captured hold made to look voided
+	_, err = db.Exec(`UPDATE transactions SET amount = amount - 1, destination_amount = destination_amount - 1, status = 'VOIDED' WHERE id = $1`, hold.TransactionID)
+	require.NoError(t, err)
+
+	report, err = chainService.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ChainBreak{
+		{AccountID: 1, Seq: 2, TransactionID: hold.TransactionID, Reason: models.ChainBreakSettlement},
+	}, report.Breaks)
+
+	_, err = db.Exec(`UPDATE transactions SET status = 'COMPLETED' WHERE id = $1`, hold.TransactionID)
+	require.NoError(t, err)
+
+	// Taking a record out of the chain leaves a gap
+	_, err = db.Exec(`
+		UPDATE transactions SET chain_account_id = NULL, chain_seq = NULL, prev_hash = NULL, hash = NULL
+		WHERE id = $1`, hold.TransactionID)
+	require.NoError(t, err)
+
+	report, err = chainService.Verify(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ChainBreak{
+		{AccountID: 1, Seq: 2, Reason: models.ChainBreakMissing},
+		{AccountID: 1, TransactionID: hold.TransactionID, Reason: models.ChainBreakUnsealed},
+	}, report.Breaks)
+}
@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createAccounts creates each account from its JSON body and fails the test
+// on anything but 201.
+func createAccounts(t *testing.T, router *chi.Mux, bodies ...string) {
+	for _, body := range bodies {
+		req := httptest.NewRequest("POST", "/accounts", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code, body)
+	}
+}
+
+// transfer posts a simple transfer and returns the created transaction.
+func transfer(t *testing.T, router *chi.Mux, source, destination int64, amount string) models.TransactionResponse {
+	body := fmt.Sprintf(`{"source_account_id": %d, "destination_account_id": %d, "amount": %q}`,
+		source, destination, amount)
+
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var txn models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&txn))
+	return txn
+}
+
+func listTransactions(t *testing.T, router *chi.Mux, accountID int64, query url.Values) models.TransactionPageResponse {
+	req := httptest.NewRequest("GET", fmt.Sprintf("/accounts/%d/transactions?%s", accountID, query.Encode()), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var page models.TransactionPageResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+	return page
+}
+
+func TestAPI_GetTransaction(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	created := transfer(t, router, 1, 2, "12.34")
+
+	req := httptest.NewRequest("GET", "/transactions/"+created.TransactionID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var txn models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&txn))
+	assert.Equal(t, created.TransactionID, txn.TransactionID)
+	assert.Equal(t, models.Decimal("12.34"), txn.Amount)
+	assert.Equal(t, int64(1), txn.SourceAccountID)
+	assert.Equal(t, int64(2), txn.DestinationAccountID)
+
+	for _, id := range []string{"not-a-uuid", "00000000-0000-0000-0000-000000000000"} {
+		req = httptest.NewRequest("GET", "/transactions/"+id, nil)
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+}
+
+func TestAPI_ListAccountTransactions(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "1000.00"}`,
+		`{"account_id": 2, "initial_balance": "1000.00"}`,
+		`{"account_id": 3, "initial_balance": "0"}`,
+	)
+
+	var created []models.TransactionResponse
+	for i := 1; i <= 5; i++ {
+		created = append(created, transfer(t, router, 1, 2, fmt.Sprintf("%d.00", i)))
+	}
+	created = append(created, transfer(t, router, 2, 1, "50.00"))
+
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(`{
+		"sources": [{"account_id": 1, "amount": "30.00"}],
+		"destinations": [{"account_id": 2, "amount": "20.00"}, {"account_id": 3, "amount": "10.00"}]
+	}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// Everything for account 1, newest first
+	page := listTransactions(t, router, 1, url.Values{})
+	require.Len(t, page.Transactions, 7)
+	assert.Empty(t, page.NextCursor)
+	assert.Equal(t, models.DirectionDebit, page.Transactions[0].Direction)
+	assert.Equal(t, models.Decimal("30.00"), page.Transactions[0].AccountAmount)
+	assert.Equal(t, created[5].TransactionID, page.Transactions[1].TransactionID)
+	assert.Equal(t, models.DirectionCredit, page.Transactions[1].Direction)
+
+	// Keyset pagination walks the same list without gaps or repeats
+	var walked []string
+	query := url.Values{"limit": {"3"}}
+	for {
+		page := listTransactions(t, router, 1, query)
+		for _, txn := range page.Transactions {
+			walked = append(walked, txn.TransactionID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+	assert.Len(t, walked, 7)
+
+	// Direction and amount range
+	page = listTransactions(t, router, 1, url.Values{
+		"direction":  {"debit"},
+		"min_amount": {"2.00"},
+		"max_amount": {"4"},
+	})
+	require.Len(t, page.Transactions, 3)
+	for _, txn := range page.Transactions {
+		assert.Equal(t, models.DirectionDebit, txn.Direction)
+	}
+
+	// The multi-leg transaction shows up for a destination-only account
+	page = listTransactions(t, router, 3, url.Values{"direction": {"credit"}})
+	require.Len(t, page.Transactions, 1)
+	assert.Equal(t, models.Decimal("10.00"), page.Transactions[0].AccountAmount)
+
+	// Date range in the future is empty
+	page = listTransactions(t, router, 1, url.Values{"from": {"2999-01-01T00:00:00Z"}})
+	assert.Empty(t, page.Transactions)
+
+	for _, query := range []string{"direction=up", "limit=abc", "cursor=@@", "from=yesterday", "min_amount=1.001"} {
+		req := httptest.NewRequest("GET", "/accounts/1/transactions?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	req = httptest.NewRequest("GET", "/accounts/99/transactions", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
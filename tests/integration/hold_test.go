@@ -0,0 +1,178 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authorize creates a pending transfer and returns it.
+func authorize(t *testing.T, router *chi.Mux, source, destination int64, amount string) models.TransactionResponse {
+	body := fmt.Sprintf(`{"source_account_id": %d, "destination_account_id": %d, "amount": %q, "pending": true}`,
+		source, destination, amount)
+
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var txn models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&txn))
+	return txn
+}
+
+func postAction(router *chi.Mux, transactionID, action, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/transactions/"+transactionID+"/"+action, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func getAccount(t *testing.T, router *chi.Mux, accountID int64) models.AccountResponse {
+	req := httptest.NewRequest("GET", fmt.Sprintf("/accounts/%d", accountID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var account models.AccountResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+	return account
+}
+
+func TestAPI_AuthorizeAndCapture(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	pending := authorize(t, router, 1, 2, "80.00")
+	assert.Equal(t, models.StatusPending, pending.Status)
+	assert.NotNil(t, pending.HoldExpiresAt)
+
+	account := getAccount(t, router, 1)
+	assert.Equal(t, models.Decimal("100.00"), account.Balance)
+	assert.Equal(t, models.Decimal("20.00"), account.AvailableBalance)
+
+	// The hold counts against new transfers
+	req := httptest.NewRequest("POST", "/transactions",
+		bytes.NewBufferString(`{"source_account_id": 1, "destination_account_id": 2, "amount": "20.01"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Pending transfers can not be reversed
+	w = reverse(router, pending.TransactionID, ``)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = postAction(router, pending.TransactionID, "capture", `{"amount": "80.01"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = postAction(router, pending.TransactionID, "capture", `{"amount": "50.00"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var captured models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&captured))
+	assert.Equal(t, models.StatusCompleted, captured.Status)
+	assert.Equal(t, models.Decimal("50.00"), captured.Amount)
+	assert.Equal(t, models.Decimal("80.00"), captured.AuthorizedAmount)
+	assert.Nil(t, captured.HoldExpiresAt)
+
+	account = getAccount(t, router, 1)
+	assert.Equal(t, models.Decimal("50.00"), account.Balance)
+	assert.Equal(t, models.Decimal("50.00"), account.AvailableBalance)
+	assert.Equal(t, models.Decimal("50.00"), getBalance(t, router, 2))
+
+	// Captured once only
+	w = postAction(router, pending.TransactionID, "capture", ``)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = postAction(router, pending.TransactionID, "void", ``)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestAPI_AuthorizeAndVoid(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	pending := authorize(t, router, 1, 2, "100.00")
+
+	w := postAction(router, pending.TransactionID, "void", ``)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var voided models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&voided))
+	assert.Equal(t, models.StatusVoided, voided.Status)
+
+	account := getAccount(t, router, 1)
+	assert.Equal(t, models.Decimal("100.00"), account.Balance)
+	assert.Equal(t, models.Decimal("100.00"), account.AvailableBalance)
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 2))
+
+	w = postAction(router, pending.TransactionID, "capture", ``)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestAPI_HoldExpiry(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	expiring := authorize(t, router, 1, 2, "30.00")
+	captured := authorize(t, router, 1, 2, "20.00")
+
+	w := postAction(router, captured.TransactionID, "capture", ``)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	db := openTestDB(t)
+	defer db.Close()
+
+	transferService := service.NewTransferService(
+		postgres.NewUnitOfWork(db),
+		postgres.NewAccountRepository(db),
+		postgres.NewTransactionRepository(db),
+		postgres.NewJournalRepository(db),
+		postgres.NewQuoteRepository(db),
+		postgres.NewIdempotencyRepository(db),
+		testHoldTTL,
+	)
+
+	expired, err := transferService.ExpireHolds(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, expired)
+
+	expired, err = transferService.ExpireHolds(context.Background(), time.Now().Add(2*testHoldTTL))
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+
+	assert.Equal(t, models.StatusExpired, getTransaction(t, router, expiring.TransactionID).Status)
+	assert.Equal(t, models.StatusCompleted, getTransaction(t, router, captured.TransactionID).Status)
+
+	account := getAccount(t, router, 1)
+	assert.Equal(t, models.Decimal("80.00"), account.Balance)
+	assert.Equal(t, models.Decimal("80.00"), account.AvailableBalance)
+}
@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hotBalances reads an account's settled balance, its pending credits and
+// the sum of its postings straight from the database.
+func hotBalances(t *testing.T, db *sql.DB, accountID int64) (balance, pending, posted int64) {
+	err := db.QueryRow(`
+		SELECT balance,
+			(SELECT COALESCE(SUM(amount), 0) FROM pending_credits WHERE account_id = $1),
+			(SELECT COALESCE(SUM(amount), 0) FROM postings WHERE account_id = $1)
+		FROM accounts
+		WHERE id = $1
+	`, accountID).Scan(&balance, &pending, &posted)
+	require.NoError(t, err)
+	return balance, pending, posted
+}
+
+func TestHotAccount_ConcurrentCreditsAndDebits(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	const (
+		hotAccount  = 1
+		sinkAccount = 99
+		payers      = 10
+		credits     = 10 // of 10.00 per payer
+		debiters    = 5
+		debits      = 40 // of 5.00 per debiter
+	)
+
+	createAccounts(t, router,
+		fmt.Sprintf(`{"account_id": %d, "initial_balance": "0"}`, hotAccount),
+		fmt.Sprintf(`{"account_id": %d, "initial_balance": "0"}`, sinkAccount),
+	)
+	for i := 0; i < payers; i++ {
+		createAccounts(t, router, fmt.Sprintf(`{"account_id": %d, "initial_balance": "100.00"}`, i+2))
+	}
+
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/accounts/%d/hot", hotAccount), bytes.NewBufferString(`{"hot": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	db := openTestDB(t)
+	defer db.Close()
+
+	accountService := service.NewAccountService(
+		postgres.NewUnitOfWork(db),
+		postgres.NewAccountRepository(db),
+		postgres.NewJournalRepository(db),
+		postgres.NewIdempotencyRepository(db),
+	)
+	ctx := context.Background()
+
+	// A credit to a hot account is posted at once but only pending; the
+	// balance reported includes it
+	transfer(t, router, 2, hotAccount, "10.00")
+	balance, pending, posted := hotBalances(t, db, hotAccount)
+	assert.Equal(t, int64(0), balance)
+	assert.Equal(t, int64(1000), pending)
+	assert.Equal(t, int64(1000), posted)
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, hotAccount))
+
+	settled, err := accountService.SettleCredits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, settled)
+	balance, pending, _ = hotBalances(t, db, hotAccount)
+	assert.Equal(t, int64(1000), balance)
+	assert.Zero(t, pending)
+
+	// Payers credit the hot account while others debit it and the settle
+	// job runs, with the balances sampled throughout
+	var (
+		wg           sync.WaitGroup
+		debited      atomic.Int32
+		unexpected   atomic.Int32
+		negative     atomic.Int32
+		done         = make(chan struct{})
+		backgroundWg sync.WaitGroup
+	)
+
+	backgroundWg.Add(2)
+	go func() {
+		defer backgroundWg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if _, err := accountService.SettleCredits(ctx); err != nil {
+				t.Errorf("settling credits: %v", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer backgroundWg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			var balance, ledgerBalance int64
+			err := db.QueryRow(`
+				SELECT balance, balance + (SELECT COALESCE(SUM(amount), 0) FROM pending_credits WHERE account_id = $1)
+				FROM accounts
+				WHERE id = $1
+			`, hotAccount).Scan(&balance, &ledgerBalance)
+			if err != nil {
+				t.Errorf("sampling balance: %v", err)
+				return
+			}
+			if balance < 0 || ledgerBalance < 0 {
+				negative.Add(1)
+			}
+		}
+	}()
+
+	for i := 0; i < payers; i++ {
+		payer := int64(i + 2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < credits; j++ {
+				// The first payer's first credit was made above
+				if payer == 2 && j == 0 {
+					continue
+				}
+				if code := tryTransfer(router, payer, hotAccount, "10.00"); code != http.StatusCreated {
+					unexpected.Add(1)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < debiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < debits; j++ {
+				switch tryTransfer(router, hotAccount, sinkAccount, "5.00") {
+				case http.StatusCreated:
+					debited.Add(1)
+				case http.StatusUnprocessableEntity:
+					// Not enough has been credited yet
+				default:
+					unexpected.Add(1)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+	backgroundWg.Wait()
+
+	assert.Zero(t, unexpected.Load(), "every credit succeeds and debits only fail for want of funds")
+	assert.Zero(t, negative.Load(), "the hot account's balance never goes negative")
+
+	// Whatever is still pending is posted, and settles into the balance
+	balance, pending, posted = hotBalances(t, db, hotAccount)
+	assert.Equal(t, posted, balance+pending)
+
+	_, err = accountService.SettleCredits(ctx)
+	require.NoError(t, err)
+
+	balance, pending, posted = hotBalances(t, db, hotAccount)
+	assert.Zero(t, pending)
+	assert.Equal(t, posted, balance)
+	assert.Equal(t, int64(payers*credits*1000-int(debited.Load())*500), balance)
+
+	sinkBalance, _, sinkPosted := hotBalances(t, db, sinkAccount)
+	assert.Equal(t, int64(debited.Load())*500, sinkBalance)
+	assert.Equal(t, sinkPosted, sinkBalance)
+
+	report := reconcile(t, router)
+	assert.True(t, report.Balanced)
+	assert.Empty(t, report.AccountDrift)
+	assert.Empty(t, report.UnbalancedEntries)
+	assert.Empty(t, report.Transactions)
+}
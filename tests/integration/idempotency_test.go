@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/filipe/financial-ledger-project/internal/repository/postgres"
+	"github.com/filipe/financial-ledger-project/internal/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// postIdempotent posts body to path with an Idempotency-Key, and an
+// X-Client-ID unless clientID is empty.
+func postIdempotent(router *chi.Mux, path, body, clientID, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+	if clientID != "" {
+		req.Header.Set("X-Client-ID", clientID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPI_IdempotencyScopedToClient(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	decode := func(w *httptest.ResponseRecorder) models.TransactionResponse {
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var txn models.TransactionResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&txn))
+		return txn
+	}
+
+	// Two clients using the same key with different payloads don't collide
+	acme := decode(postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "10.00"}`, "acme", "order-1"))
+	globex := decode(postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "20.00"}`, "globex", "order-1"))
+	assert.NotEqual(t, acme.TransactionID, globex.TransactionID)
+
+	// Nor does a request without a client ID
+	anonymous := decode(postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "30.00"}`, "", "order-1"))
+	assert.NotEqual(t, acme.TransactionID, anonymous.TransactionID)
+
+	// Each client's retry replays its own transaction
+	retry := decode(postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "20.00"}`, "globex", "order-1"))
+	assert.Equal(t, globex.TransactionID, retry.TransactionID)
+
+	w := postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "20.00"}`, "acme", "order-1")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Requests without a client ID share the anonymous scope, like keys
+	// stored before scoping existed
+	retry = decode(postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "30.00"}`, "", "order-1"))
+	assert.Equal(t, anonymous.TransactionID, retry.TransactionID)
+
+	assert.Equal(t, models.Decimal("40.00"), getBalance(t, router, 1))
+}
+
+func TestAPI_IdempotencyRequiresClientID(t *testing.T) {
+	router, cleanup := setupTestRouterWith(t, routerOptions{requireClientID: true})
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	w := postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "30.00"}`, "", "order-1")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "X-Client-ID")
+
+	w = postIdempotent(router, "/transactions",
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "30.00"}`, "acme", "order-1")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	assert.Equal(t, models.Decimal("70.00"), getBalance(t, router, 1))
+}
+
+func TestAPI_CreateAccountIdempotency(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	body := `{"account_id": 1, "initial_balance": "100.00"}`
+
+	w := postIdempotent(router, "/accounts", body, "acme", "open-1")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// A retry succeeds without opening the account twice
+	w = postIdempotent(router, "/accounts", body, "acme", "open-1")
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, models.Decimal("100.00"), getBalance(t, router, 1))
+
+	// Without the key the account already exists
+	req := httptest.NewRequest("POST", "/accounts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// And a different account under the same key is refused
+	w = postIdempotent(router, "/accounts", `{"account_id": 2}`, "acme", "open-1")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// A request that failed because the account exists keeps failing
+	w = postIdempotent(router, "/accounts", body, "globex", "open-1")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = postIdempotent(router, "/accounts", body, "globex", "open-1")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestIdempotency_PurgeExpired(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	body := `{"source_account_id": 1, "destination_account_id": 2, "amount": "10.00"}`
+	w := postIdempotent(router, "/transactions", body, "acme", "purge-1")
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	db := openTestDB(t)
+	defer db.Close()
+
+	idempotencyService := service.NewIdempotencyService(postgres.NewUnitOfWork(db), postgres.NewIdempotencyRepository(db), 24*time.Hour)
+	ctx := context.Background()
+
+	// Within the retention window the key is kept
+	purged, err := idempotencyService.PurgeExpired(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	w = postIdempotent(router, "/transactions", body, "acme", "purge-1")
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, models.Decimal("90.00"), getBalance(t, router, 1))
+
+	// Once it has expired, the same key is a new request
+	purged, err = idempotencyService.PurgeExpired(ctx, time.Now().Add(25*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	w = postIdempotent(router, "/transactions", body, "acme", "purge-1")
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, models.Decimal("80.00"), getBalance(t, router, 1))
+}
@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// importAccounts uploads a CSV file of accounts and returns the report.
+func importAccounts(t *testing.T, router *chi.Mux, file string) models.AccountImportReport {
+	req := httptest.NewRequest("POST", "/accounts/import", strings.NewReader(file))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var report models.AccountImportReport
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	return report
+}
+
+func TestAPI_ImportAccounts(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router, `{"account_id": 7, "initial_balance": "5.00"}`)
+
+	// More rows than fit in one batch, so the file is copied in two. Odd
+	// accounts open with a balance and even ones without.
+	const rows = 1500
+	var file strings.Builder
+	file.WriteString("account_id,initial_balance,overdraft_limit\n")
+	for id := 1; id <= rows; id++ {
+		balance := ""
+		if id%2 == 1 {
+			balance = "10.00"
+		}
+		overdraft := ""
+		if id == 2 {
+			overdraft = "25.00"
+		}
+		fmt.Fprintf(&file, "%d,%s,%s\n", id, balance, overdraft)
+	}
+
+	report := importAccounts(t, router, file.String())
+	assert.Equal(t, rows, report.Rows)
+	assert.Equal(t, rows-1, report.Created)
+	assert.Zero(t, report.Existing)
+	require.Len(t, report.Rejected, 1)
+	assert.Equal(t, 8, report.Rejected[0].Line)
+	assert.Equal(t, "account 7 conflicts with existing account: opening balance is 5.00, not 10.00", report.Rejected[0].Error)
+
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, 1))
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, rows-1))
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, rows))
+	assert.Equal(t, models.Decimal("5.00"), getBalance(t, router, 7), "a conflicting account is left alone")
+	limit := getAccount(t, router, 2).OverdraftLimit
+	require.NotNil(t, limit)
+	assert.Equal(t, models.Decimal("25.00"), *limit)
+
+	db := openTestDB(t)
+	defer db.Close()
+
+	openingBalances := func() (entries, accounts int) {
+		err := db.QueryRow(`
+			SELECT COUNT(*), COUNT(DISTINCT p.account_id)
+			FROM journal_entries e
+			JOIN postings p ON p.entry_id = e.id
+			WHERE e.kind = $1 AND p.account_id IS NOT NULL
+		`, models.EntryKindOpeningBalance).Scan(&entries, &accounts)
+		require.NoError(t, err)
+		return entries, accounts
+	}
+
+	// One opening deposit for each odd account, account 7's included
+	entries, accounts := openingBalances()
+	assert.Equal(t, rows/2, entries)
+	assert.Equal(t, rows/2, accounts)
+
+	// Sending the same file again finds every account already there and
+	// posts nothing
+	report = importAccounts(t, router, file.String())
+	assert.Equal(t, rows, report.Rows)
+	assert.Zero(t, report.Created)
+	assert.Equal(t, rows-1, report.Existing)
+	assert.Len(t, report.Rejected, 1)
+
+	entries, accounts = openingBalances()
+	assert.Equal(t, rows/2, entries)
+	assert.Equal(t, rows/2, accounts)
+	assert.Equal(t, models.Decimal("10.00"), getBalance(t, router, 1))
+
+	reconciled := reconcile(t, router)
+	assert.True(t, reconciled.Balanced)
+	assert.Equal(t, rows, reconciled.AccountsChecked)
+}
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setOverdraftLimit(router *chi.Mux, accountID int64, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/accounts/%d/overdraft-limit", accountID), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPI_OverdraftLimit(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00", "overdraft_limit": "50.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	account := getAccount(t, router, 1)
+	require.NotNil(t, account.OverdraftLimit)
+	assert.Equal(t, models.Decimal("50.00"), *account.OverdraftLimit)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, tryTransfer(router, 1, 2, "150.01"))
+	assert.Equal(t, http.StatusCreated, tryTransfer(router, 1, 2, "150.00"))
+	assert.Equal(t, models.Decimal("-50.00"), getBalance(t, router, 1))
+
+	// Accounts without an overdraft still stop at zero
+	assert.Equal(t, http.StatusUnprocessableEntity, tryTransfer(router, 2, 1, "150.01"))
+
+	// A limit the account is already past is refused
+	w := setOverdraftLimit(router, 1, `{"overdraft_limit": "49.99"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = setOverdraftLimit(router, 1, `{"overdraft_limit": "80.00"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusCreated, tryTransfer(router, 1, 2, "30.00"))
+
+	w = setOverdraftLimit(router, 1, `{"overdraft_limit": "-1.00"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAPI_UnlimitedOverdraft(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "unlimited_overdraft": true}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	assert.Nil(t, getAccount(t, router, 1).OverdraftLimit)
+	assert.Equal(t, http.StatusCreated, tryTransfer(router, 1, 2, "1000000.00"))
+	assert.Equal(t, models.Decimal("-1000000.00"), getBalance(t, router, 1))
+
+	// Re-imposing a limit is refused while the account is past it
+	w := setOverdraftLimit(router, 1, `{"overdraft_limit": "0"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Closing still needs a zero balance
+	w = changeStatus(router, 1, "close", "Clearing account retired")
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
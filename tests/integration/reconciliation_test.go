@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reconcile(t *testing.T, router *chi.Mux) models.ReconciliationResponse {
+	req := httptest.NewRequest("GET", "/admin/reconciliation", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var report models.ReconciliationResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&report))
+	return report
+}
+
+func TestReconciliation(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+		`{"account_id": 3, "initial_balance": "0"}`,
+	)
+
+	first := transfer(t, router, 1, 2, "30.00")
+	transfer(t, router, 2, 3, "10.00")
+	require.Equal(t, http.StatusCreated, reverse(router, first.TransactionID, `{"amount": "5.00"}`).Code)
+	authorize(t, router, 1, 3, "20.00")
+	voided := authorize(t, router, 1, 2, "15.00")
+	require.Equal(t, http.StatusOK, postAction(router, voided.TransactionID, "void", ``).Code)
+
+	report := reconcile(t, router)
+	assert.True(t, report.Balanced)
+	assert.Equal(t, 3, report.AccountsChecked)
+	assert.Empty(t, report.AccountDrift)
+	assert.Empty(t, report.UnbalancedEntries)
+	assert.Empty(t, report.Transactions)
+
+	// Tamper with the cached balances behind the ledger's back
+	db := openTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`UPDATE accounts SET balance = balance + 100 WHERE id = 2`)
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE accounts SET held_balance = 0 WHERE id = 1`)
+	require.NoError(t, err)
+
+	report = reconcile(t, router)
+	assert.False(t, report.Balanced)
+	assert.Equal(t, []models.AccountDriftResponse{
+		{
+			AccountID:           1,
+			Currency:            "USD",
+			Balance:             "75.00",
+			ExpectedBalance:     "75.00",
+			HeldBalance:         "0.00",
+			ExpectedHeldBalance: "20.00",
+		},
+		{
+			AccountID:           2,
+			Currency:            "USD",
+			Balance:             "16.00",
+			ExpectedBalance:     "15.00",
+			HeldBalance:         "0.00",
+			ExpectedHeldBalance: "0.00",
+		},
+	}, report.AccountDrift)
+	assert.Empty(t, report.UnbalancedEntries)
+	assert.Empty(t, report.Transactions)
+}
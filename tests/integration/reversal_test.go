@@ -0,0 +1,204 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/filipe/financial-ledger-project/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func reverse(router *chi.Mux, transactionID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/transactions/"+transactionID+"/reversals", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func getBalance(t *testing.T, router *chi.Mux, accountID int64) models.Decimal {
+	req := httptest.NewRequest("GET", fmt.Sprintf("/accounts/%d", accountID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var account models.AccountResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&account))
+	return account.Balance
+}
+
+func getTransaction(t *testing.T, router *chi.Mux, transactionID string) models.TransactionResponse {
+	req := httptest.NewRequest("GET", "/transactions/"+transactionID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var txn models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&txn))
+	return txn
+}
+
+func TestAPI_Reversal(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+	)
+
+	original := transfer(t, router, 1, 2, "60.00")
+
+	// Partial refund
+	w := reverse(router, original.TransactionID, `{"amount": "20.00"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var partial models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&partial))
+	assert.Equal(t, original.TransactionID, partial.ReversalOf)
+	assert.Equal(t, int64(2), partial.SourceAccountID)
+	assert.Equal(t, int64(1), partial.DestinationAccountID)
+	assert.Equal(t, models.Decimal("20.00"), partial.Amount)
+
+	txn := getTransaction(t, router, original.TransactionID)
+	assert.Equal(t, models.StatusPartiallyReversed, txn.Status)
+	assert.Equal(t, models.Decimal("20.00"), txn.ReversedAmount)
+
+	// Can not reverse more than is left
+	w = reverse(router, original.TransactionID, `{"amount": "40.01"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// A reversal is not itself reversible
+	w = reverse(router, partial.TransactionID, ``)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// No body reverses the rest
+	w = reverse(router, original.TransactionID, ``)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var rest models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&rest))
+	assert.Equal(t, models.Decimal("40.00"), rest.Amount)
+
+	txn = getTransaction(t, router, original.TransactionID)
+	assert.Equal(t, models.StatusReversed, txn.Status)
+	assert.Equal(t, models.Decimal("100.00"), getBalance(t, router, 1))
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 2))
+
+	w = reverse(router, original.TransactionID, ``)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = reverse(router, "00000000-0000-0000-0000-000000000000", ``)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAPI_ReversalInsufficientFunds(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+		`{"account_id": 3, "initial_balance": "0"}`,
+	)
+
+	original := transfer(t, router, 1, 2, "60.00")
+	transfer(t, router, 2, 3, "50.00")
+
+	w := reverse(router, original.TransactionID, ``)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = reverse(router, original.TransactionID, `{"amount": "10.00"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 2))
+}
+
+func TestAPI_CrossCurrencyReversal(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "currency": "USD", "initial_balance": "100.00"}`,
+		`{"account_id": 2, "currency": "JPY", "initial_balance": "0"}`,
+	)
+
+	req := httptest.NewRequest("POST", "/fx/quotes",
+		bytes.NewBufferString(`{"source_currency": "USD", "destination_currency": "JPY"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var quote models.FXQuoteResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&quote))
+
+	req = httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(fmt.Sprintf(
+		`{"source_account_id": 1, "destination_account_id": 2, "amount": "0.03", "quote_id": %q}`, quote.QuoteID)))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var original models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&original))
+	assert.Equal(t, models.Decimal("5"), original.DestinationAmount)
+
+	// Each cent is 1.505 JPY; the parts take back exactly the 5 JPY credited
+	var taken []models.Decimal
+	for i := 0; i < 3; i++ {
+		w = reverse(router, original.TransactionID, `{"amount": "0.01"}`)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var reversal models.TransactionResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&reversal))
+		assert.Equal(t, "JPY", reversal.Currency)
+		assert.Equal(t, "USD", reversal.DestinationCurrency)
+		assert.Equal(t, models.Decimal("0.01"), reversal.DestinationAmount)
+		taken = append(taken, reversal.Amount)
+	}
+
+	assert.Equal(t, []models.Decimal{"2", "1", "2"}, taken)
+	assert.Equal(t, models.Decimal("0"), getBalance(t, router, 2))
+	assert.Equal(t, models.Decimal("100.00"), getBalance(t, router, 1))
+}
+
+func TestAPI_MultiLegReversal(t *testing.T) {
+	router, cleanup := setupTestRouter(t)
+	defer cleanup()
+
+	createAccounts(t, router,
+		`{"account_id": 1, "initial_balance": "100.00"}`,
+		`{"account_id": 2, "initial_balance": "0"}`,
+		`{"account_id": 3, "initial_balance": "0"}`,
+	)
+
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBufferString(`{
+		"sources": [{"account_id": 1, "amount": "100.00"}],
+		"destinations": [{"account_id": 2, "amount": "90.00"}, {"account_id": 3, "amount": "10.00"}]
+	}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var original models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&original))
+
+	w = reverse(router, original.TransactionID, `{"amount": "10.00"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = reverse(router, original.TransactionID, ``)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var reversal models.TransactionResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&reversal))
+	assert.Len(t, reversal.Sources, 2)
+	assert.Len(t, reversal.Destinations, 1)
+
+	assert.Equal(t, models.Decimal("100.00"), getBalance(t, router, 1))
+	assert.Equal(t, models.Decimal("0.00"), getBalance(t, router, 2))
+	assert.Equal(t, models.StatusReversed, getTransaction(t, router, original.TransactionID).Status)
+}
@@ -17,7 +17,7 @@ func TestCreateAccountRequest_Validate(t *testing.T) {
 			name: "Valid request",
 			req: models.CreateAccountRequest{
 				AccountID:      1,
-				InitialBalance: 100.0,
+				InitialBalance: "100.00",
 			},
 			expectError: nil,
 		},
@@ -25,7 +25,7 @@ func TestCreateAccountRequest_Validate(t *testing.T) {
 			name: "Valid request with zero balance",
 			req: models.CreateAccountRequest{
 				AccountID:      1,
-				InitialBalance: 0.0,
+				InitialBalance: "0.00",
 			},
 			expectError: nil,
 		},
@@ -33,7 +33,7 @@ func TestCreateAccountRequest_Validate(t *testing.T) {
 			name: "Invalid account ID - zero",
 			req: models.CreateAccountRequest{
 				AccountID:      0,
-				InitialBalance: 100.0,
+				InitialBalance: "100.00",
 			},
 			expectError: models.ErrInvalidAccountID,
 		},
@@ -41,7 +41,7 @@ func TestCreateAccountRequest_Validate(t *testing.T) {
 			name: "Invalid account ID - negative",
 			req: models.CreateAccountRequest{
 				AccountID:      -1,
-				InitialBalance: 100.0,
+				InitialBalance: "100.00",
 			},
 			expectError: models.ErrInvalidAccountID,
 		},
@@ -49,10 +49,68 @@ func TestCreateAccountRequest_Validate(t *testing.T) {
 			name: "Negative initial balance",
 			req: models.CreateAccountRequest{
 				AccountID:      1,
-				InitialBalance: -100.0,
+				InitialBalance: "-100.00",
 			},
 			expectError: models.ErrNegativeBalance,
 		},
+		{
+			name: "Valid request with omitted balance",
+			req: models.CreateAccountRequest{
+				AccountID: 1,
+			},
+			expectError: nil,
+		},
+		{
+			name: "Initial balance with too many decimals",
+			req: models.CreateAccountRequest{
+				AccountID:      1,
+				InitialBalance: "100.126",
+			},
+			expectError: models.ErrTooManyDecimals,
+		},
+		{
+			name: "Valid request with three decimal currency",
+			req: models.CreateAccountRequest{
+				AccountID:      1,
+				Currency:       "KWD",
+				InitialBalance: "100.125",
+			},
+			expectError: nil,
+		},
+		{
+			name: "Fractional balance in zero decimal currency",
+			req: models.CreateAccountRequest{
+				AccountID:      1,
+				Currency:       "JPY",
+				InitialBalance: "100.5",
+			},
+			expectError: models.ErrTooManyDecimals,
+		},
+		{
+			name: "Unsupported currency",
+			req: models.CreateAccountRequest{
+				AccountID:      1,
+				Currency:       "XYZ",
+				InitialBalance: "100.00",
+			},
+			expectError: models.ErrUnsupportedCurrency,
+		},
+		{
+			name: "Valid request with overdraft limit",
+			req: models.CreateAccountRequest{
+				AccountID:             1,
+				OverdraftLimitRequest: models.OverdraftLimitRequest{OverdraftLimit: "500.00"},
+			},
+			expectError: nil,
+		},
+		{
+			name: "Negative overdraft limit",
+			req: models.CreateAccountRequest{
+				AccountID:             1,
+				OverdraftLimitRequest: models.OverdraftLimitRequest{OverdraftLimit: "-500.00"},
+			},
+			expectError: models.ErrInvalidOverdraftLimit,
+		},
 	}
 
 	for _, tt := range tests {
@@ -78,7 +136,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			req: models.CreateTransactionRequest{
 				SourceAccountID:      1,
 				DestinationAccountID: 2,
-				Amount:               100.0,
+				Amount:               "100.00",
 			},
 			expectError: nil,
 		},
@@ -87,7 +145,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			req: models.CreateTransactionRequest{
 				SourceAccountID:      0,
 				DestinationAccountID: 2,
-				Amount:               100.0,
+				Amount:               "100.00",
 			},
 			expectError: models.ErrInvalidAccountID,
 		},
@@ -96,7 +154,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			req: models.CreateTransactionRequest{
 				SourceAccountID:      1,
 				DestinationAccountID: 0,
-				Amount:               100.0,
+				Amount:               "100.00",
 			},
 			expectError: models.ErrInvalidAccountID,
 		},
@@ -105,7 +163,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			req: models.CreateTransactionRequest{
 				SourceAccountID:      1,
 				DestinationAccountID: 1,
-				Amount:               100.0,
+				Amount:               "100.00",
 			},
 			expectError: models.ErrSameAccount,
 		},
@@ -114,7 +172,7 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			req: models.CreateTransactionRequest{
 				SourceAccountID:      1,
 				DestinationAccountID: 2,
-				Amount:               0.0,
+				Amount:               "0.00",
 			},
 			expectError: models.ErrInvalidAmount,
 		},
@@ -123,10 +181,135 @@ func TestCreateTransactionRequest_Validate(t *testing.T) {
 			req: models.CreateTransactionRequest{
 				SourceAccountID:      1,
 				DestinationAccountID: 2,
-				Amount:               -100.0,
+				Amount:               "-100.00",
+			},
+			expectError: models.ErrInvalidAmount,
+		},
+		{
+			name: "Missing amount",
+			req: models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+			},
+			expectError: models.ErrInvalidAmount,
+		},
+		{
+			name: "Amount with too many decimals",
+			req: models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+				Amount:               "100.12345",
+			},
+			expectError: models.ErrTooManyDecimals,
+		},
+		{
+			name: "Valid multi-leg request",
+			req: models.CreateTransactionRequest{
+				Sources: []models.TransactionLegRequest{
+					{AccountID: 1, Amount: "100.00"},
+					{AccountID: 2, Amount: "50"},
+				},
+				Destinations: []models.TransactionLegRequest{
+					{AccountID: 3, Amount: "140.00"},
+					{AccountID: 4, Amount: "9.5"},
+					{AccountID: 5, Amount: "0.50"},
+				},
+			},
+			expectError: nil,
+		},
+		{
+			name: "Multi-leg without destinations",
+			req: models.CreateTransactionRequest{
+				Sources: []models.TransactionLegRequest{
+					{AccountID: 1, Amount: "100.00"},
+				},
+			},
+			expectError: models.ErrInvalidLegs,
+		},
+		{
+			name: "Multi-leg mixed with single-leg fields",
+			req: models.CreateTransactionRequest{
+				SourceAccountID: 1,
+				Sources: []models.TransactionLegRequest{
+					{AccountID: 1, Amount: "100.00"},
+				},
+				Destinations: []models.TransactionLegRequest{
+					{AccountID: 2, Amount: "100.00"},
+				},
+			},
+			expectError: models.ErrInvalidLegs,
+		},
+		{
+			name: "Multi-leg legs do not balance",
+			req: models.CreateTransactionRequest{
+				Sources: []models.TransactionLegRequest{
+					{AccountID: 1, Amount: "100.00"},
+				},
+				Destinations: []models.TransactionLegRequest{
+					{AccountID: 2, Amount: "60.00"},
+					{AccountID: 3, Amount: "39.99"},
+				},
+			},
+			expectError: models.ErrUnbalancedLegs,
+		},
+		{
+			name: "Multi-leg account on both sides",
+			req: models.CreateTransactionRequest{
+				Sources: []models.TransactionLegRequest{
+					{AccountID: 1, Amount: "100.00"},
+				},
+				Destinations: []models.TransactionLegRequest{
+					{AccountID: 2, Amount: "50.00"},
+					{AccountID: 1, Amount: "50.00"},
+				},
+			},
+			expectError: models.ErrSameAccount,
+		},
+		{
+			name: "Multi-leg zero leg",
+			req: models.CreateTransactionRequest{
+				Sources: []models.TransactionLegRequest{
+					{AccountID: 1, Amount: "100.00"},
+				},
+				Destinations: []models.TransactionLegRequest{
+					{AccountID: 2, Amount: "100.00"},
+					{AccountID: 3, Amount: "0"},
+				},
 			},
 			expectError: models.ErrInvalidAmount,
 		},
+		{
+			name: "Valid pending request",
+			req: models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+				Amount:               "100.00",
+				Pending:              true,
+			},
+			expectError: nil,
+		},
+		{
+			name: "Pending multi-leg",
+			req: models.CreateTransactionRequest{
+				Sources: []models.TransactionLegRequest{
+					{AccountID: 1, Amount: "100.00"},
+				},
+				Destinations: []models.TransactionLegRequest{
+					{AccountID: 2, Amount: "100.00"},
+				},
+				Pending: true,
+			},
+			expectError: models.ErrInvalidLegs,
+		},
+		{
+			name: "Malformed amount",
+			req: models.CreateTransactionRequest{
+				SourceAccountID:      1,
+				DestinationAccountID: 2,
+				Amount:               "1e2",
+			},
+			expectError: models.ErrInvalidAmountFormat,
+		},
 	}
 
 	for _, tt := range tests {